@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/config"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	KEY   = 0
+	VALUE = 1
+)
+
+// sourceFlag is the source of the settings given on the command line
+const sourceFlag = "flag"
+
+// Apply sets the defaults of the persistent flags of rootCmd from the
+// config files and the environment, it runs before the command line is
+// parsed so the flags still win. The sources that work apply even when
+// another is broken, the error is the one of the first broken source
+func Apply(rootCmd *cobra.Command) error {
+	settings, errs := config.Inspect(config.UserFile(), config.ProjectFile)
+
+	for name, setting := range settings {
+		flag := rootCmd.PersistentFlags().Lookup(name)
+		if flag == nil {
+			continue
+		}
+
+		if err := flag.Value.Set(setting.Value); err != nil {
+			errs = append(errs, fmt.Errorf("%s from %s: %w", name, setting.Source, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+func Config() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "manage the defaults of the flags",
+		Long: fmt.Sprintf(`defaults of the flags %s are read from %s,
+then from %s in the working directory, then from the %s* environment variables,
+flags on the command line win over all of them`, strings.Join(config.Names(), ", "), config.UserFile(), config.ProjectFile, config.EnvPrefix),
+	}
+
+	configCmd.AddCommand(get())
+	configCmd.AddCommand(set())
+	configCmd.AddCommand(list())
+
+	return configCmd
+}
+
+func get() *cobra.Command {
+	getCmd := &cobra.Command{
+		Use:   "get key",
+		Short: "print the value of a key",
+		Long:  `print the value of a key as calc sees it`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+
+			_, err := config.Lookup(args[KEY])
+
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			settings, errs := config.Inspect(config.UserFile(), config.ProjectFile)
+
+			setting := effective(cmd, settings, args[KEY])
+
+			record := render.Record{
+				Operands: args,
+				Result:   setting.Value,
+				Details:  map[string]string{"source": setting.Source},
+			}
+
+			if err := output.Render(cmd, record); err != nil {
+				return err
+			}
+
+			return report(cmd, errs)
+		},
+	}
+
+	return getCmd
+}
+
+func set() *cobra.Command {
+	var project bool
+
+	setCmd := &cobra.Command{
+		Use:   "set key value",
+		Short: "write the value of a key",
+		Long:  fmt.Sprintf(`write the value of a key to the user config file, or to %s with --project`, config.ProjectFile),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			key, err := config.Lookup(args[KEY])
+			if err != nil {
+				return err
+			}
+
+			return key.Validate(args[VALUE])
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := config.UserFile()
+			if project {
+				path = config.ProjectFile
+			}
+
+			if err := config.Set(path, args[KEY], args[VALUE]); err != nil {
+				return err
+			}
+
+			record := render.Record{
+				Operands: args,
+				Result:   args[VALUE],
+				Details:  map[string]string{"file": path},
+				Text:     fmt.Sprintf("%s=%s written to %s", args[KEY], args[VALUE], path),
+			}
+
+			return output.Render(cmd, record)
+		},
+	}
+
+	setCmd.Flags().BoolVar(&project, "project", false, fmt.Sprintf("write to %s in the working directory", config.ProjectFile))
+
+	return setCmd
+}
+
+func list() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "print every key",
+		Long:  `print every key with its value and where the value comes from`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			settings, errs := config.Inspect(config.UserFile(), config.ProjectFile)
+
+			if !output.Structured(cmd) {
+				lines := []string{}
+
+				for _, name := range config.Names() {
+					setting := effective(cmd, settings, name)
+
+					lines = append(lines, fmt.Sprintf("%s=%s (%s)", name, setting.Value, setting.Source))
+				}
+
+				if err := output.Print(cmd, args, strings.Join(lines, "\n")); err != nil {
+					return err
+				}
+
+				return report(cmd, errs)
+			}
+
+			for _, name := range config.Names() {
+				setting := effective(cmd, settings, name)
+
+				record := render.Record{
+					Result:  setting.Value,
+					Details: map[string]string{"key": name, "source": setting.Source},
+				}
+
+				if err := output.Render(cmd, record); err != nil {
+					return err
+				}
+			}
+
+			return report(cmd, errs)
+		},
+	}
+
+	return listCmd
+}
+
+// report writes the errors of the broken config sources after the
+// settings calc still applies, as records with the structured outputs,
+// and fails with the first one
+func report(cmd *cobra.Command, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for _, err := range errs {
+		if output.Structured(cmd) {
+			if rerr := output.Render(cmd, render.Record{Error: err.Error()}); rerr != nil {
+				return rerr
+			}
+
+			continue
+		}
+
+		if flag := cmd.Flag("quiet"); flag == nil || flag.Value.String() != "true" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "calc: %v\n", err)
+		}
+	}
+
+	return output.Reported(errs[0])
+}
+
+// effective is the value of the flag named after the key, the source is
+// the command line when the flag was given
+func effective(cmd *cobra.Command, settings map[string]config.Setting, name string) config.Setting {
+	setting := config.Setting{Key: name, Source: config.SourceDefault}
+
+	if loaded, ok := settings[name]; ok {
+		setting = loaded
+	}
+
+	if flag := cmd.Flag(name); flag != nil {
+		setting.Value = flag.Value.String()
+
+		if flag.Changed {
+			setting.Source = sourceFlag
+		}
+	}
+
+	return setting
+}
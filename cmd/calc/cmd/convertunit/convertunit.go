@@ -0,0 +1,50 @@
+package convertunit
+
+import (
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/units"
+	"github.com/spf13/cobra"
+)
+
+const (
+	QUANTITY = 0
+	UNIT     = 1
+)
+
+func ConvertUnit() *cobra.Command {
+	convertUnitCmd := &cobra.Command{
+		Use:   "convert-unit quantity unit",
+		Short: "unit conversion",
+		Long: `convert a quantity such as 3.5GiB to another unit of the same dimension,
+unit arithmetic is float64 and unchecked: --precision checked does not apply`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := units.Parse(args[QUANTITY]); err != nil {
+				return err
+			}
+
+			if _, err := units.ParseUnit(args[UNIT]); err != nil {
+				return err
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			quantity, _ := units.Parse(args[QUANTITY])
+
+			converted, err := units.Convert(quantity, args[UNIT])
+
+			if err != nil {
+				return err
+			}
+
+			return output.Render(cmd, render.Record{Operands: args, Result: converted.String(), Precision: render.Float64})
+		},
+	}
+
+	return convertUnitCmd
+}
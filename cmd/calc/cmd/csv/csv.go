@@ -0,0 +1,124 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/table"
+	"github.com/spf13/cobra"
+)
+
+const FILE = 0
+
+func CSV() *cobra.Command {
+	var exprs []string
+	var onError string
+	var footers []string
+	var delimiter string
+
+	opts := table.Options{}
+
+	csvCmd := &cobra.Command{
+		Use:   "csv [file]",
+		Short: "compute columns of a CSV file",
+		Long: `add the columns of --expr 'total = price * qty' to a CSV file with a header, or to stdin,
+the formulas reference the columns by name and use the integer arithmetic of the operations,
+formulas using decimal cells such as 9.99 are exact and rounded to --places with --rounding.
+Rows with cells that are not numbers fail, --on-error skip leaves them out and blank leaves the cells empty,
+--footer sum and avg aggregate the computed columns and the columns they use. The output is CSV, --output accepts text and csv only`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+				return err
+			}
+
+			// the output is the input table with its new columns
+			if format, err := render.ParseFormat(cmd.Flag("output").Value.String()); err == nil && format != render.Text && format != render.CSV {
+				return fmt.Errorf("the output of csv is CSV, --output %s is not supported", format)
+			}
+
+			if len(exprs) == 0 {
+				return fmt.Errorf("at least one --expr is required")
+			}
+
+			opts.Formulas = opts.Formulas[:0]
+
+			for _, expr := range exprs {
+				f, err := table.ParseFormula(expr)
+				if err != nil {
+					return err
+				}
+
+				opts.Formulas = append(opts.Formulas, f)
+			}
+
+			var err error
+
+			if opts.OnError, err = table.ParseOnError(onError); err != nil {
+				return err
+			}
+
+			opts.Footers = opts.Footers[:0]
+
+			for _, footer := range footers {
+				aggregate, err := table.ParseAggregate(footer)
+				if err != nil {
+					return err
+				}
+
+				opts.Footers = append(opts.Footers, aggregate)
+			}
+
+			comma, size := utf8.DecodeRuneInString(delimiter)
+			if size == 0 || size != len(delimiter) {
+				return fmt.Errorf("invalid delimiter %q, use a single character", delimiter)
+			}
+
+			opts.Comma = comma
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rounding, err := output.Rounding(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts.Rounding = rounding
+
+			var in io.Reader = cmd.InOrStdin()
+
+			if len(args) > 0 && args[FILE] != "-" {
+				f, err := os.Open(args[FILE])
+				if err != nil {
+					return err
+				}
+
+				defer f.Close()
+
+				in = f
+			}
+
+			stats, err := table.Compute(cmd.Context(), in, cmd.OutOrStdout(), opts)
+
+			if flag := cmd.Flag("quiet"); flag == nil || flag.Value.String() != "true" {
+				for _, rowErr := range stats.Errors {
+					fmt.Fprintf(cmd.ErrOrStderr(), "calc: %v\n", rowErr)
+				}
+			}
+
+			return err
+		},
+	}
+
+	csvCmd.Flags().StringArrayVar(&exprs, "expr", nil, "column = expression computed on every row, repeat it for several columns")
+	csvCmd.Flags().StringVar(&onError, "on-error", string(table.Fail), "rows whose formulas fail: fail, skip or blank")
+	csvCmd.Flags().StringSliceVar(&footers, "footer", nil, "footer rows: sum, avg or both")
+	csvCmd.Flags().IntVar(&opts.Places, "places", 2, "decimal places of the averages and of the results of decimal cells, rounded with --rounding")
+	csvCmd.Flags().StringVar(&delimiter, "delimiter", ",", "field delimiter of the input and the output")
+
+	return csvCmd
+}
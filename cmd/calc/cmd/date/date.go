@@ -0,0 +1,206 @@
+package date
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	calcdate "github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/date"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FIRST  = 0
+	SECOND = 1
+)
+
+func Date() *cobra.Command {
+	dateCmd := &cobra.Command{
+		Use:   "date",
+		Short: "date and duration arithmetic",
+		Long:  `date and duration arithmetic on RFC3339 timestamps`,
+		Annotations: map[string]string{
+			output.PrecisionAnnotation: render.Float64,
+		},
+	}
+
+	dateCmd.AddCommand(add())
+	dateCmd.AddCommand(sub())
+	dateCmd.AddCommand(diff())
+	dateCmd.AddCommand(addBusinessDays())
+	dateCmd.AddCommand(businessDays())
+
+	return dateCmd
+}
+
+func add() *cobra.Command {
+	addCmd := &cobra.Command{
+		Use:   "add date duration",
+		Short: "add a duration to a date",
+		Long:  `add a duration such as 36h, 1h30m or 14d to an RFC3339 date`,
+		Args:  dateAndDuration,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			date, _ := calcdate.Parse(args[FIRST])
+			duration, _ := calcdate.ParseDuration(args[SECOND])
+
+			return output.Print(cmd, args, date.Add(duration).Format(time.RFC3339))
+		},
+	}
+
+	return addCmd
+}
+
+func sub() *cobra.Command {
+	subCmd := &cobra.Command{
+		Use:   "sub date duration",
+		Short: "subtract a duration from a date",
+		Long:  `subtract a duration such as 36h, 1h30m or 14d from an RFC3339 date`,
+		Args:  dateAndDuration,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			date, _ := calcdate.Parse(args[FIRST])
+			duration, _ := calcdate.ParseDuration(args[SECOND])
+
+			return output.Print(cmd, args, date.Add(-duration).Format(time.RFC3339))
+		},
+	}
+
+	return subCmd
+}
+
+func diff() *cobra.Command {
+	var unit string
+
+	diffCmd := &cobra.Command{
+		Use:   "diff from to",
+		Short: "difference between two dates",
+		Long:  `difference between two RFC3339 dates expressed in a time unit`,
+		Args:  twoDates,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, _ := calcdate.Parse(args[FIRST])
+			to, _ := calcdate.Parse(args[SECOND])
+
+			diff, err := calcdate.Diff(from, to, unit)
+
+			if err != nil {
+				return err
+			}
+
+			return output.Print(cmd, args, diff.String())
+		},
+	}
+
+	diffCmd.Flags().StringVar(&unit, "unit", "h", "time unit of the result (ms, s, min, h, d)")
+
+	return diffCmd
+}
+
+func addBusinessDays() *cobra.Command {
+	var holidaysFile string
+
+	addBusinessDaysCmd := &cobra.Command{
+		Use:   "add-business-days date days",
+		Short: "add business days to a date",
+		Long:  `add working days to an RFC3339 date skipping weekends and holidays`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := calcdate.Parse(args[FIRST]); err != nil {
+				return err
+			}
+
+			if _, err := strconv.Atoi(args[SECOND]); err != nil {
+				return err
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			date, _ := calcdate.Parse(args[FIRST])
+			days, _ := strconv.Atoi(args[SECOND])
+
+			holidays, err := calcdate.LoadHolidays(holidaysFile)
+
+			if err != nil {
+				return err
+			}
+
+			result, err := calcdate.AddBusinessDays(cmd.Context(), date, days, holidays)
+			if err != nil {
+				return err
+			}
+
+			return output.Print(cmd, args, result.Format(time.RFC3339))
+		},
+	}
+
+	addBusinessDaysCmd.Flags().StringVar(&holidaysFile, "holidays", "", "file listing one YYYY-MM-DD holiday per line")
+
+	return addBusinessDaysCmd
+}
+
+func businessDays() *cobra.Command {
+	var holidaysFile string
+
+	businessDaysCmd := &cobra.Command{
+		Use:   "business-days from to",
+		Short: "count business days between two dates",
+		Long:  `count the working days in [from, to) skipping weekends and holidays`,
+		Args:  twoDates,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, _ := calcdate.Parse(args[FIRST])
+			to, _ := calcdate.Parse(args[SECOND])
+
+			holidays, err := calcdate.LoadHolidays(holidaysFile)
+
+			if err != nil {
+				return err
+			}
+
+			count, err := calcdate.BusinessDays(cmd.Context(), from, to, holidays)
+			if err != nil {
+				return err
+			}
+
+			return output.Print(cmd, args, strconv.Itoa(count))
+		},
+	}
+
+	businessDaysCmd.Flags().StringVar(&holidaysFile, "holidays", "", "file listing one YYYY-MM-DD holiday per line")
+
+	return businessDaysCmd
+}
+
+func dateAndDuration(cmd *cobra.Command, args []string) error {
+	if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+		return err
+	}
+
+	if _, err := calcdate.Parse(args[FIRST]); err != nil {
+		return err
+	}
+
+	if _, err := calcdate.ParseDuration(args[SECOND]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func twoDates(cmd *cobra.Command, args []string) error {
+	if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+		return err
+	}
+
+	if _, err := calcdate.Parse(args[FIRST]); err != nil {
+		return err
+	}
+
+	if _, err := calcdate.Parse(args[SECOND]); err != nil {
+		return err
+	}
+
+	return nil
+}
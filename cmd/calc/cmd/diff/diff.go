@@ -0,0 +1,100 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/symbolic"
+	"github.com/spf13/cobra"
+)
+
+const (
+	EXPRESSION = 0
+	VARIABLE   = 1
+)
+
+func Diff() *cobra.Command {
+	var at []string
+
+	diffCmd := &cobra.Command{
+		Use:   "diff expression variable",
+		Short: "symbolic differentiation",
+		Long:  `differentiate an expression such as 'x^3 + 2*x' with respect to a variable`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := symbolic.Parse(args[EXPRESSION]); err != nil {
+				return err
+			}
+
+			_, err := bindings(at)
+
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e, _ := symbolic.Parse(args[EXPRESSION])
+
+			derivative, err := symbolic.DiffContext(cmd.Context(), e, args[VARIABLE])
+
+			if err != nil {
+				return err
+			}
+
+			if len(at) == 0 {
+				return output.Render(cmd, render.Record{Operands: args, Result: derivative.String(), Precision: render.Rational})
+			}
+
+			vars, _ := bindings(at)
+
+			value, err := symbolic.EvalContext(cmd.Context(), derivative, vars)
+
+			if err != nil {
+				return err
+			}
+
+			record := render.Record{
+				Operands:  args,
+				Result:    strconv.Itoa(value),
+				Details:   map[string]string{"derivative": derivative.String()},
+				Precision: render.Wrap,
+			}
+
+			if calc.IsChecked(cmd.Context()) {
+				record.Precision = render.Checked
+			}
+
+			return output.Render(cmd, record)
+		},
+	}
+
+	diffCmd.Flags().StringSliceVar(&at, "at", nil, "evaluate the derivative at name=value")
+
+	return diffCmd
+}
+
+func bindings(at []string) (map[string]int, error) {
+	vars := map[string]int{}
+
+	for _, binding := range at {
+		name, value, ok := strings.Cut(binding, "=")
+
+		if !ok {
+			return nil, fmt.Errorf("invalid binding %q, expected name=value", binding)
+		}
+
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+
+		vars[strings.TrimSpace(name)] = v
+	}
+
+	return vars, nil
+}
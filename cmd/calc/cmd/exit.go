@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/spf13/cobra"
+)
+
+// exit codes of calc, listed in the README
+const (
+	ExitOK             = 0
+	ExitFailure        = 1
+	ExitUsage          = 2
+	ExitInvalidOperand = 3
+	ExitDivisionByZero = 4
+	ExitOverflow       = 5
+	// the same as the timeout utility
+	TimeoutExitCode = 124
+)
+
+// usageError is a wrong command line: an unknown command or flag or a
+// wrong number of arguments
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode maps the error of a command to the exit code of calc
+func ExitCode(err error) int {
+	var usage usageError
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, calc.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return TimeoutExitCode
+	case errors.Is(err, calc.ErrDivisionByZero):
+		return ExitDivisionByZero
+	case errors.Is(err, calc.ErrOverflow):
+		return ExitOverflow
+	case errors.Is(err, calc.ErrInvalidOperand):
+		return ExitInvalidOperand
+	case errors.As(err, &usage), errors.Is(err, calc.ErrArity), errors.Is(err, calc.ErrUnknownOperation):
+		return ExitUsage
+	}
+
+	return ExitFailure
+}
+
+// Report writes the error of cmd to stderr, or as a record with the
+// structured outputs, and returns the exit code. --quiet leaves only the
+// exit code
+func Report(cmd *cobra.Command, err error) int {
+	code := ExitCode(err)
+
+	if code == ExitOK || output.IsReported(err) {
+		return code
+	}
+
+	if output.Structured(cmd) {
+		_ = output.Error(cmd, err)
+
+		return code
+	}
+
+	if flag := cmd.Flag("quiet"); flag != nil && flag.Value.String() == "true" {
+		return code
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "calc: %v\n", err)
+
+	if code == ExitUsage {
+		fmt.Fprintf(cmd.ErrOrStderr(), "\n%s", cmd.UsageString())
+	}
+
+	return code
+}
+
+// withUsage marks the errors of the argument validators as usage errors,
+// validators reporting a calc error such as ErrInvalidOperand keep it
+func withUsage(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err: err}
+	})
+
+	withArgs(cmd)
+}
+
+func withArgs(cmd *cobra.Command) {
+	for _, child := range cmd.Commands() {
+		withArgs(child)
+	}
+
+	args := cmd.Args
+	if args == nil {
+		return
+	}
+
+	cmd.Args = func(cmd *cobra.Command, a []string) error {
+		err := args(cmd, a)
+
+		if err == nil || ExitCode(err) != ExitFailure {
+			return err
+		}
+
+		return usageError{err: err}
+	}
+}
@@ -0,0 +1,371 @@
+package finance
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/finance"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FIRST  = 0
+	SECOND = 1
+	THIRD  = 2
+)
+
+// decimal places of printed amounts and rates
+const (
+	amountPlaces = 2
+	ratePlaces   = 6
+)
+
+func Finance() *cobra.Command {
+	financeCmd := &cobra.Command{
+		Use:   "finance",
+		Short: "financial functions",
+		Long:  `interest, present and future values, NPV, IRR and amortization schedules in exact decimal`,
+		Annotations: map[string]string{
+			output.PrecisionAnnotation: render.Decimal,
+		},
+	}
+
+	financeCmd.AddCommand(futureValue())
+	financeCmd.AddCommand(presentValue())
+	financeCmd.AddCommand(compound())
+	financeCmd.AddCommand(npv())
+	financeCmd.AddCommand(irr())
+	financeCmd.AddCommand(amortize())
+
+	return financeCmd
+}
+
+func futureValue() *cobra.Command {
+	fvCmd := &cobra.Command{
+		Use:   "fv present rate periods",
+		Short: "future value",
+		Long:  `future value of a present amount after periods at rate per period (0.05 or 5%)`,
+		Args:  amountRatePeriods,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			present, _ := finance.ParseDecimal(args[FIRST])
+			rate, _ := finance.ParseRate(args[SECOND])
+			periods, _ := strconv.Atoi(args[THIRD])
+
+			future, err := finance.FutureValue(present, rate, periods)
+			if err != nil {
+				return err
+			}
+
+			return printDecimal(cmd, args, future, amountPlaces)
+		},
+	}
+
+	return fvCmd
+}
+
+func presentValue() *cobra.Command {
+	pvCmd := &cobra.Command{
+		Use:   "pv future rate periods",
+		Short: "present value",
+		Long:  `present value of a future amount discounted for periods at rate per period (0.05 or 5%)`,
+		Args:  amountRatePeriods,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			future, _ := finance.ParseDecimal(args[FIRST])
+			rate, _ := finance.ParseRate(args[SECOND])
+			periods, _ := strconv.Atoi(args[THIRD])
+
+			present, err := finance.PresentValue(future, rate, periods)
+			if err != nil {
+				return err
+			}
+
+			return printDecimal(cmd, args, present, amountPlaces)
+		},
+	}
+
+	return pvCmd
+}
+
+func compound() *cobra.Command {
+	var perYear int
+
+	compoundCmd := &cobra.Command{
+		Use:   "compound principal rate years",
+		Short: "compound interest",
+		Long:  `interest earned by a principal at a yearly rate (0.05 or 5%) compounded several times a year`,
+		Args:  amountRatePeriods,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			principal, _ := finance.ParseDecimal(args[FIRST])
+			rate, _ := finance.ParseRate(args[SECOND])
+			years, _ := strconv.Atoi(args[THIRD])
+
+			interest, err := finance.CompoundInterest(principal, rate, years, perYear)
+
+			if err != nil {
+				return err
+			}
+
+			return printDecimal(cmd, args, interest, amountPlaces)
+		},
+	}
+
+	compoundCmd.Flags().IntVar(&perYear, "per-year", 1, "compounding periods per year")
+
+	return compoundCmd
+}
+
+func npv() *cobra.Command {
+	npvCmd := &cobra.Command{
+		Use:   "npv rate cashflow...",
+		Short: "net present value",
+		Long:  `net present value of cash flows at rate per period, the first cash flow happens at time zero`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MinimumNArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := finance.ParseRate(args[FIRST]); err != nil {
+				return err
+			}
+
+			_, err := cashFlows(args[SECOND:])
+
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rate, _ := finance.ParseRate(args[FIRST])
+			flows, _ := cashFlows(args[SECOND:])
+
+			npv, err := finance.NPV(rate, flows)
+			if err != nil {
+				return err
+			}
+
+			return printDecimal(cmd, args, npv, amountPlaces)
+		},
+	}
+
+	return npvCmd
+}
+
+func irr() *cobra.Command {
+	irrCmd := &cobra.Command{
+		Use:   "irr cashflow...",
+		Short: "internal rate of return",
+		Long:  `internal rate of return of cash flows, the first cash flow happens at time zero`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MinimumNArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			_, err := cashFlows(args)
+
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flows, _ := cashFlows(args)
+
+			irr, err := finance.IRR(flows)
+
+			if err != nil {
+				return err
+			}
+
+			return printDecimal(cmd, args, irr, ratePlaces)
+		},
+	}
+
+	return irrCmd
+}
+
+func amortize() *cobra.Command {
+	var perYear int
+	var asCSV bool
+
+	amortizeCmd := &cobra.Command{
+		Use:   "amortize principal rate payments",
+		Short: "amortization schedule",
+		Long:  `amortization schedule of a fixed-rate loan, rate is yearly and payments are spread over the year with --per-year`,
+		Args:  amountRatePeriods,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			principal, _ := finance.ParseDecimal(args[FIRST])
+			rate, _ := finance.ParseRate(args[SECOND])
+			payments, _ := strconv.Atoi(args[THIRD])
+
+			if perYear <= 0 {
+				return finance.ErrInvalidPeriods
+			}
+
+			periodRate := new(big.Rat).Quo(rate, big.NewRat(int64(perYear), 1))
+
+			schedule, err := finance.Amortize(principal, periodRate, payments, amountPlaces)
+
+			if err != nil {
+				return err
+			}
+
+			if output.Structured(cmd) {
+				return printRecords(cmd, args, schedule)
+			}
+
+			if asCSV {
+				return printCSV(cmd, args, schedule)
+			}
+
+			return printTable(cmd, args, schedule)
+		},
+	}
+
+	amortizeCmd.Flags().IntVar(&perYear, "per-year", 12, "payments per year")
+	amortizeCmd.Flags().BoolVar(&asCSV, "csv", false, "print the schedule as CSV, same as --output csv without the record columns")
+
+	return amortizeCmd
+}
+
+var scheduleHeader = []string{"period", "payment", "interest", "principal", "balance"}
+
+func scheduleRow(installment finance.Installment) []string {
+	return []string{
+		strconv.Itoa(installment.Period),
+		installment.Payment.FloatString(amountPlaces),
+		installment.Interest.FloatString(amountPlaces),
+		installment.Principal.FloatString(amountPlaces),
+		installment.Balance.FloatString(amountPlaces),
+	}
+}
+
+// printDecimal prints x rounded to places with --rounding
+func printDecimal(cmd *cobra.Command, args []string, x *big.Rat, places int) error {
+	rounding, err := output.Rounding(cmd)
+	if err != nil {
+		return err
+	}
+
+	return output.Print(cmd, args, rounding.Round(x, places).FloatString(places))
+}
+
+func printCSV(cmd *cobra.Command, args []string, schedule []finance.Installment) error {
+	var b strings.Builder
+
+	w := csv.NewWriter(&b)
+
+	if err := w.Write(scheduleHeader); err != nil {
+		return err
+	}
+
+	for _, installment := range schedule {
+		if err := w.Write(scheduleRow(installment)); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return output.Render(cmd, render.Record{Operands: args, Text: b.String()})
+}
+
+// printRecords renders an installment per record, the payment is the
+// result and the other columns are details
+func printRecords(cmd *cobra.Command, args []string, schedule []finance.Installment) error {
+	for _, installment := range schedule {
+		row := scheduleRow(installment)
+
+		details := map[string]string{}
+		for i, column := range scheduleHeader {
+			if column != "payment" {
+				details[column] = row[i]
+			}
+		}
+
+		record := render.Record{
+			Operands: args,
+			Result:   installment.Payment.FloatString(amountPlaces),
+			Details:  details,
+		}
+
+		if err := output.Render(cmd, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func printTable(cmd *cobra.Command, args []string, schedule []finance.Installment) error {
+	var b strings.Builder
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	for _, row := range append([][]string{scheduleHeader}, rows(schedule)...) {
+		for _, cell := range row {
+			if _, err := fmt.Fprintf(w, "%s\t", cell); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return output.Render(cmd, render.Record{Operands: args, Text: b.String()})
+}
+
+func rows(schedule []finance.Installment) [][]string {
+	rows := make([][]string, 0, len(schedule))
+
+	for _, installment := range schedule {
+		rows = append(rows, scheduleRow(installment))
+	}
+
+	return rows
+}
+
+func cashFlows(args []string) ([]*big.Rat, error) {
+	flows := make([]*big.Rat, 0, len(args))
+
+	for _, arg := range args {
+		flow, err := finance.ParseDecimal(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		flows = append(flows, flow)
+	}
+
+	return flows, nil
+}
+
+func amountRatePeriods(cmd *cobra.Command, args []string) error {
+	if err := cobra.ExactArgs(3)(cmd, args); err != nil {
+		return err
+	}
+
+	if _, err := finance.ParseDecimal(args[FIRST]); err != nil {
+		return err
+	}
+
+	if _, err := finance.ParseRate(args[SECOND]); err != nil {
+		return err
+	}
+
+	if _, err := strconv.Atoi(args[THIRD]); err != nil {
+		return err
+	}
+
+	return nil
+}
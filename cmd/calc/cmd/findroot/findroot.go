@@ -0,0 +1,97 @@
+package findroot
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/numeric"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/poly"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	COEFFICIENTS = 0
+	LOWER        = 1
+	UPPER        = 2
+)
+
+func FindRoot() *cobra.Command {
+	var method string
+	var opts numeric.Opts
+
+	rootCmd := &cobra.Command{
+		Use:   "root coefficients lower upper",
+		Short: "numeric root of a polynomial",
+		Long: `find a real root of a polynomial between lower and upper, coefficients are comma
+separated highest degree first, newton starts from the midpoint of the bounds`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(3)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := poly.Parse(args[COEFFICIENTS]); err != nil {
+				return err
+			}
+
+			for _, bound := range args[LOWER:] {
+				if _, err := strconv.ParseFloat(bound, 64); err != nil {
+					return fmt.Errorf("invalid bound %q", bound)
+				}
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, _ := poly.Parse(args[COEFFICIENTS])
+			lower, _ := strconv.ParseFloat(args[LOWER], 64)
+			upper, _ := strconv.ParseFloat(args[UPPER], 64)
+
+			var result numeric.Result
+			var err error
+
+			switch method {
+			case "bisection":
+				result, err = numeric.Bisection(p.Float, lower, upper, opts)
+			case "newton":
+				var derivative poly.Poly
+
+				if derivative, err = p.Derivative(cmd.Context()); err != nil {
+					return err
+				}
+
+				result, err = numeric.Newton(p.Float, derivative.Float, lower+(upper-lower)/2, opts)
+			case "brent":
+				result, err = numeric.Brent(p.Float, lower, upper, opts)
+			default:
+				return fmt.Errorf("unknown method %q", method)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			value := strconv.FormatFloat(result.Value, 'g', -1, 64)
+
+			record := render.Record{
+				Operands: args,
+				Result:   value,
+				Details: map[string]string{
+					"iterations":     strconv.Itoa(result.Iterations),
+					"error_estimate": fmt.Sprintf("%g", result.ErrorEstimate),
+				},
+				Precision: render.Float64,
+				Text:      fmt.Sprintf("%s\niterations: %d\nerror estimate: %g", value, result.Iterations, result.ErrorEstimate),
+			}
+
+			return output.Render(cmd, record)
+		},
+	}
+
+	rootCmd.Flags().StringVar(&method, "method", "brent", "bisection, newton or brent")
+	rootCmd.Flags().Float64Var(&opts.Tolerance, "tolerance", 1e-12, "absolute tolerance")
+	rootCmd.Flags().IntVar(&opts.MaxIterations, "max-iterations", 100, "iterations before giving up")
+
+	return rootCmd
+}
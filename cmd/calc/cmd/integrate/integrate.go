@@ -0,0 +1,89 @@
+package integrate
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/numeric"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/poly"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	COEFFICIENTS = 0
+	LOWER        = 1
+	UPPER        = 2
+)
+
+func Integrate() *cobra.Command {
+	var method string
+	var opts numeric.Opts
+
+	integrateCmd := &cobra.Command{
+		Use:   "integrate coefficients lower upper",
+		Short: "numeric integral of a polynomial",
+		Long: `integrate a polynomial from lower to upper, coefficients are comma separated
+highest degree first`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(3)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := poly.Parse(args[COEFFICIENTS]); err != nil {
+				return err
+			}
+
+			for _, bound := range args[LOWER:] {
+				if _, err := strconv.ParseFloat(bound, 64); err != nil {
+					return fmt.Errorf("invalid bound %q", bound)
+				}
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, _ := poly.Parse(args[COEFFICIENTS])
+			lower, _ := strconv.ParseFloat(args[LOWER], 64)
+			upper, _ := strconv.ParseFloat(args[UPPER], 64)
+
+			var result numeric.Result
+			var err error
+
+			switch method {
+			case "simpson":
+				result, err = numeric.Simpson(p.Float, lower, upper, opts)
+			case "romberg":
+				result, err = numeric.Romberg(p.Float, lower, upper, opts)
+			default:
+				return fmt.Errorf("unknown method %q", method)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			value := strconv.FormatFloat(result.Value, 'g', -1, 64)
+
+			record := render.Record{
+				Operands: args,
+				Result:   value,
+				Details: map[string]string{
+					"iterations":     strconv.Itoa(result.Iterations),
+					"error_estimate": fmt.Sprintf("%g", result.ErrorEstimate),
+				},
+				Precision: render.Float64,
+				Text:      fmt.Sprintf("%s\niterations: %d\nerror estimate: %g", value, result.Iterations, result.ErrorEstimate),
+			}
+
+			return output.Render(cmd, record)
+		},
+	}
+
+	integrateCmd.Flags().StringVar(&method, "method", "romberg", "simpson or romberg")
+	integrateCmd.Flags().Float64Var(&opts.Tolerance, "tolerance", 1e-10, "absolute tolerance")
+	integrateCmd.Flags().IntVar(&opts.MaxIterations, "max-iterations", 20, "refinements before giving up")
+
+	return integrateCmd
+}
@@ -0,0 +1,240 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FIRST  = 0
+	SECOND = 1
+)
+
+func Money() *cobra.Command {
+	moneyCmd := &cobra.Command{
+		Use:   "money",
+		Short: "currency-aware money arithmetic",
+		Long:  `money arithmetic on amounts such as 100.00EUR, stored in minor units`,
+		Annotations: map[string]string{
+			output.PrecisionAnnotation: render.Decimal,
+		},
+	}
+
+	moneyCmd.AddCommand(add())
+	moneyCmd.AddCommand(sub())
+	moneyCmd.AddCommand(mul())
+	moneyCmd.AddCommand(split())
+	moneyCmd.AddCommand(allocate())
+
+	return moneyCmd
+}
+
+func add() *cobra.Command {
+	addCmd := &cobra.Command{
+		Use:   "add first second",
+		Short: "add two amounts of the same currency",
+		Long:  `add two amounts of the same currency`,
+		Args:  twoAmounts,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			first, _ := calc.ParseMoney(args[FIRST])
+			second, _ := calc.ParseMoney(args[SECOND])
+
+			sum, err := first.Add(second)
+
+			if err != nil {
+				return err
+			}
+
+			return output.Print(cmd, args, sum.String())
+		},
+	}
+
+	return addCmd
+}
+
+func sub() *cobra.Command {
+	subCmd := &cobra.Command{
+		Use:   "sub first second",
+		Short: "subtract two amounts of the same currency",
+		Long:  `subtract two amounts of the same currency`,
+		Args:  twoAmounts,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			first, _ := calc.ParseMoney(args[FIRST])
+			second, _ := calc.ParseMoney(args[SECOND])
+
+			sub, err := first.Sub(second)
+
+			if err != nil {
+				return err
+			}
+
+			return output.Print(cmd, args, sub.String())
+		},
+	}
+
+	return subCmd
+}
+
+func mul() *cobra.Command {
+	mulCmd := &cobra.Command{
+		Use:   "mul amount factor",
+		Short: "multiply an amount by a factor",
+		Long:  `multiply an amount by a decimal or fractional factor (0.22, 1/3), rounding half to even unless --rounding says otherwise`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := calc.ParseMoney(args[FIRST]); err != nil {
+				return err
+			}
+
+			if _, ok := new(big.Rat).SetString(args[SECOND]); !ok {
+				return fmt.Errorf("invalid factor %q", args[SECOND])
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			amount, _ := calc.ParseMoney(args[FIRST])
+			factor, _ := new(big.Rat).SetString(args[SECOND])
+
+			rounding, err := output.Rounding(cmd)
+			if err != nil {
+				return err
+			}
+
+			product, err := amount.MultiplyRounding(factor, rounding)
+			if err != nil {
+				return err
+			}
+
+			return output.Print(cmd, args, product.String())
+		},
+	}
+
+	return mulCmd
+}
+
+func split() *cobra.Command {
+	splitCmd := &cobra.Command{
+		Use:   "split amount parts",
+		Short: "split an amount in equal parts",
+		Long:  `split an amount in equal parts without losing minor units`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := calc.ParseMoney(args[FIRST]); err != nil {
+				return err
+			}
+
+			if _, err := strconv.Atoi(args[SECOND]); err != nil {
+				return err
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			amount, _ := calc.ParseMoney(args[FIRST])
+			parts, _ := strconv.Atoi(args[SECOND])
+
+			allocation, err := amount.Split(parts)
+
+			if err != nil {
+				return err
+			}
+
+			return printAllocation(cmd, args, allocation)
+		},
+	}
+
+	return splitCmd
+}
+
+func allocate() *cobra.Command {
+	allocateCmd := &cobra.Command{
+		Use:   "allocate amount ratio...",
+		Short: "allocate an amount by ratios",
+		Long:  `allocate an amount proportionally to integer ratios with the largest remainder method`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MinimumNArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := calc.ParseMoney(args[FIRST]); err != nil {
+				return err
+			}
+
+			for _, arg := range args[SECOND:] {
+				if _, err := strconv.Atoi(arg); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			amount, _ := calc.ParseMoney(args[FIRST])
+
+			ratios := make([]int, 0, len(args)-1)
+
+			for _, arg := range args[SECOND:] {
+				ratio, _ := strconv.Atoi(arg)
+				ratios = append(ratios, ratio)
+			}
+
+			allocation, err := amount.Allocate(ratios...)
+
+			if err != nil {
+				return err
+			}
+
+			return printAllocation(cmd, args, allocation)
+		},
+	}
+
+	return allocateCmd
+}
+
+func twoAmounts(cmd *cobra.Command, args []string) error {
+	if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+		return err
+	}
+
+	if _, err := calc.ParseMoney(args[FIRST]); err != nil {
+		return err
+	}
+
+	if _, err := calc.ParseMoney(args[SECOND]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// printAllocation prints a part per line, structured outputs get the
+// parts space separated in a single result
+func printAllocation(cmd *cobra.Command, args []string, allocation []calc.Money) error {
+	parts := make([]string, 0, len(allocation))
+
+	for _, part := range allocation {
+		parts = append(parts, part.String())
+	}
+
+	record := render.Record{
+		Operands: args,
+		Result:   strings.Join(parts, " "),
+		Text:     strings.Join(parts, "\n"),
+	}
+
+	return output.Render(cmd, record)
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/spf13/cobra"
+)
+
+// withNumberFlags adds the persistent flags choosing how the results are
+// written: the base of the integers and the rounding of the decimals,
+// commands read them with cmd.Flag and output.Rounding
+func withNumberFlags(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().Int("base", 10, "base of the integer results, 2 to 36, with the plain format")
+	rootCmd.PersistentFlags().String("rounding", string(calc.HalfEven), "rounding of decimal results: half-even, half-up or down")
+}
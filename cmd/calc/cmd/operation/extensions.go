@@ -0,0 +1,187 @@
+package operation
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/units"
+)
+
+const (
+	FIRST  = 0
+	SECOND = 1
+)
+
+const quantitiesAndIntervals = "operands may carry units such as 1GiB or 250ms or be intervals such as [1,2], " +
+	"quantities are computed in float64 without the overflow checks of --precision checked"
+
+// Extend wraps the built-in operations of registry so they also take
+// quantities and intervals, operations that are already Extended are
+// left as they are
+func Extend(registry *calc.Registry) {
+	extensions := map[string]func(op calc.Operation) calc.Operation{
+		"sum": binary(
+			func(x, y calc.Interval) (string, error) {
+				return x.Add(y).String(), nil
+			},
+			units.Add,
+		),
+		"sub": binary(
+			func(x, y calc.Interval) (string, error) {
+				return x.Sub(y).String(), nil
+			},
+			units.Sub,
+		),
+		"mul": binary(
+			func(x, y calc.Interval) (string, error) {
+				return x.Mul(y).String(), nil
+			},
+			units.Mul,
+		),
+		"div": binary(
+			func(x, y calc.Interval) (string, error) {
+				parts, err := x.Div(y)
+				if err != nil {
+					return "", err
+				}
+
+				div := make([]string, 0, len(parts))
+				for _, part := range parts {
+					div = append(div, part.String())
+				}
+
+				// a divisor containing zero splits the result in two intervals
+				return strings.Join(div, " U "), nil
+			},
+			units.Div,
+		),
+		"pow": func(op calc.Operation) calc.Operation {
+			return intervalPower{Operation: op}
+		},
+	}
+
+	for name, extend := range extensions {
+		registry.Wrap(name, func(op calc.Operation) calc.Operation {
+			if _, ok := op.(calc.Extended); ok {
+				return op
+			}
+
+			return extend(op)
+		})
+	}
+}
+
+// quantities extends a binary operation to quantities such as 1GiB and
+// to intervals such as [1,2]
+type quantities struct {
+	calc.Operation
+	intervals  func(x, y calc.Interval) (string, error)
+	quantities func(a, b units.Quantity) (units.Quantity, error)
+}
+
+func binary(intervals func(x, y calc.Interval) (string, error), q func(a, b units.Quantity) (units.Quantity, error)) func(op calc.Operation) calc.Operation {
+	return func(op calc.Operation) calc.Operation {
+		return quantities{Operation: op, intervals: intervals, quantities: q}
+	}
+}
+
+func (q quantities) About() string {
+	return quantitiesAndIntervals
+}
+
+func (q quantities) ValidateOperands(operands ...string) error {
+	return validateOperands(operands)
+}
+
+// EvalOperands evaluates on intervals when one of the operands is an
+// interval and on quantities otherwise
+func (q quantities) EvalOperands(operands ...string) (string, error) {
+	if calc.IsInterval(operands[FIRST]) || calc.IsInterval(operands[SECOND]) {
+		x, err := calc.ParseInterval(operands[FIRST])
+		if err != nil {
+			return "", err
+		}
+
+		y, err := calc.ParseInterval(operands[SECOND])
+		if err != nil {
+			return "", err
+		}
+
+		return q.intervals(x, y)
+	}
+
+	a, _ := units.Parse(operands[FIRST])
+	b, _ := units.Parse(operands[SECOND])
+
+	result, err := q.quantities(a, b)
+	if err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
+
+// intervalPower extends pow to bases that are intervals such as [1,2]
+type intervalPower struct {
+	calc.Operation
+}
+
+func (p intervalPower) About() string {
+	return "the base may be an interval such as [1,2]"
+}
+
+func (p intervalPower) ValidateOperands(operands ...string) error {
+	return validatePow(operands)
+}
+
+func (p intervalPower) EvalOperands(operands ...string) (string, error) {
+	return powInterval(operands)
+}
+
+// operands are integers, quantities such as 1GiB or intervals such as [1,2]
+func validateOperands(args []string) error {
+	for _, operand := range args {
+		if calc.IsInterval(operand) {
+			if _, err := calc.ParseInterval(operand); err != nil {
+				return &calc.OperandError{Operand: operand, Err: err}
+			}
+
+			continue
+		}
+
+		if _, err := units.Parse(operand); err != nil {
+			return &calc.OperandError{Operand: operand, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func validatePow(args []string) error {
+	if calc.IsInterval(args[FIRST]) {
+		if _, err := calc.ParseInterval(args[FIRST]); err != nil {
+			return &calc.OperandError{Operand: args[FIRST], Err: err}
+		}
+	} else if _, err := strconv.Atoi(args[FIRST]); err != nil {
+		return &calc.OperandError{Operand: args[FIRST], Err: err}
+	}
+
+	if _, err := strconv.Atoi(args[SECOND]); err != nil {
+		return &calc.OperandError{Operand: args[SECOND], Err: err}
+	}
+
+	return nil
+}
+
+func powInterval(args []string) (string, error) {
+	x, _ := calc.ParseInterval(args[FIRST])
+	n, _ := strconv.Atoi(args[SECOND])
+
+	pow, err := x.Pow(n)
+	if err != nil {
+		return "", err
+	}
+
+	return pow.String(), nil
+}
@@ -0,0 +1,222 @@
+package operation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/format"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const DefaultLocale = "en-US"
+
+var ordinals = []string{"first", "second", "third", "fourth", "fifth", "sixth", "seventh", "eighth", "ninth", "tenth"}
+
+// Commands builds one command per registered operation
+func Commands(registry *calc.Registry) []*cobra.Command {
+	operations := registry.Operations()
+
+	commands := make([]*cobra.Command, 0, len(operations))
+
+	for _, op := range operations {
+		commands = append(commands, Command(op))
+	}
+
+	return commands
+}
+
+// Command builds the command of an operation, integer operands go to
+// op.Eval while Extended operations also accept the operands of their
+// extension, such as intervals and quantities
+func Command(op calc.Operation) *cobra.Command {
+	ext, extended := op.(calc.Extended)
+
+	long := op.Description()
+	if extended {
+		long = fmt.Sprintf("%s, %s", long, ext.About())
+	}
+
+	opCmd := &cobra.Command{
+		Use:   use(op),
+		Short: op.Description(),
+		Long:  long,
+		Annotations: map[string]string{
+			output.PrecisionAnnotation: render.Wrap,
+		},
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(op.Arity())(cmd, args); err != nil {
+				return err
+			}
+
+			_, locale, err := Formatting(cmd)
+			if err != nil {
+				return err
+			}
+
+			_, err = integers(args, locale)
+
+			if err != nil && extended {
+				return ext.ValidateOperands(args...)
+			}
+
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			style, locale, err := Formatting(cmd)
+			if err != nil {
+				return err
+			}
+
+			operands, err := integers(args, locale)
+
+			if err != nil {
+				result, err := ext.EvalOperands(args...)
+				if err != nil {
+					return err
+				}
+
+				return output.Render(cmd, render.Record{Operands: args, Result: result, Precision: render.Float64})
+			}
+
+			result, err := op.Eval(cmd.Context(), operands...)
+			if err != nil {
+				return err
+			}
+
+			formatted, err := formatResult(cmd, result, style, locale)
+			if err != nil {
+				return err
+			}
+
+			record := render.Record{Operands: args, Result: formatted}
+			if calc.IsChecked(cmd.Context()) {
+				record.Precision = render.Checked
+			}
+
+			return output.Render(cmd, record)
+		},
+	}
+
+	return opCmd
+}
+
+// List prints the registered operations
+func List(registry *calc.Registry) *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "operations",
+		Short: "list the operations",
+		Long:  `list the registered operations with their usage`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !output.Structured(cmd) {
+				lines := []string{}
+
+				for _, op := range registry.Operations() {
+					lines = append(lines, fmt.Sprintf("%-24s %s", use(op), op.Description()))
+				}
+
+				return output.Print(cmd, args, strings.Join(lines, "\n"))
+			}
+
+			for _, op := range registry.Operations() {
+				record := render.Record{
+					Result:  op.Name(),
+					Details: map[string]string{"usage": use(op), "description": op.Description()},
+				}
+
+				if err := output.Render(cmd, record); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return listCmd
+}
+
+func use(op calc.Operation) string {
+	words := []string{op.Name()}
+
+	for i := 0; i < op.Arity(); i++ {
+		if i < len(ordinals) {
+			words = append(words, ordinals[i])
+		} else {
+			words = append(words, fmt.Sprintf("operand%d", i+1))
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Formatting reads the --format and --locale flags, commands built
+// outside the calc root print plain numbers
+func Formatting(cmd *cobra.Command) (format.Style, format.Locale, error) {
+	style := format.Plain
+	locale, _ := format.LookupLocale(DefaultLocale)
+
+	if flag := cmd.Flag("format"); flag != nil {
+		parsed, err := format.ParseStyle(flag.Value.String())
+		if err != nil {
+			return "", format.Locale{}, err
+		}
+
+		style = parsed
+	}
+
+	if flag := cmd.Flag("locale"); flag != nil {
+		parsed, err := format.LookupLocale(flag.Value.String())
+		if err != nil {
+			return "", format.Locale{}, err
+		}
+
+		locale = parsed
+	}
+
+	return style, locale, nil
+}
+
+// formatResult writes result in the --base when it is not 10
+func formatResult(cmd *cobra.Command, result int, style format.Style, locale format.Locale) (string, error) {
+	base := 10
+
+	if flag := cmd.Flag("base"); flag != nil {
+		parsed, err := strconv.Atoi(flag.Value.String())
+		if err != nil {
+			return "", err
+		}
+
+		base = parsed
+	}
+
+	if base == 10 {
+		return format.Format(result, style, locale)
+	}
+
+	if style != format.Plain {
+		return "", fmt.Errorf("base %d only works with the plain format", base)
+	}
+
+	return format.FormatBase(result, base)
+}
+
+// integers accepts plain and locale grouped integers such as 1.234 in it-IT
+func integers(args []string, locale format.Locale) ([]int, error) {
+	operands := make([]int, 0, len(args))
+
+	for _, arg := range args {
+		n, err := format.ParseInt(arg, locale)
+		if err != nil {
+			return nil, &calc.OperandError{Operand: arg, Err: err}
+		}
+
+		operands = append(operands, n)
+	}
+
+	return operands, nil
+}
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+// PrecisionAnnotation is the cobra annotation holding the precision of
+// the results of a command, subcommands inherit it
+const PrecisionAnnotation = "precision"
+
+type rendererKey struct{}
+
+// With stores the renderer every result of the command goes through
+func With(ctx context.Context, r *render.Renderer) context.Context {
+	return context.WithValue(ctx, rendererKey{}, r)
+}
+
+// Renderer returns the renderer of the running command, the --output
+// flag is read when the command did not get one yet
+func Renderer(cmd *cobra.Command) *render.Renderer {
+	if ctx := cmd.Context(); ctx != nil {
+		if r, ok := ctx.Value(rendererKey{}).(*render.Renderer); ok {
+			return r
+		}
+	}
+
+	format := render.Text
+
+	if flag := cmd.Flag("output"); flag != nil {
+		if parsed, err := render.ParseFormat(flag.Value.String()); err == nil {
+			format = parsed
+		}
+	}
+
+	return render.New(os.Stdout, format)
+}
+
+// Structured is true for the json, yaml and csv outputs
+func Structured(cmd *cobra.Command) bool {
+	return Renderer(cmd).Format() != render.Text
+}
+
+// Print renders the result of cmd run on args
+func Print(cmd *cobra.Command, args []string, result string) error {
+	return Render(cmd, render.Record{Operands: args, Result: result})
+}
+
+// Render fills the operation and precision of record from cmd
+func Render(cmd *cobra.Command, record render.Record) error {
+	if record.Operation == "" {
+		record.Operation = Operation(cmd)
+	}
+
+	if record.Precision == "" {
+		record.Precision = Precision(cmd)
+	}
+
+	return Renderer(cmd).Render(record)
+}
+
+// reportedError is a failure the command already wrote, such as the
+// failed cells of a sheet
+type reportedError struct {
+	err error
+}
+
+func (e reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e reportedError) Unwrap() error {
+	return e.err
+}
+
+// Reported marks err as written by the command, calc only exits with
+// its code
+func Reported(err error) error {
+	return reportedError{err: err}
+}
+
+func IsReported(err error) bool {
+	var reported reportedError
+
+	return errors.As(err, &reported)
+}
+
+// Rounding reads the --rounding flag, commands built outside the calc
+// root round half to even
+func Rounding(cmd *cobra.Command) (calc.Rounding, error) {
+	if flag := cmd.Flag("rounding"); flag != nil {
+		return calc.ParseRounding(flag.Value.String())
+	}
+
+	return calc.HalfEven, nil
+}
+
+// Error renders a failed command, the text output leaves errors to cobra
+func Error(cmd *cobra.Command, err error) error {
+	return Render(cmd, render.Record{Operands: cmd.Flags().Args(), Error: err.Error()})
+}
+
+// Operation is the command path without the root command: poly div
+func Operation(cmd *cobra.Command) string {
+	path := strings.Fields(cmd.CommandPath())
+
+	return strings.Join(path[1:], " ")
+}
+
+// Precision is the precision annotation of cmd or of its closest parent
+func Precision(cmd *cobra.Command) string {
+	for c := cmd; c != nil; c = c.Parent() {
+		if precision, ok := c.Annotations[PrecisionAnnotation]; ok {
+			return precision
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,163 @@
+package plugins
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/plugin"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+// PathAnnotation holds the executable of the commands built for plugin
+// operations
+const PathAnnotation = "plugin"
+
+// Accept keeps the operations of the plugins that calc can run as
+// commands, operations clashing with an existing command or with an
+// operation of an earlier plugin are refused and reported in the errors
+func Accept(plugins []plugin.Plugin, taken func(name string) bool) ([]plugin.Plugin, []error) {
+	accepted := []plugin.Plugin{}
+	errs := []error{}
+
+	seen := map[string]string{}
+
+	for _, p := range plugins {
+		operations := []plugin.Spec{}
+
+		for _, spec := range p.Operations {
+			if taken(spec.Name) {
+				errs = append(errs, fmt.Errorf("plugin %s: operation %s clashes with a built-in command", filepath.Base(p.Path), spec.Name))
+				continue
+			}
+
+			if first, ok := seen[spec.Name]; ok {
+				errs = append(errs, fmt.Errorf("plugin %s: operation %s is already provided by %s", filepath.Base(p.Path), spec.Name, first))
+				continue
+			}
+
+			seen[spec.Name] = p.Path
+
+			operations = append(operations, spec)
+		}
+
+		if len(operations) > 0 {
+			accepted = append(accepted, plugin.Plugin{Path: p.Path, Operations: operations})
+		}
+	}
+
+	return accepted, errs
+}
+
+// Commands builds one command per operation of the plugins
+func Commands(plugins []plugin.Plugin) []*cobra.Command {
+	commands := []*cobra.Command{}
+
+	for _, p := range plugins {
+		for _, spec := range p.Operations {
+			commands = append(commands, command(p, spec))
+		}
+	}
+
+	return commands
+}
+
+func command(p plugin.Plugin, spec plugin.Spec) *cobra.Command {
+	words := []string{spec.Name}
+	for i := 1; i <= spec.Arity; i++ {
+		words = append(words, fmt.Sprintf("operand%d", i))
+	}
+
+	short := spec.Help
+	if short == "" {
+		short = fmt.Sprintf("%s operation", spec.Name)
+	}
+
+	pluginCmd := &cobra.Command{
+		Use:   strings.Join(words, " "),
+		Short: short,
+		Long:  fmt.Sprintf("%s\n\nprovided by the plugin %s", short, p.Path),
+		Args:  cobra.ExactArgs(spec.Arity),
+		Annotations: map[string]string{
+			PathAnnotation: p.Path,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := p.Eval(cmd.Context(), spec.Name, args)
+			if err != nil {
+				return err
+			}
+
+			return output.Print(cmd, args, result)
+		},
+	}
+
+	return pluginCmd
+}
+
+// List prints the discovered plugins, the ones failing the handshake and
+// the operations refused by Accept, it runs the handshakes again and
+// refreshes the cache the root command registers the plugins from
+func List(taken func(name string) bool) *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "plugins",
+		Short: "list the plugins",
+		Long: fmt.Sprintf(`list the %s* executables found in the calc plugins directory and on PATH
+with the operations they provide`, plugin.Prefix),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			discovered, errs := plugin.DefaultCache().Refresh(cmd.Context(), plugin.Dirs())
+
+			plugins, refused := Accept(discovered, taken)
+			errs = append(errs, refused...)
+
+			if output.Structured(cmd) {
+				return records(cmd, plugins, errs)
+			}
+
+			lines := []string{}
+
+			for _, p := range plugins {
+				lines = append(lines, p.Path)
+
+				for _, spec := range p.Operations {
+					lines = append(lines, fmt.Sprintf("  %-20s %s", spec.Name, spec.Help))
+				}
+			}
+
+			for _, err := range errs {
+				lines = append(lines, fmt.Sprintf("error: %v", err))
+			}
+
+			return output.Print(cmd, args, strings.Join(lines, "\n"))
+		},
+	}
+
+	return listCmd
+}
+
+// records prints a record per plugin operation and per failed handshake
+func records(cmd *cobra.Command, plugins []plugin.Plugin, errs []error) error {
+	for _, p := range plugins {
+		for _, spec := range p.Operations {
+			record := render.Record{
+				Result:  spec.Name,
+				Details: map[string]string{"plugin": p.Path, "arity": strconv.Itoa(spec.Arity), "help": spec.Help},
+			}
+
+			if err := output.Render(cmd, record); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, err := range errs {
+		if rerr := output.Render(cmd, render.Record{Error: err.Error()}); rerr != nil {
+			return rerr
+		}
+	}
+
+	return nil
+}
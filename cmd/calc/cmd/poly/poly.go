@@ -0,0 +1,210 @@
+package poly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/poly"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FIRST  = 0
+	SECOND = 1
+)
+
+func Poly() *cobra.Command {
+	polyCmd := &cobra.Command{
+		Use:   "poly",
+		Short: "polynomial arithmetic",
+		Long: `polynomial arithmetic on comma separated coefficient lists, highest degree first,
+e.g. 1,0,-2 is x^2 - 2, use -- before lists starting with a negative coefficient`,
+		Annotations: map[string]string{
+			output.PrecisionAnnotation: render.Rational,
+		},
+	}
+
+	polyCmd.AddCommand(binary("add", "add two polynomials", func(ctx context.Context, p, q poly.Poly) (render.Record, error) {
+		return result(p.Add(ctx, q))
+	}))
+	polyCmd.AddCommand(binary("sub", "subtract two polynomials", func(ctx context.Context, p, q poly.Poly) (render.Record, error) {
+		return result(p.Sub(ctx, q))
+	}))
+	polyCmd.AddCommand(binary("mul", "multiply two polynomials", func(ctx context.Context, p, q poly.Poly) (render.Record, error) {
+		return result(p.Mul(ctx, q))
+	}))
+	polyCmd.AddCommand(binary("div", "long division, prints quotient and remainder", func(ctx context.Context, p, q poly.Poly) (render.Record, error) {
+		quotient, remainder, err := p.DivMod(ctx, q)
+		if err != nil {
+			return render.Record{}, err
+		}
+
+		record := render.Record{
+			Result:  quotient.String(),
+			Details: map[string]string{"remainder": remainder.String()},
+			Text:    fmt.Sprintf("%s\n%s", quotient, remainder),
+		}
+
+		return record, nil
+	}))
+	polyCmd.AddCommand(binary("gcd", "monic greatest common divisor", func(ctx context.Context, p, q poly.Poly) (render.Record, error) {
+		return result(poly.GCD(ctx, p, q))
+	}))
+	polyCmd.AddCommand(derive())
+	polyCmd.AddCommand(eval())
+	polyCmd.AddCommand(roots())
+
+	return polyCmd
+}
+
+// result is the record of a polynomial, or the error computing it
+func result(p poly.Poly, err error) (render.Record, error) {
+	if err != nil {
+		return render.Record{}, err
+	}
+
+	return render.Record{Result: p.String()}, nil
+}
+
+func binary(name, description string, op func(ctx context.Context, p, q poly.Poly) (render.Record, error)) *cobra.Command {
+	binaryCmd := &cobra.Command{
+		Use:   name + " first second",
+		Short: description,
+		Long:  description,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := poly.Parse(args[FIRST]); err != nil {
+				return err
+			}
+
+			if _, err := poly.Parse(args[SECOND]); err != nil {
+				return err
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, _ := poly.Parse(args[FIRST])
+			q, _ := poly.Parse(args[SECOND])
+
+			record, err := op(cmd.Context(), p, q)
+
+			if err != nil {
+				return err
+			}
+
+			record.Operands = args
+
+			return output.Render(cmd, record)
+		},
+	}
+
+	return binaryCmd
+}
+
+func derive() *cobra.Command {
+	deriveCmd := &cobra.Command{
+		Use:   "derive coefficients",
+		Short: "derivative of a polynomial",
+		Long:  `derivative of a polynomial`,
+		Args:  onePolynomial,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, _ := poly.Parse(args[FIRST])
+
+			derivative, err := p.Derivative(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			return output.Print(cmd, args, derivative.String())
+		},
+	}
+
+	return deriveCmd
+}
+
+func eval() *cobra.Command {
+	evalCmd := &cobra.Command{
+		Use:   "eval coefficients x",
+		Short: "evaluate a polynomial",
+		Long:  `evaluate a polynomial at an integer or fraction with Horner's method`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			if _, err := poly.Parse(args[FIRST]); err != nil {
+				return err
+			}
+
+			if _, err := poly.ParseRat(args[SECOND]); err != nil {
+				return err
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, _ := poly.Parse(args[FIRST])
+			x, _ := poly.ParseRat(args[SECOND])
+
+			value, err := p.Eval(cmd.Context(), x)
+			if err != nil {
+				return err
+			}
+
+			return output.Print(cmd, args, value.String())
+		},
+	}
+
+	return evalCmd
+}
+
+func roots() *cobra.Command {
+	rootsCmd := &cobra.Command{
+		Use:   "roots coefficients",
+		Short: "rational roots of a polynomial",
+		Long:  `distinct rational roots of a polynomial, one per line`,
+		Args:  onePolynomial,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, _ := poly.Parse(args[FIRST])
+
+			roots, err := p.RationalRoots(cmd.Context())
+
+			if err != nil {
+				return err
+			}
+
+			lines := make([]string, 0, len(roots))
+
+			for _, root := range roots {
+				lines = append(lines, root.String())
+			}
+
+			record := render.Record{
+				Operands: args,
+				Result:   strings.Join(lines, " "),
+				Text:     strings.Join(lines, "\n"),
+			}
+
+			return output.Render(cmd, record)
+		},
+	}
+
+	return rootsCmd
+}
+
+func onePolynomial(cmd *cobra.Command, args []string) error {
+	if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+		return err
+	}
+
+	_, err := poly.Parse(args[FIRST])
+
+	return err
+}
@@ -0,0 +1,97 @@
+package random
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/dice"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	LOWER = 0
+	UPPER = 1
+)
+
+func Rand() *cobra.Command {
+	var seed int64
+	var count int
+	var verbose bool
+
+	randCmd := &cobra.Command{
+		Use:   "rand lower upper",
+		Short: "uniform random integers",
+		Long:  `uniform random integers between lower and upper included, use -- before negative bounds`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+
+			lower, err := strconv.Atoi(args[LOWER])
+			if err != nil {
+				return err
+			}
+
+			upper, err := strconv.Atoi(args[UPPER])
+			if err != nil {
+				return err
+			}
+
+			if lower > upper {
+				return fmt.Errorf("lower %d is above upper %d", lower, upper)
+			}
+
+			if count < 1 {
+				return fmt.Errorf("%w, got %d", dice.ErrCount, count)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lower, _ := strconv.Atoi(args[LOWER])
+			upper, _ := strconv.Atoi(args[UPPER])
+
+			if !cmd.Flags().Changed("seed") {
+				seed = time.Now().UnixNano()
+			}
+
+			random := rand.New(rand.NewSource(seed))
+
+			drawn, err := dice.Draw(random, lower, upper, count)
+			if err != nil {
+				return err
+			}
+
+			numbers := make([]string, 0, len(drawn))
+			for _, n := range drawn {
+				numbers = append(numbers, strconv.Itoa(n))
+			}
+
+			text := strings.Join(numbers, "\n")
+			if verbose {
+				text = fmt.Sprintf("seed: %d\n%s", seed, text)
+			}
+
+			record := render.Record{
+				Operands:  args,
+				Result:    strings.Join(numbers, " "),
+				Details:   map[string]string{"seed": strconv.FormatInt(seed, 10)},
+				Precision: render.Wrap,
+				Text:      text,
+			}
+
+			return output.Render(cmd, record)
+		},
+	}
+
+	randCmd.Flags().Int64Var(&seed, "seed", 0, "seed for reproducible numbers, random when unset")
+	randCmd.Flags().IntVar(&count, "count", 1, "how many numbers to draw")
+	randCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "show the seed")
+
+	return randCmd
+}
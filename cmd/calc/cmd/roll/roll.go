@@ -0,0 +1,87 @@
+package roll
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/dice"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	EXPRESSION = 0
+)
+
+func Roll() *cobra.Command {
+	var seed int64
+	var verbose bool
+
+	rollCmd := &cobra.Command{
+		Use:   "roll expression",
+		Short: "roll dice",
+		Long: `roll dice written in dice notation such as 3d6+2 or 1d20-1d4,
+use -- before expressions starting with a minus`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+
+			_, err := dice.Parse(args[EXPRESSION])
+
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			roll, _ := dice.Parse(args[EXPRESSION])
+
+			if !cmd.Flags().Changed("seed") {
+				seed = time.Now().UnixNano()
+			}
+
+			result := roll.Roll(rand.New(rand.NewSource(seed)))
+
+			record := render.Record{
+				Operands:  args,
+				Result:    strconv.Itoa(result.Total),
+				Details:   map[string]string{"seed": strconv.FormatInt(seed, 10)},
+				Precision: render.Wrap,
+			}
+
+			lines := []string{fmt.Sprintf("seed: %d", seed)}
+
+			for i, faces := range result.Faces {
+				sign := ""
+				if result.Dice[i].Negative {
+					sign = "-"
+				}
+
+				die := sign + result.Dice[i].String()
+				rolled := strings.Trim(fmt.Sprint(faces), "[]")
+
+				// keyed by position, the same die can appear more than once
+				record.Details[fmt.Sprintf("die %d", i+1)] = fmt.Sprintf("%s: %s", die, rolled)
+				lines = append(lines, fmt.Sprintf("%s: %s", die, rolled))
+			}
+
+			if result.Modifier != 0 {
+				record.Details["modifier"] = fmt.Sprintf("%+d", result.Modifier)
+				lines = append(lines, fmt.Sprintf("modifier: %+d", result.Modifier))
+			}
+
+			if verbose {
+				record.Text = strings.Join(append(lines, record.Result), "\n")
+			}
+
+			return output.Render(cmd, record)
+		},
+	}
+
+	rollCmd.Flags().Int64Var(&seed, "seed", 0, "seed for a reproducible roll, random when unset")
+	rollCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "show the seed and each die")
+
+	return rollCmd
+}
@@ -1,29 +1,244 @@
 package cmd
 
 import (
-	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/div"
-	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/mul"
-	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/pow"
-	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/sub"
-	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/sum"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/config"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/convertunit"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/csv"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/date"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/diff"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/finance"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/findroot"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/integrate"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/money"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/operation"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/plugins"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/poly"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/random"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/roll"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/rpc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/selftest"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/seq"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/serve"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/sheet"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/simplify"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/metrics"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/plugin"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
 	"github.com/spf13/cobra"
 )
 
 func Root() *cobra.Command {
+	var explain bool
+	var timeout time.Duration
+	var out string
+	var precision string
+	var metricsFile string
+	var configErr error
+
 	rootCmd := &cobra.Command{
 		Use:   "calc",
 		Short: "compute operations",
 		Long:  `A Fast and Flexible calculator built with love by MailUp`,
+		// errors are reported by Report
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		Args:          cobra.ArbitraryArgs,
 		PreRun: func(cmd *cobra.Command, args []string) {
 
 		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return usageError{err: fmt.Errorf("unknown command %q for %q", args[0], cmd.CommandPath())}
+			}
+
+			return cmd.Help()
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// config get and set still work to fix a broken config
+			if configErr != nil && !strings.HasPrefix(output.Operation(cmd), "config") {
+				return configErr
+			}
+
+			format, err := render.ParseFormat(out)
+			if err != nil {
+				return usageError{err: err}
+			}
+
+			cmd.SetContext(output.With(cmd.Context(), render.New(cmd.OutOrStdout(), format)))
+
+			switch precision {
+			case render.Wrap:
+			case render.Checked:
+				cmd.SetContext(calc.Checked(cmd.Context()))
+			default:
+				return usageError{err: fmt.Errorf("unknown precision %q, use %s or %s", precision, render.Wrap, render.Checked)}
+			}
+
+			if !explain {
+				return nil
+			}
+
+			// keep stdout parseable when the output is structured
+			steps := cmd.OutOrStdout()
+			if format != render.Text {
+				steps = cmd.ErrOrStderr()
+			}
+
+			calc.SetTracer(func(step calc.Step) {
+				fmt.Fprintf(steps, "%s%s: %s\n", strings.Repeat("  ", step.Depth), step.Op, step.Message)
+			})
+
+			return nil
+		},
 	}
 
-	rootCmd.AddCommand(sum.Sum())
-	rootCmd.AddCommand(sub.Sub())
-	rootCmd.AddCommand(mul.Mul())
-	rootCmd.AddCommand(div.Div())
-	rootCmd.AddCommand(pow.Pow())
+	rootCmd.PersistentFlags().BoolVar(&explain, "explain", false, "print the intermediate steps of the operations")
+	rootCmd.PersistentFlags().String("format", "plain", "result format: plain, grouped, words, roman, scientific or engineering")
+	rootCmd.PersistentFlags().String("locale", operation.DefaultLocale, "locale of grouped numbers and words such as en-US or it-IT")
+	rootCmd.PersistentFlags().StringVarP(&out, "output", "o", string(render.Text), "output of the results: text, json, yaml or csv")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "stop commands running longer than this, such as 500ms or 2s")
+	rootCmd.PersistentFlags().StringVar(&precision, "precision", render.Wrap, "integer arithmetic: wrap around like Go's int or checked to fail on overflow")
+	rootCmd.PersistentFlags().StringVar(&metricsFile, "metrics-file", "", "write Prometheus metrics of the operations to this file when the command ends")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "print no error messages, only the exit code tells the failure")
+
+	withNumberFlags(rootCmd)
+
+	// the config files and CALC_* variables change the flag defaults
+	configErr = config.Apply(rootCmd)
+
+	operation.Extend(calc.Default)
+
+	rootCmd.AddCommand(operation.Commands(calc.Default)...)
+	rootCmd.AddCommand(operation.List(calc.Default))
+	rootCmd.AddCommand(convertunit.ConvertUnit())
+	rootCmd.AddCommand(date.Date())
+	rootCmd.AddCommand(money.Money())
+	rootCmd.AddCommand(finance.Finance())
+	rootCmd.AddCommand(diff.Diff())
+	rootCmd.AddCommand(simplify.Simplify())
+	rootCmd.AddCommand(poly.Poly())
+	rootCmd.AddCommand(findroot.FindRoot())
+	rootCmd.AddCommand(integrate.Integrate())
+	rootCmd.AddCommand(seq.Seq())
+	rootCmd.AddCommand(roll.Roll())
+	rootCmd.AddCommand(random.Rand())
+	rootCmd.AddCommand(selftest.Selftest())
+	rootCmd.AddCommand(config.Config())
+	rootCmd.AddCommand(serve.Serve())
+	rootCmd.AddCommand(rpc.RPC())
+	rootCmd.AddCommand(csv.CSV())
+	rootCmd.AddCommand(sheet.Sheet())
+
+	rootCmd.AddCommand(plugins.List(func(name string) bool {
+		return taken(rootCmd, name)
+	}))
+
+	// plugins come last so they never shadow a built-in command, their
+	// handshakes are cached so calc only waits for the new or changed ones
+	discovered, _ := plugin.DefaultCache().Discover(context.Background(), plugin.Dirs())
+	accepted, _ := plugins.Accept(discovered, func(name string) bool {
+		return taken(rootCmd, name)
+	})
+
+	rootCmd.AddCommand(plugins.Commands(accepted)...)
+
+	withDeadline(rootCmd, &timeout)
+	withMetrics(rootCmd, &metricsFile)
+	withUsage(rootCmd)
 
 	return rootCmd
 }
+
+// withDeadline wraps the RunE of every command so it runs with the
+// --timeout deadline in its context, commands not watching the context
+// are abandoned when the deadline passes
+func withDeadline(cmd *cobra.Command, timeout *time.Duration) {
+	for _, child := range cmd.Commands() {
+		withDeadline(child, timeout)
+	}
+
+	run := cmd.RunE
+	if run == nil {
+		return
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if *timeout <= 0 {
+			return run(cmd, args)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), *timeout)
+		defer cancel()
+
+		cmd.SetContext(ctx)
+
+		done := make(chan error, 1)
+
+		go func() {
+			done <- run(cmd, args)
+		}()
+
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return fmt.Errorf("%w after %s", calc.ErrTimeout, *timeout)
+		}
+	}
+}
+
+// withMetrics wraps the RunE of every command so the calls of the
+// operations are written to --metrics-file, failed commands included
+func withMetrics(cmd *cobra.Command, path *string) {
+	for _, child := range cmd.Commands() {
+		withMetrics(child, path)
+	}
+
+	run := cmd.RunE
+	if run == nil {
+		return
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if *path == "" {
+			return run(cmd, args)
+		}
+
+		calc.SetObserver(metrics.Default)
+
+		err := run(cmd, args)
+
+		if writeErr := metrics.Default.WriteFile(*path); err == nil {
+			return writeErr
+		}
+
+		return err
+	}
+}
+
+// taken reports whether a built-in command has the name
+func taken(rootCmd *cobra.Command, name string) bool {
+	// added by cobra on Execute
+	if name == "help" || name == "completion" {
+		return true
+	}
+
+	for _, cmd := range rootCmd.Commands() {
+		if _, ok := cmd.Annotations[plugins.PathAnnotation]; ok {
+			continue
+		}
+
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+
+	return false
+}
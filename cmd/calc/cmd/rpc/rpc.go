@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/jsonrpc"
+	"github.com/spf13/cobra"
+)
+
+func RPC() *cobra.Command {
+	var opts jsonrpc.Options
+
+	rpcCmd := &cobra.Command{
+		Use:   "rpc",
+		Short: "answer JSON-RPC 2.0 requests on stdin and stdout",
+		Long: fmt.Sprintf(`answer JSON-RPC 2.0 requests framed with Content-Length headers like the Language Server Protocol,
+every operation is a method taking its operands as [1, 2] or {"operands": [1, 2], "precision": "checked"},
+%s lists them and %s cancels a request by id. Batches are supported,
+the session ends with stdin`, jsonrpc.CapabilitiesMethod, jsonrpc.CancelMethod),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return jsonrpc.New(calc.Default, opts).Serve(ctx, cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	rpcCmd.Flags().DurationVar(&opts.Timeout, "request-timeout", 10*time.Second, "stop evaluations running longer than this, 0 for no limit")
+
+	return rpcCmd
+}
@@ -0,0 +1,119 @@
+package selftest
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/selftest"
+	"github.com/spf13/cobra"
+)
+
+func Selftest() *cobra.Command {
+	var seed int64
+	var count int
+
+	selftestCmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "check the operations against Go's native operators",
+		Long: `compare sum, sub, mul, div and pow with Go's native operators on edge cases
+(zero, ±1, the int limits, powers of two) and on random operands, the seed is
+printed so a failing run can be repeated with --seed`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("seed") {
+				seed = time.Now().UnixNano()
+			}
+
+			report, err := selftest.Run(cmd.Context(), seed, count)
+			if err != nil {
+				return err
+			}
+
+			platform := fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)
+
+			if output.Structured(cmd) {
+				if err := printRecords(cmd, platform, report); err != nil {
+					return err
+				}
+			} else {
+				if err := printText(cmd, platform, report); err != nil {
+					return err
+				}
+			}
+
+			if report.Failed() {
+				return errors.New("selftest failed")
+			}
+
+			return nil
+		},
+	}
+
+	selftestCmd.Flags().Int64Var(&seed, "seed", 0, "seed of the random operands, random when unset")
+	selftestCmd.Flags().IntVar(&count, "count", 10000, "random checks per operation")
+
+	return selftestCmd
+}
+
+func printText(cmd *cobra.Command, platform string, report selftest.Report) error {
+	lines := []string{fmt.Sprintf("platform: %s", platform), fmt.Sprintf("seed: %d", report.Seed)}
+
+	for _, result := range report.Results {
+		status := "PASS"
+		if result.Failed > 0 {
+			status = "FAIL"
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %-4s passed %d failed %d", status, result.Operation, result.Passed, result.Failed))
+
+		for _, failure := range result.Failures {
+			lines = append(lines, fmt.Sprintf("  %s %s", result.Operation, failure))
+		}
+	}
+
+	if report.Failed() {
+		return output.Print(cmd, nil, strings.Join(lines, "\n")+"\n")
+	}
+
+	return output.Print(cmd, nil, strings.Join(append(lines, "PASS"), "\n"))
+}
+
+// printRecords renders a record per operation, failing operations carry
+// their failures in the error
+func printRecords(cmd *cobra.Command, platform string, report selftest.Report) error {
+	for _, result := range report.Results {
+		record := render.Record{
+			Result: "PASS",
+			Details: map[string]string{
+				"operation": result.Operation,
+				"platform":  platform,
+				"seed":      strconv.FormatInt(report.Seed, 10),
+				"passed":    strconv.Itoa(result.Passed),
+				"failed":    strconv.Itoa(result.Failed),
+			},
+			Precision: render.Wrap,
+		}
+
+		if result.Failed > 0 {
+			failures := make([]string, 0, len(result.Failures))
+			for _, failure := range result.Failures {
+				failures = append(failures, failure.String())
+			}
+
+			record.Result = "FAIL"
+			record.Error = strings.Join(failures, "; ")
+		}
+
+		if err := output.Render(cmd, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
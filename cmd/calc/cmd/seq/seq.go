@@ -0,0 +1,226 @@
+package seq
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/seq"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FIRST  = 0
+	SECOND = 1
+)
+
+const defaultCount = "10"
+
+func Seq() *cobra.Command {
+	seqCmd := &cobra.Command{
+		Use:   "seq",
+		Short: "sequences and series",
+		Long: `print the terms of a sequence one per line, terms are streamed so
+calc seq fib --count 1e6 | head only computes what is read`,
+		Annotations: map[string]string{
+			output.PrecisionAnnotation: render.Arbitrary,
+		},
+	}
+
+	seqCmd.AddCommand(arithmetic())
+	seqCmd.AddCommand(geometric())
+	seqCmd.AddCommand(fibonacci())
+	seqCmd.AddCommand(lucas())
+	seqCmd.AddCommand(triangular())
+	seqCmd.AddCommand(primes())
+
+	return seqCmd
+}
+
+func arithmetic() *cobra.Command {
+	var count string
+	var sum bool
+
+	arithCmd := &cobra.Command{
+		Use:   "arith first step",
+		Short: "arithmetic progression",
+		Long:  `arithmetic progression first, first+step, first+2*step, ...`,
+		Args:  twoInts,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			first, _ := strconv.Atoi(args[FIRST])
+			step, _ := strconv.Atoi(args[SECOND])
+
+			n, err := parseCount(count)
+			if err != nil {
+				return err
+			}
+
+			if sum {
+				series, err := calc.ArithmeticSeriesContext(cmd.Context(), first, step, n)
+				if err != nil {
+					return err
+				}
+
+				return printSum(cmd, args, series)
+			}
+
+			return stream(cmd, args, seq.Take(seq.Arithmetic(big.NewInt(int64(first)), big.NewInt(int64(step))), n))
+		},
+	}
+
+	arithCmd.Flags().StringVar(&count, "count", defaultCount, "number of terms, 1e6 is accepted")
+	arithCmd.Flags().BoolVar(&sum, "sum", false, "print the sum of the terms instead")
+
+	return arithCmd
+}
+
+func geometric() *cobra.Command {
+	var count string
+	var sum bool
+
+	geomCmd := &cobra.Command{
+		Use:   "geom first ratio",
+		Short: "geometric progression",
+		Long:  `geometric progression first, first*ratio, first*ratio^2, ...`,
+		Args:  twoInts,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			first, _ := strconv.Atoi(args[FIRST])
+			ratio, _ := strconv.Atoi(args[SECOND])
+
+			n, err := parseCount(count)
+			if err != nil {
+				return err
+			}
+
+			if sum {
+				series, err := calc.GeometricSeriesContext(cmd.Context(), first, ratio, n)
+				if err != nil {
+					return err
+				}
+
+				return printSum(cmd, args, series)
+			}
+
+			return stream(cmd, args, seq.Take(seq.Geometric(big.NewInt(int64(first)), big.NewInt(int64(ratio))), n))
+		},
+	}
+
+	geomCmd.Flags().StringVar(&count, "count", defaultCount, "number of terms, 1e6 is accepted")
+	geomCmd.Flags().BoolVar(&sum, "sum", false, "print the sum of the terms instead")
+
+	return geomCmd
+}
+
+func fibonacci() *cobra.Command {
+	return indexed("fib", "Fibonacci numbers", `Fibonacci numbers from F(start), F(0) = 0 and F(1) = 1`, seq.Fibonacci)
+}
+
+func lucas() *cobra.Command {
+	return indexed("lucas", "Lucas numbers", `Lucas numbers from L(start), L(0) = 2 and L(1) = 1`, seq.Lucas)
+}
+
+func triangular() *cobra.Command {
+	return indexed("triangular", "triangular numbers", `triangular numbers from T(start), T(n) = 1 + 2 + ... + n`, seq.Triangular)
+}
+
+// indexed builds the commands of sequences starting at an index
+func indexed(name, short, long string, sequence func(start int) seq.Sequence) *cobra.Command {
+	var count string
+	var start int
+
+	indexedCmd := &cobra.Command{
+		Use:   name,
+		Short: short,
+		Long:  long,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if start < 0 {
+				return fmt.Errorf("invalid start %d", start)
+			}
+
+			n, err := parseCount(count)
+			if err != nil {
+				return err
+			}
+
+			return stream(cmd, args, seq.Take(sequence(start), n))
+		},
+	}
+
+	indexedCmd.Flags().StringVar(&count, "count", defaultCount, "number of terms, 1e6 is accepted")
+	indexedCmd.Flags().IntVar(&start, "start", 0, "index of the first term")
+
+	return indexedCmd
+}
+
+func primes() *cobra.Command {
+	primesCmd := &cobra.Command{
+		Use:   "primes lower upper",
+		Short: "prime numbers in a range",
+		Long:  `prime numbers between lower and upper included, computed with a segmented sieve`,
+		Args:  twoInts,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lower, _ := strconv.Atoi(args[FIRST])
+			upper, _ := strconv.Atoi(args[SECOND])
+
+			return stream(cmd, args, seq.Primes(lower, upper))
+		},
+	}
+
+	return primesCmd
+}
+
+// stream renders a record per term as it is generated, so a closed pipe
+// stops the generation
+func stream(cmd *cobra.Command, args []string, s seq.Sequence) error {
+	for term, ok := s.Next(); ok; term, ok = s.Next() {
+		if err := output.Render(cmd, render.Record{Operands: args, Result: term.String()}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printSum renders the closed-form sum of a progression with the
+// precision it was computed in
+func printSum(cmd *cobra.Command, args []string, sum int) error {
+	record := render.Record{Operands: args, Result: strconv.Itoa(sum), Precision: render.Wrap}
+	if calc.IsChecked(cmd.Context()) {
+		record.Precision = render.Checked
+	}
+
+	return output.Render(cmd, record)
+}
+
+// parseCount accepts integers and exponent notation such as 1e6
+func parseCount(count string) (int, error) {
+	if n, err := strconv.Atoi(count); err == nil && n >= 0 {
+		return n, nil
+	}
+
+	f, err := strconv.ParseFloat(count, 64)
+	if err != nil || f < 0 || f != math.Trunc(f) || f > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid count %q", count)
+	}
+
+	return int(f), nil
+}
+
+func twoInts(cmd *cobra.Command, args []string) error {
+	if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		if _, err := strconv.Atoi(arg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package serve
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/graphql"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/metrics"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/server"
+	"github.com/spf13/cobra"
+)
+
+func Serve() *cobra.Command {
+	var addr string
+	var withGraphQL bool
+	var opts server.Options
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "serve the operations over HTTP",
+		Long: fmt.Sprintf(`serve every operation as POST %s<operation> with a JSON body such as {"operands": [1, 2]},
+POST %s evaluates several requests, GET %s describes the API,
+GET %s and %s are the liveness and readiness probes.
+--graphql adds a GraphQL endpoint at %s with a query field per operation,
+GET %s exposes the Prometheus metrics of the operations.
+SIGINT and SIGTERM stop the server after the requests in flight`, server.Prefix, server.BatchPath, server.OpenAPIPath, server.HealthPath, server.ReadyPath, server.GraphQLPath, server.MetricsPath),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if withGraphQL {
+				handler, err := graphql.New(calc.Default, graphql.Options{Timeout: opts.Timeout})
+				if err != nil {
+					return err
+				}
+
+				opts.GraphQL = handler
+			}
+
+			calc.SetObserver(metrics.Default)
+			opts.Metrics = metrics.Default
+
+			l, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "calc listening on %s\n", l.Addr())
+
+			return server.New(calc.Default, opts).Serve(ctx, l)
+		},
+	}
+
+	serveCmd.Flags().StringVar(&addr, "addr", ":8080", "address to listen on")
+	serveCmd.Flags().BoolVar(&withGraphQL, "graphql", false, fmt.Sprintf("serve GraphQL at %s as well", server.GraphQLPath))
+	serveCmd.Flags().DurationVar(&opts.Timeout, "request-timeout", 10*time.Second, "stop evaluations running longer than this, 0 for no limit")
+	serveCmd.Flags().DurationVar(&opts.ShutdownTimeout, "shutdown-timeout", 10*time.Second, "wait this long for the requests in flight on shutdown")
+
+	return serveCmd
+}
@@ -0,0 +1,142 @@
+package sheet
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/sheet"
+	"github.com/spf13/cobra"
+)
+
+const FILE = 0
+
+func Sheet() *cobra.Command {
+	var delimiter string
+	var strict bool
+	var comma rune
+
+	sheetCmd := &cobra.Command{
+		Use:   "sheet [file]",
+		Short: "evaluate the formulas of a CSV spreadsheet",
+		Long: `evaluate the cells of a CSV file, or of stdin, holding formulas like =A1*B2 or =SUM(A1:A10),
+formulas use + - * / ^, the functions SUM, AVG, MIN, MAX and COUNT and the operations in upper case: POW(2, 10).
+The formulas are computed in the order of their references with the integer arithmetic of the operations,
+AVG is exact and rounded to an integer with --rounding like the averages of calc csv.
+Failed cells show #DIV/0!, #NUM!, #VALUE!, #REF!, #NAME?, #CYCLE! or #ERROR!, are reported on stderr
+and make calc exit with the code of the first failure.
+The grid is written as a table, or as CSV with --output csv, json and yaml write a record per formula`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+				return err
+			}
+
+			r, size := utf8.DecodeRuneInString(delimiter)
+			if size == 0 || size != len(delimiter) {
+				return fmt.Errorf("invalid delimiter %q, use a single character", delimiter)
+			}
+
+			comma = r
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var in io.Reader = cmd.InOrStdin()
+
+			if len(args) > 0 && args[FILE] != "-" {
+				f, err := os.Open(args[FILE])
+				if err != nil {
+					return err
+				}
+
+				defer f.Close()
+
+				in = f
+			}
+
+			s, err := sheet.Read(in, comma)
+			if err != nil {
+				return err
+			}
+
+			rounding, err := output.Rounding(cmd)
+			if err != nil {
+				return err
+			}
+
+			result, err := s.Evaluate(cmd.Context(), sheet.Options{Rounding: rounding})
+			if err != nil {
+				return err
+			}
+
+			if len(result.Errors) > 0 && strict {
+				return result.Errors[0]
+			}
+
+			format := output.Renderer(cmd).Format()
+
+			switch format {
+			case render.CSV:
+				err = result.WriteCSV(cmd.OutOrStdout(), comma)
+			case render.Text:
+				err = result.WriteTable(cmd.OutOrStdout())
+			default:
+				err = records(cmd, s, result)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if len(result.Errors) == 0 {
+				return nil
+			}
+
+			// the records carry the errors already
+			if flag := cmd.Flag("quiet"); format != render.JSON && format != render.YAML && (flag == nil || flag.Value.String() != "true") {
+				for _, cellErr := range result.Errors {
+					fmt.Fprintf(cmd.ErrOrStderr(), "calc: %v\n", cellErr)
+				}
+			}
+
+			// the first failure tells the exit code
+			return output.Reported(result.Errors[0])
+		},
+	}
+
+	sheetCmd.Flags().StringVar(&delimiter, "delimiter", ",", "field delimiter of the input and of the csv output")
+	sheetCmd.Flags().BoolVar(&strict, "strict", false, "write no grid when a cell fails, only the error of the first failed cell")
+
+	return sheetCmd
+}
+
+// records renders a record per formula with its cell and formula in the
+// details, failed formulas carry their error
+func records(cmd *cobra.Command, s *sheet.Sheet, result *sheet.Result) error {
+	errs := map[sheet.Cell]error{}
+
+	for _, err := range result.Errors {
+		errs[err.Cell] = err
+	}
+
+	for _, c := range result.Formulas {
+		record := render.Record{
+			Result:  result.Values[c.Row][c.Col],
+			Details: map[string]string{"cell": c.String(), "formula": s.Text(c)},
+		}
+
+		if err, failed := errs[c]; failed {
+			record.Result = ""
+			record.Error = err.Error()
+		}
+
+		if err := output.Render(cmd, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package simplify
+
+import (
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd/output"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/symbolic"
+	"github.com/spf13/cobra"
+)
+
+const (
+	EXPRESSION = 0
+)
+
+func Simplify() *cobra.Command {
+	simplifyCmd := &cobra.Command{
+		Use:   "simplify expression",
+		Short: "algebraic simplification",
+		Long:  `fold constants and collect like terms of an expression such as '2*x + 3*x'`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+
+			_, err := symbolic.Parse(args[EXPRESSION])
+
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e, _ := symbolic.Parse(args[EXPRESSION])
+
+			simplified, err := symbolic.SimplifyContext(cmd.Context(), e)
+			if err != nil {
+				return err
+			}
+
+			return output.Render(cmd, render.Record{Operands: args, Result: simplified.String(), Precision: render.Rational})
+		},
+	}
+
+	return simplifyCmd
+}
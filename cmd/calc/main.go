@@ -1,15 +1,15 @@
 package main
 
 import (
-	"fmt"
+	"os"
 
 	"github.com/alvise88/zero-turnaround-cicd-with-dagger/cmd/calc/cmd"
 )
 
 func main() {
-	err := cmd.Root().Execute()
+	c, err := cmd.Root().ExecuteC()
 
-	if err != nil {
-		fmt.Println("unable to run calc cli")
+	if code := cmd.Report(c, err); code != cmd.ExitOK {
+		os.Exit(code)
 	}
 }
@@ -6,15 +6,17 @@ require (
 	dagger.io/dagger v0.4.5
 	github.com/magefile/mage v1.14.0
 	github.com/spf13/cobra v1.6.1
+	github.com/vektah/gqlparser/v2 v2.5.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/Khan/genqlient v0.5.0 // indirect
 	github.com/adrg/xdg v0.4.0 // indirect
+	github.com/agnivade/levenshtein v1.1.1 // indirect
 	github.com/iancoleman/strcase v0.2.0 // indirect
 	github.com/inconshreveable/mousetrap v1.0.1 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	github.com/vektah/gqlparser/v2 v2.5.1 // indirect
 	golang.org/x/sync v0.0.0-20220722155255-886fb9371eb4 // indirect
 	golang.org/x/sys v0.0.0-20220811171246-fbc7d0a398ab // indirect
 )
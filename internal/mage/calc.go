@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"dagger.io/dagger"
 	"github.com/magefile/mage/mg"
@@ -127,6 +128,56 @@ func (calc Calc) Test(ctx context.Context) error {
 	return err
 }
 
+// Selftest runs calc selftest on the linux platforms of the build matrix,
+// platforms other than the host are emulated
+func (calc Calc) Selftest(ctx context.Context) error {
+	fmt.Println("Self testing with Dagger")
+
+	client, err := dagger.Connect(ctx, dagger.WithLogOutput(os.Stderr))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// get reference to the local project
+	src := client.Host().Directory(".")
+
+	// get `golang` image
+	golang := client.Container().From(fmt.Sprintf("golang:%s-alpine", goVersion))
+
+	// mount cloned repository into `golang` image
+	golang = golang.WithMountedDirectory("/src", src).WithWorkdir("/src")
+
+	for _, goarch := range arches {
+		platform := dagger.Platform(fmt.Sprintf("linux/%s", goarch))
+
+		// build application (crosscompilation)
+		build := golang.WithEnvVariable("GOOS", "linux")
+		build = build.WithEnvVariable("GOARCH", goarch)
+		build = build.WithEnvVariable("CGO_ENABLED", "0")
+		build = build.WithExec([]string{"go", "build", "-o", "/output/calc", "./cmd/calc"})
+
+		// run the binary on its own platform
+		output, err := client.
+			Container(dagger.ContainerOpts{Platform: platform}).
+			From("alpine:3.17").
+			WithFile("/usr/local/bin/calc", build.File("/output/calc")).
+			WithExec([]string{"calc", "selftest"}).
+			Stdout(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(output)
+
+		if !strings.HasSuffix(strings.TrimSpace(output), "PASS") {
+			return fmt.Errorf("selftest failed on %s", platform)
+		}
+	}
+
+	return nil
+}
+
 func platforms() []dagger.Platform {
 	platforms := []dagger.Platform{}
 	for _, goos := range oses {
@@ -1,65 +1,342 @@
 package calc
 
-import "errors"
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// the loops check for cancellation every checkInterval iterations
+const checkInterval = 1 << 10
 
 func Sum(first, second int) int {
+	if o := currentObserver(); o != nil {
+		defer observe(o, "sum", time.Now(), nil, first, second)
+	}
+
+	return sum(first, second, currentTracer())
+}
+
+func sum(first, second int, t Tracer) int {
 	a := first
 	b := second
 
-	for b != 0 {
+	t.trace("sum", 0, "%d + %d", first, second)
+
+	for round := 1; b != 0; round++ {
 		carry := a & b // Carry value is calculated
 		a ^= b         // Sum value is calculated and stored in a
 		b = carry << 1 // The carry value is shifted towards left by a bit
+
+		t.trace("sum", 1, "round %d: partial %d (%b), carry %d (%b)", round, a, a, b, b)
 	}
 
 	return a // returns the final sum
 }
 
+// SumContext is Sum returning ErrOverflow in Checked contexts
+func SumContext(ctx context.Context, first, second int) (s int, err error) {
+	if o := currentObserver(); o != nil {
+		defer observe(o, "sum", time.Now(), &err, first, second)
+	}
+
+	if err := contextError(ctx); err != nil {
+		return 0, err
+	}
+
+	s = sum(first, second, currentTracer())
+
+	// operands of the same sign with a result of the other sign
+	if IsChecked(ctx) && (first < 0) == (second < 0) && (s < 0) != (first < 0) {
+		return 0, ErrOverflow
+	}
+
+	return s, nil
+}
+
 func Sub(first, second int) int {
-	return Sum(first, -second)
+	if o := currentObserver(); o != nil {
+		defer observe(o, "sub", time.Now(), nil, first, second)
+	}
+
+	return sum(first, -second, currentTracer())
+}
+
+// SubContext is Sub returning ErrOverflow in Checked contexts
+func SubContext(ctx context.Context, first, second int) (s int, err error) {
+	if o := currentObserver(); o != nil {
+		defer observe(o, "sub", time.Now(), &err, first, second)
+	}
+
+	if err := contextError(ctx); err != nil {
+		return 0, err
+	}
+
+	s = sum(first, -second, currentTracer())
+
+	// operands of opposite signs with a result of the sign of the second
+	if IsChecked(ctx) && (first < 0) != (second < 0) && (s < 0) != (first < 0) {
+		return 0, ErrOverflow
+	}
+
+	return s, nil
 }
 
+// Mul is first * second, negative operands included, wrapping around on
+// overflow like Go's *, selftest checks it against the native operator
 func Mul(first, second int) int {
-	var mul int
-	for i := 0; i < second; i++ {
-		mul = Sum(mul, first)
+	if o := currentObserver(); o != nil {
+		defer observe(o, "mul", time.Now(), nil, first, second)
 	}
+
+	mul, _ := mul(context.Background(), first, second, currentTracer())
+
 	return mul
 }
 
-func Div(first, second int) (int, error) {
+// MulContext is Mul returning ctx.Err() once ctx is done, ErrTimeout
+// when its deadline passed and ErrOverflow in Checked contexts
+func MulContext(ctx context.Context, first, second int) (product int, err error) {
+	if o := currentObserver(); o != nil {
+		defer observe(o, "mul", time.Now(), &err, first, second)
+	}
+
+	return mul(ctx, first, second, currentTracer())
+}
+
+func mul(ctx context.Context, first, second int, t Tracer) (int, error) {
+	if err := contextError(ctx); err != nil {
+		return 0, err
+	}
+
+	// loop on the multiplier of smaller magnitude
+	if magnitude(second) > magnitude(first) {
+		t.trace("mul", 0, "%d * %d = %d * %d", first, second, second, first)
+
+		first, second = second, first
+	}
+
+	// the magnitude of the multiplier is the count of the loop, a negative
+	// multiplier adds the opposite of the multiplicand
+	addend := first
+
+	if second < 0 {
+		t.trace("mul", 0, "%d * %d = %d * %d", first, second, -first, -second)
+
+		addend = -first
+	}
+
+	if IsChecked(ctx) && mulOverflows(first, second) {
+		return 0, ErrOverflow
+	}
+
+	t.trace("mul", 0, "%d * %d", addend, magnitude(second))
+
+	var mul int
+	for i := uint(0); i < magnitude(second); i++ {
+		if i%checkInterval == 0 {
+			if err := contextError(ctx); err != nil {
+				return 0, err
+			}
+		}
+
+		mul = sum(mul, addend, nil)
+
+		t.trace("mul", 1, "partial product %d: %d", i+1, mul)
+	}
+
+	return mul, nil
+}
+
+// mulOverflows compares the magnitudes before the loop so checked
+// products fail without looping, the smallest int has no positive
+// counterpart so negative products reach one further
+func mulOverflows(first, second int) bool {
 	if second == 0 {
-		return 0, errors.New("division by zero")
+		return false
 	}
 
-	if second > first {
-		return 0, nil
+	limit := uint(math.MaxInt)
+	if (first < 0) != (second < 0) {
+		limit++
+	}
+
+	return magnitude(first) > limit/magnitude(second)
+}
+
+// Div is first / second truncated toward zero like Go's /, negative
+// operands included, selftest checks it against the native operator
+func Div(first, second int) (quotient int, err error) {
+	if o := currentObserver(); o != nil {
+		defer observe(o, "div", time.Now(), &err, first, second)
+	}
+
+	return div(context.Background(), first, second, currentTracer())
+}
+
+// DivContext is Div returning ctx.Err() once ctx is done, ErrTimeout
+// when its deadline passed and ErrOverflow in Checked contexts
+func DivContext(ctx context.Context, first, second int) (quotient int, err error) {
+	if o := currentObserver(); o != nil {
+		defer observe(o, "div", time.Now(), &err, first, second)
 	}
 
-	var div int
+	return div(ctx, first, second, currentTracer())
+}
+
+func div(ctx context.Context, first, second int, t Tracer) (int, error) {
+	if second == 0 {
+		return 0, ErrDivisionByZero
+	}
+
+	if err := contextError(ctx); err != nil {
+		return 0, err
+	}
+
+	// the only quotient not fitting an int
+	if IsChecked(ctx) && first == math.MinInt && second == -1 {
+		return 0, ErrOverflow
+	}
+
+	// divide the magnitudes, the quotient is truncated toward zero like Go's /
+	negative := false
+
+	if second < 0 {
+		t.trace("div", 0, "%d / %d = -(%d / %d)", first, second, first, magnitude(second))
+
+		negative = !negative
+	}
+
+	if first < 0 {
+		t.trace("div", 0, "%d / %d = -(%d / %d)", first, magnitude(second), magnitude(first), magnitude(second))
+
+		negative = !negative
+	}
+
+	// unsigned so the magnitude of the smallest int fits
+	dividend, divisor := magnitude(first), magnitude(second)
+
+	t.trace("div", 0, "%d / %d", dividend, divisor)
+
+	var div uint
+
+	rest := dividend
+
+	// dividing by 1 would subtract as many times as the dividend
+	if divisor == 1 {
+		div, rest = dividend, 0
+	}
+
+	for rest >= divisor {
+		if div%checkInterval == 0 {
+			if err := contextError(ctx); err != nil {
+				return 0, err
+			}
+		}
 
-	rest := first
-	for rest > 0 {
 		div++
 
-		rest -= second
+		t.trace("div", 1, "step %d: %d - %d = %d", div, rest, divisor, rest-divisor)
+
+		rest -= divisor
 	}
 
-	return div, nil
+	t.trace("div", 1, "quotient %d, remainder %d", div, rest)
+
+	if negative {
+		return -int(div), nil
+	}
+
+	return int(div), nil
+}
+
+// magnitude is |n|, the smallest int has no positive int counterpart
+func magnitude(n int) uint {
+	if n < 0 {
+		return uint(-n)
+	}
+
+	return uint(n)
+}
+
+// bits is the number of significant bits of n
+func bits(n uint) int {
+	length := 0
+
+	for ; n != 0; n >>= 1 {
+		length++
+	}
+
+	return length
 }
 
 func Pow(base, exponent int) int {
-	if exponent < 0 {
-		return 0
+	if o := currentObserver(); o != nil {
+		defer observe(o, "pow", time.Now(), nil, base, exponent)
+	}
+
+	pow, _ := pow(context.Background(), base, exponent, currentTracer())
+
+	return pow
+}
+
+// PowContext is Pow returning ctx.Err() once ctx is done, ErrTimeout
+// when its deadline passed and ErrOverflow in Checked contexts
+func PowContext(ctx context.Context, base, exponent int) (power int, err error) {
+	if o := currentObserver(); o != nil {
+		defer observe(o, "pow", time.Now(), &err, base, exponent)
 	}
 
-	if exponent == 0 {
-		return 1
+	return pow(ctx, base, exponent, currentTracer())
+}
+
+// pow multiplies once per unit of the exponent, in a loop so large
+// exponents take long but never exhaust the stack
+func pow(ctx context.Context, base, exponent int, t Tracer) (int, error) {
+	if err := contextError(ctx); err != nil {
+		return 0, err
+	}
+
+	t.trace("pow", 0, "%d^%d", base, exponent)
+
+	switch {
+	case exponent < 0:
+		return 0, nil
+	case exponent == 0:
+		return 1, nil
+	// the powers of 0, 1 and -1 need no multiplication
+	case base == 0 || base == 1:
+		return base, nil
+	case base == -1:
+		if exponent&1 == 0 {
+			return 1, nil
+		}
+
+		return -1, nil
 	}
 
-	if exponent == 1 {
-		return base
+	power := base
+
+	for i := 2; i <= exponent; i++ {
+		if i%checkInterval == 0 {
+			if err := contextError(ctx); err != nil {
+				return 0, err
+			}
+		}
+
+		var err error
+
+		if power, err = mul(ctx, power, base, nil); err != nil {
+			return 0, err
+		}
+
+		t.trace("pow", 1, "power %d: %d", i, power)
+
+		// wrapped around to 0, the following powers are 0 as well
+		if power == 0 {
+			break
+		}
 	}
 
-	return Mul(base, Pow(base, exponent-1))
+	return power, nil
 }
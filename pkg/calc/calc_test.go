@@ -1,7 +1,11 @@
 package calc
 
 import (
+	"context"
+	"errors"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
 )
@@ -185,6 +189,69 @@ func TestDiv(t *testing.T) {
 	}
 }
 
+// TestSigned checks that Mul and Div agree with Go's * and / on negative
+// operands and inexact quotients, the parity selftest checks at runtime
+func TestSigned(t *testing.T) {
+	type testCase struct {
+		first  int
+		second int
+		mul    int
+		div    int
+	}
+
+	cases := []testCase{
+		{
+			first:  7,
+			second: 2,
+			mul:    14,
+			div:    3,
+		},
+		{
+			first:  5,
+			second: -2,
+			mul:    -10,
+			div:    -2,
+		},
+		{
+			first:  -6,
+			second: 3,
+			mul:    -18,
+			div:    -2,
+		},
+		{
+			first:  -7,
+			second: -2,
+			mul:    14,
+			div:    3,
+		},
+		{
+			first:  math.MinInt,
+			second: -1,
+			mul:    math.MinInt,
+			div:    math.MinInt,
+		},
+		{
+			first:  -1,
+			second: math.MinInt,
+			mul:    math.MinInt,
+			div:    0,
+		},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, Mul(tc.first, tc.second), tc.mul)
+		assert.Equal(t, tc.first*tc.second, tc.mul)
+
+		div, err := Div(tc.first, tc.second)
+		if err != nil {
+			t.Error(err)
+		}
+
+		assert.Equal(t, div, tc.div)
+		assert.Equal(t, tc.first/tc.second, tc.div)
+	}
+}
+
 func TestPow(t *testing.T) {
 	type testCase struct {
 		base     int
@@ -228,6 +295,27 @@ func TestPow(t *testing.T) {
 			exponent: -1,
 			power:    0,
 		},
+		{
+			base:     1,
+			exponent: 200000000,
+			power:    1,
+		},
+		{
+			base:     -1,
+			exponent: 200000001,
+			power:    -1,
+		},
+		{
+			base:     0,
+			exponent: 200000000,
+			power:    0,
+		},
+		{
+			// wraps around to 0 after 64 bits
+			base:     2,
+			exponent: 200000000,
+			power:    0,
+		},
 	}
 
 	for _, tc := range cases {
@@ -236,3 +324,36 @@ func TestPow(t *testing.T) {
 		assert.Equal(t, power, tc.power)
 	}
 }
+
+func TestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mul, err := MulContext(ctx, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, mul, 15)
+
+	cancel()
+
+	if _, err := MulContext(ctx, 3, 1<<20); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled got %v", err)
+	}
+
+	if _, err := DivContext(ctx, 1<<20, 3); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled got %v", err)
+	}
+
+	if _, err := PowContext(ctx, 3, 20); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled got %v", err)
+	}
+
+	// one multiplication per unit of the exponent, stopped by the deadline
+	deadline, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := PowContext(deadline, 3, 200000000); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/format"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is read from the working directory
+const ProjectFile = ".calc.yaml"
+
+// EnvPrefix of the environment overrides, CALC_OUTPUT sets output
+const EnvPrefix = "CALC_"
+
+// Sources of a setting from the lowest to the highest precedence, the
+// command line flags come last and win over all of them
+const (
+	SourceDefault = "default"
+	SourceUser    = "user"
+	SourceProject = "project"
+	SourceEnv     = "env"
+)
+
+// Key is a setting, it provides the default of the calc flag with the
+// same name
+type Key struct {
+	Name     string
+	Validate func(value string) error
+}
+
+var Keys = []Key{
+	{
+		Name: "output",
+		Validate: func(value string) error {
+			_, err := render.ParseFormat(value)
+
+			return err
+		},
+	},
+	{
+		Name: "format",
+		Validate: func(value string) error {
+			_, err := format.ParseStyle(value)
+
+			return err
+		},
+	},
+	{
+		Name: "base",
+		Validate: func(value string) error {
+			base, err := strconv.Atoi(value)
+			if err != nil || base < 2 || base > 36 {
+				return fmt.Errorf("invalid base %q, use 2 to 36", value)
+			}
+
+			return nil
+		},
+	},
+	{
+		Name: "precision",
+		Validate: func(value string) error {
+			if value != render.Wrap && value != render.Checked {
+				return fmt.Errorf("unknown precision %q, use %s or %s", value, render.Wrap, render.Checked)
+			}
+
+			return nil
+		},
+	},
+	{
+		Name: "locale",
+		Validate: func(value string) error {
+			_, err := format.LookupLocale(value)
+
+			return err
+		},
+	},
+	{
+		Name: "rounding",
+		Validate: func(value string) error {
+			_, err := calc.ParseRounding(value)
+
+			return err
+		},
+	},
+}
+
+// Setting is the value of a key and where it comes from
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Lookup finds a key by name
+func Lookup(name string) (Key, error) {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+
+	return Key{}, fmt.Errorf("unknown key %q, use %s", name, strings.Join(Names(), ", "))
+}
+
+// Env is the environment variable of a key, CALC_ROUNDING for rounding
+func Env(name string) string {
+	return EnvPrefix + strings.ToUpper(name)
+}
+
+// UserFile is config.yaml in the calc directory of $XDG_CONFIG_HOME,
+// ~/.config when unset
+func UserFile() string {
+	config := os.Getenv("XDG_CONFIG_HOME")
+
+	if config == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+
+		config = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(config, "calc", "config.yaml")
+}
+
+// Load merges the user file, the project file and the environment, the
+// keys set nowhere are left out
+func Load(userFile, projectFile string) (map[string]Setting, error) {
+	settings, errs := Inspect(userFile, projectFile)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return settings, nil
+}
+
+// Inspect is Load going on past the broken sources: a file that fails to
+// parse or validate and an invalid variable are left out and reported in
+// the errors, the other sources still apply
+func Inspect(userFile, projectFile string) (map[string]Setting, []error) {
+	settings := map[string]Setting{}
+	errs := []error{}
+
+	files := []struct {
+		path   string
+		source string
+	}{
+		{path: userFile, source: SourceUser},
+		{path: projectFile, source: SourceProject},
+	}
+
+	for _, file := range files {
+		values, err := Read(file.path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for name, value := range values {
+			settings[name] = Setting{Key: name, Value: value, Source: file.source}
+		}
+	}
+
+	for _, key := range Keys {
+		value, ok := os.LookupEnv(Env(key.Name))
+		if !ok {
+			continue
+		}
+
+		if err := key.Validate(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", Env(key.Name), err))
+			continue
+		}
+
+		settings[key.Name] = Setting{Key: key.Name, Value: value, Source: SourceEnv}
+	}
+
+	return settings, errs
+}
+
+// Read parses and validates a config file, a missing file has no
+// settings
+func Read(path string) (map[string]string, error) {
+	values, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range values {
+		key, err := Lookup(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if err := key.Validate(value); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+	}
+
+	return values, nil
+}
+
+func read(path string) (map[string]string, error) {
+	values := map[string]string{}
+
+	if path == "" {
+		return values, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// Set validates value and writes it to the config file at path, the
+// other keys of the file are kept even when invalid so a broken file
+// can be fixed with Set
+func Set(path, name, value string) error {
+	key, err := Lookup(name)
+	if err != nil {
+		return err
+	}
+
+	if err := key.Validate(value); err != nil {
+		return err
+	}
+
+	values, err := read(path)
+	if err != nil {
+		return err
+	}
+
+	values[name] = value
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Names of the keys in the order of Keys
+func Names() []string {
+	names := make([]string, 0, len(Keys))
+	for _, key := range Keys {
+		names = append(names, key.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	user := filepath.Join(dir, "config.yaml")
+	project := filepath.Join(dir, ProjectFile)
+
+	write(t, user, "output: json\nlocale: it-IT\nbase: 16\n")
+	write(t, project, "output: yaml\n")
+
+	t.Setenv(Env("base"), "2")
+
+	settings, err := Load(user, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		key    string
+		value  string
+		source string
+	}
+
+	cases := []testCase{
+		{key: "output", value: "yaml", source: SourceProject},
+		{key: "locale", value: "it-IT", source: SourceUser},
+		{key: "base", value: "2", source: SourceEnv},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, settings[tc.key].Value, tc.value)
+		assert.Equal(t, settings[tc.key].Source, tc.source)
+	}
+
+	if _, ok := settings["rounding"]; ok {
+		t.Error("expected rounding to be unset")
+	}
+
+	settings, err = Load(filepath.Join(dir, "missing.yaml"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(settings), 1)
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	type testCase struct {
+		content string
+		env     string
+	}
+
+	cases := []testCase{
+		{content: "colour: red\n"},
+		{content: "precision: exact\n"},
+		{content: "output: [json\n"},
+		{env: "roman"},
+	}
+
+	for i, tc := range cases {
+		path := filepath.Join(dir, "config.yaml")
+		write(t, path, tc.content)
+
+		t.Setenv(Env("output"), tc.env)
+		if tc.env == "" {
+			os.Unsetenv(Env("output"))
+		}
+
+		if _, err := Load(path, ""); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+
+	user := filepath.Join(dir, "config.yaml")
+	project := filepath.Join(dir, ProjectFile)
+
+	write(t, user, "locale: it-IT\n")
+	write(t, project, "output: [json\n")
+
+	t.Setenv(Env("base"), "99")
+	t.Setenv(Env("precision"), "checked")
+
+	settings, errs := Inspect(user, project)
+
+	assert.Equal(t, len(errs), 2)
+	assert.StringContains(t, errs[0].Error(), project)
+	assert.StringContains(t, errs[1].Error(), Env("base"))
+
+	assert.Equal(t, len(settings), 2)
+	assert.Equal(t, settings["locale"].Source, SourceUser)
+	assert.Equal(t, settings["precision"].Source, SourceEnv)
+}
+
+func TestSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calc", "config.yaml")
+
+	if err := Set(path, "locale", "de-DE"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Set(path, "rounding", "half-up"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Set(path, "rounding", "ceiling"); err == nil {
+		t.Error("expected an error for an invalid value")
+	}
+
+	if err := Set(path, "colour", "red"); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+
+	values, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(values), 2)
+	assert.Equal(t, values["locale"], "de-DE")
+	assert.Equal(t, values["rounding"], "half-up")
+}
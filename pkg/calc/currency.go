@@ -0,0 +1,68 @@
+package calc
+
+// ISO 4217 minor unit exponents, the number of decimal digits of the
+// minor unit of each currency
+var currencyExponents = map[string]int{
+	"AED": 2,
+	"ARS": 2,
+	"AUD": 2,
+	"BGN": 2,
+	"BHD": 3,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CLF": 4,
+	"CLP": 0,
+	"CNY": 2,
+	"COP": 2,
+	"CZK": 2,
+	"DKK": 2,
+	"EGP": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"HUF": 2,
+	"IDR": 2,
+	"ILS": 2,
+	"INR": 2,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"MXN": 2,
+	"MYR": 2,
+	"NOK": 2,
+	"NZD": 2,
+	"OMR": 3,
+	"PHP": 2,
+	"PLN": 2,
+	"PYG": 0,
+	"RON": 2,
+	"RSD": 2,
+	"RUB": 2,
+	"SAR": 2,
+	"SEK": 2,
+	"SGD": 2,
+	"THB": 2,
+	"TND": 3,
+	"TRY": 2,
+	"TWD": 2,
+	"UAH": 2,
+	"UGX": 0,
+	"USD": 2,
+	"UYU": 2,
+	"VND": 0,
+	"XAF": 0,
+	"XOF": 0,
+	"ZAR": 2,
+}
+
+// CurrencyExponent returns the minor unit exponent of an ISO 4217 code
+func CurrencyExponent(currency string) (int, bool) {
+	exponent, ok := currencyExponents[currency]
+
+	return exponent, ok
+}
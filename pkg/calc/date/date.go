@@ -0,0 +1,205 @@
+package date
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/units"
+)
+
+const day = "2006-01-02"
+
+// Parse parses an RFC3339 timestamp, a plain date is taken at midnight UTC
+func Parse(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(day, s)
+}
+
+// ParseDuration accepts Go durations (1h30m) as well as
+// time quantities (14d, 1.5h, 250ms), durations beyond about 292 years
+// do not fit a time.Duration and fail with calc.ErrOverflow
+func ParseDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	q, err := units.Parse(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	if q.Dimension() != units.Time {
+		return 0, &units.DimensionError{Op: "use", Left: q.Dimension(), Right: units.Time}
+	}
+
+	// 2^63 is the first float64 beyond the largest int64
+	nanoseconds := q.Base() * float64(time.Second)
+	if nanoseconds >= math.MaxInt64 || nanoseconds < math.MinInt64 {
+		return 0, fmt.Errorf("duration %q: %w", s, calc.ErrOverflow)
+	}
+
+	return time.Duration(nanoseconds), nil
+}
+
+// Diff returns the distance between two instants expressed in unit, it
+// is computed from the Unix times since a time.Duration stops at about
+// 292 years
+func Diff(from, to time.Time, unit string) (units.Quantity, error) {
+	elapsed := float64(to.Unix()-from.Unix()) + float64(to.Nanosecond()-from.Nanosecond())/float64(time.Second)
+
+	seconds := units.Quantity{Value: elapsed, Unit: units.Unit{Symbol: "s", Factor: 1, Dimension: units.Time}}
+
+	return units.Convert(seconds, unit)
+}
+
+// Holidays is a set of non-working days
+type Holidays map[string]struct{}
+
+// ParseHolidays reads one YYYY-MM-DD date per line, anything after the
+// date is a description, blank lines and lines starting with # are ignored
+func ParseHolidays(r io.Reader) (Holidays, error) {
+	holidays := Holidays{}
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+
+		holiday, err := time.Parse(day, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("holidays line %d: %w", line, err)
+		}
+
+		holidays[holiday.Format(day)] = struct{}{}
+	}
+
+	return holidays, scanner.Err()
+}
+
+func LoadHolidays(path string) (Holidays, error) {
+	if path == "" {
+		return Holidays{}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseHolidays(f)
+}
+
+func (h Holidays) Contains(t time.Time) bool {
+	_, ok := h[t.Format(day)]
+
+	return ok
+}
+
+func IsBusinessDay(t time.Time, holidays Holidays) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+
+	return !holidays.Contains(t)
+}
+
+// the day loops check for cancellation every checkInterval days
+const checkInterval = 1 << 10
+
+// the dates are parsed and printed with four digit years
+const (
+	firstYear = 1
+	lastYear  = 9999
+	// the days from 0001-01-01 to 9999-12-31, more business days than
+	// these leave the range whatever the start
+	maxDays = 3652058
+)
+
+// AddBusinessDays moves t forward (or backward when days is negative)
+// by the given number of business days, keeping the time of day. It
+// returns calc.ErrOverflow when the result leaves the years 1 to 9999
+// and ctx.Err() once ctx is done
+func AddBusinessDays(ctx context.Context, t time.Time, days int, holidays Holidays) (time.Time, error) {
+	if days > maxDays || days < -maxDays {
+		return time.Time{}, fmt.Errorf("%d business days: %w", days, calc.ErrOverflow)
+	}
+
+	requested, step := days, 1
+
+	if days < 0 {
+		step = -1
+		days = -days
+	}
+
+	for i := 0; days > 0; i++ {
+		if i%checkInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return time.Time{}, err
+			}
+		}
+
+		t = t.AddDate(0, 0, step)
+
+		if t.Year() < firstYear || t.Year() > lastYear {
+			return time.Time{}, fmt.Errorf("%d business days: %w", requested, calc.ErrOverflow)
+		}
+
+		if IsBusinessDay(t, holidays) {
+			days--
+		}
+	}
+
+	return t, nil
+}
+
+// BusinessDays counts the business days in [from, to), the count is
+// negative when to is before from. It returns ctx.Err() once ctx is done
+func BusinessDays(ctx context.Context, from, to time.Time, holidays Holidays) (int, error) {
+	if to.Before(from) {
+		count, err := BusinessDays(ctx, to, from, holidays)
+
+		return -count, err
+	}
+
+	from = midnight(from)
+	to = midnight(to)
+
+	var count int
+
+	for i, current := 0, from; current.Before(to); i, current = i+1, current.AddDate(0, 0, 1) {
+		if i%checkInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+
+		if IsBusinessDay(current, holidays) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func midnight(t time.Time) time.Time {
+	year, month, dd := t.Date()
+
+	return time.Date(year, month, dd, 0, 0, 0, 0, t.Location())
+}
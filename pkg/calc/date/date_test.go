@@ -0,0 +1,240 @@
+package date
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+func TestParseDuration(t *testing.T) {
+	type testCase struct {
+		input    string
+		duration time.Duration
+		ok       bool
+	}
+
+	cases := []testCase{
+		{
+			input:    "1h30m",
+			duration: 90 * time.Minute,
+			ok:       true,
+		},
+		{
+			input:    "14d",
+			duration: 14 * 24 * time.Hour,
+			ok:       true,
+		},
+		{
+			input:    "1.5h",
+			duration: 90 * time.Minute,
+			ok:       true,
+		},
+		{
+			input: "3GiB",
+			ok:    false,
+		},
+		{
+			input: "soon",
+			ok:    false,
+		},
+		{
+			input: "100000000d",
+			ok:    false,
+		},
+		{
+			input: "-110000d",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		duration, err := ParseDuration(tc.input)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error for %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, duration, tc.duration)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from, _ := Parse("2023-03-01T09:00:00Z")
+	to, _ := Parse("2023-03-02T21:00:00+00:00")
+
+	diff, err := Diff(from, to, "h")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, diff.String(), "36h")
+
+	// beyond the range of a time.Duration
+	first, _ := Parse("0001-01-01")
+	last, _ := Parse("9999-12-31")
+
+	diff, err = Diff(first, last, "d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, diff.String(), "3652058d")
+
+	if _, err := ParseDuration("100000000d"); !errors.Is(err, calc.ErrOverflow) {
+		t.Errorf("expected an overflow, got %v", err)
+	}
+}
+
+func TestParseHolidays(t *testing.T) {
+	holidays, err := ParseHolidays(strings.NewReader("# italian holidays\n2023-04-25 Liberazione\n\n2023-05-01\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(holidays), 2)
+
+	_, err = ParseHolidays(strings.NewReader("25/04/2023\n"))
+	if err == nil {
+		t.Error("expected holidays parse error")
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	type testCase struct {
+		start  string
+		days   int
+		result string
+	}
+
+	holidays := Holidays{"2023-04-25": {}}
+
+	cases := []testCase{
+		{
+			// friday to monday
+			start:  "2023-04-21T09:00:00Z",
+			days:   1,
+			result: "2023-04-24T09:00:00Z",
+		},
+		{
+			// skips the weekend and the holiday
+			start:  "2023-04-21T09:00:00Z",
+			days:   2,
+			result: "2023-04-26T09:00:00Z",
+		},
+		{
+			start:  "2023-04-03T00:00:00Z",
+			days:   14,
+			result: "2023-04-21T00:00:00Z",
+		},
+		{
+			start:  "2023-04-24T09:00:00Z",
+			days:   -1,
+			result: "2023-04-21T09:00:00Z",
+		},
+	}
+
+	for _, tc := range cases {
+		start, _ := Parse(tc.start)
+
+		result, err := AddBusinessDays(context.Background(), start, tc.days, holidays)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		assert.Equal(t, result.Format(time.RFC3339), tc.result)
+	}
+}
+
+func TestAddBusinessDaysLimits(t *testing.T) {
+	start, _ := Parse("2024-01-01")
+	end, _ := Parse("9999-12-30")
+
+	type testCase struct {
+		start time.Time
+		days  int
+	}
+
+	cases := []testCase{
+		{start: start, days: math.MinInt},
+		{start: start, days: math.MaxInt},
+		{start: start, days: -600000},
+		{start: end, days: 2},
+	}
+
+	for _, tc := range cases {
+		if _, err := AddBusinessDays(context.Background(), tc.start, tc.days, nil); !errors.Is(err, calc.ErrOverflow) {
+			t.Errorf("%d days: expected an overflow, got %v", tc.days, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := AddBusinessDays(ctx, start, 10, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancellation, got %v", err)
+	}
+
+	first, _ := Parse("0001-01-01")
+
+	if _, err := BusinessDays(ctx, first, start, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancellation, got %v", err)
+	}
+}
+
+func TestBusinessDays(t *testing.T) {
+	type testCase struct {
+		from  string
+		to    string
+		count int
+	}
+
+	holidays := Holidays{"2023-04-25": {}}
+
+	cases := []testCase{
+		{
+			from:  "2023-04-17",
+			to:    "2023-04-24",
+			count: 5,
+		},
+		{
+			from:  "2023-04-24",
+			to:    "2023-05-01",
+			count: 4,
+		},
+		{
+			from:  "2023-05-01",
+			to:    "2023-04-24",
+			count: -4,
+		},
+		{
+			from:  "2023-04-22",
+			to:    "2023-04-22",
+			count: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		from, _ := Parse(tc.from)
+		to, _ := Parse(tc.to)
+
+		count, err := BusinessDays(context.Background(), from, to, holidays)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, count, tc.count)
+	}
+}
@@ -0,0 +1,26 @@
+package calc
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+)
+
+var decimalRegexp = regexp.MustCompile(`^[+-]?\d+(?:\.\d+)?$`)
+
+// ParseDecimal parses decimal numbers such as 9.99 or -12 exactly
+func ParseDecimal(s string) (*big.Rat, error) {
+	if !decimalRegexp.MatchString(s) {
+		return nil, &OperandError{Operand: s, Err: errors.New("not a decimal number")}
+	}
+
+	x, _ := new(big.Rat).SetString(s)
+
+	return x, nil
+}
+
+// RoundHalfEven rounds x to the given number of decimal places, ties go
+// to the even neighbour (banker's rounding)
+func RoundHalfEven(x *big.Rat, places int) *big.Rat {
+	return HalfEven.Round(x, places)
+}
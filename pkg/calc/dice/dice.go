@@ -0,0 +1,210 @@
+package dice
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+// dice counts and sides above these are rejected as typos
+const (
+	maxCount = 1000
+	maxSides = 1000000
+)
+
+// ErrCount is a number of draws below 1
+var ErrCount = errors.New("count must be at least 1")
+
+// Dice is a group of identical dice such as 3d6, Negative for -1d4
+type Dice struct {
+	Count    int
+	Sides    int
+	Negative bool
+}
+
+func (d Dice) String() string {
+	return fmt.Sprintf("%dd%d", d.Count, d.Sides)
+}
+
+// Roll is a dice expression such as 3d6+2 or 1d20-1d4+1
+type Roll struct {
+	Dice     []Dice
+	Modifier int
+}
+
+// Parse reads dice notation, a group without count (d20) is one die
+func Parse(s string) (Roll, error) {
+	expression := strings.ReplaceAll(strings.ToLower(s), " ", "")
+
+	if expression == "" {
+		return Roll{}, fmt.Errorf("empty dice expression")
+	}
+
+	roll := Roll{}
+
+	for len(expression) > 0 {
+		negative := false
+
+		switch expression[0] {
+		case '-':
+			negative = true
+			expression = expression[1:]
+		case '+':
+			expression = expression[1:]
+		}
+
+		end := strings.IndexAny(expression, "+-")
+		if end < 0 {
+			end = len(expression)
+		}
+
+		term := expression[:end]
+		expression = expression[end:]
+
+		count, sides, isDice := strings.Cut(term, "d")
+
+		if !isDice {
+			n, err := strconv.Atoi(term)
+			if err != nil {
+				return Roll{}, fmt.Errorf("invalid dice expression %q", s)
+			}
+
+			if negative {
+				n = calc.Sub(0, n)
+			}
+
+			roll.Modifier = calc.Sum(roll.Modifier, n)
+
+			continue
+		}
+
+		dice := Dice{Count: 1, Negative: negative}
+
+		if count != "" {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 1 || n > maxCount {
+				return Roll{}, fmt.Errorf("invalid dice count in %q", term)
+			}
+
+			dice.Count = n
+		}
+
+		n, err := strconv.Atoi(sides)
+		if err != nil || n < 1 || n > maxSides {
+			return Roll{}, fmt.Errorf("invalid dice sides in %q", term)
+		}
+
+		dice.Sides = n
+
+		roll.Dice = append(roll.Dice, dice)
+	}
+
+	return roll, nil
+}
+
+func (r Roll) String() string {
+	var b strings.Builder
+
+	for i, dice := range r.Dice {
+		switch {
+		case dice.Negative:
+			b.WriteString("-")
+		case i > 0:
+			b.WriteString("+")
+		}
+
+		b.WriteString(dice.String())
+	}
+
+	switch {
+	case r.Modifier < 0:
+		fmt.Fprintf(&b, "%d", r.Modifier)
+	case r.Modifier > 0 && b.Len() > 0:
+		fmt.Fprintf(&b, "+%d", r.Modifier)
+	case r.Modifier > 0:
+		fmt.Fprintf(&b, "%d", r.Modifier)
+	}
+
+	return b.String()
+}
+
+// Result keeps every die so the roll can be shown in detail
+type Result struct {
+	Dice     []Dice
+	Faces    [][]int
+	Modifier int
+	Total    int
+}
+
+// Roll throws the dice with the given source, the same seed gives the
+// same faces
+func (r Roll) Roll(random *rand.Rand) Result {
+	result := Result{Dice: r.Dice, Modifier: r.Modifier, Total: r.Modifier}
+
+	for _, dice := range r.Dice {
+		faces := make([]int, dice.Count)
+
+		for i := range faces {
+			faces[i] = calc.Sum(random.Intn(dice.Sides), 1)
+
+			if dice.Negative {
+				result.Total = calc.Sub(result.Total, faces[i])
+			} else {
+				result.Total = calc.Sum(result.Total, faces[i])
+			}
+		}
+
+		result.Faces = append(result.Faces, faces)
+	}
+
+	return result
+}
+
+// Uniform draws an integer in [lower, upper]
+func Uniform(random *rand.Rand, lower, upper int) (int, error) {
+	if lower > upper {
+		return 0, fmt.Errorf("empty range [%d, %d]", lower, upper)
+	}
+
+	span := uint64(upper-lower) + 1
+
+	// the whole int64 range
+	if span == 0 {
+		return int(random.Uint64()), nil
+	}
+
+	if span <= 1<<63-1 {
+		return lower + int(random.Int63n(int64(span))), nil
+	}
+
+	// spans beyond int64: draw until it fits
+	for {
+		if n := random.Uint64(); n < span {
+			return lower + int(n), nil
+		}
+	}
+}
+
+// Draw draws count integers in [lower, upper]
+func Draw(random *rand.Rand, lower, upper, count int) ([]int, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("%w, got %d", ErrCount, count)
+	}
+
+	numbers := make([]int, 0, count)
+
+	for i := 0; i < count; i++ {
+		n, err := Uniform(random, lower, upper)
+		if err != nil {
+			return nil, err
+		}
+
+		numbers = append(numbers, n)
+	}
+
+	return numbers, nil
+}
@@ -0,0 +1,136 @@
+package dice
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestParse(t *testing.T) {
+	type testCase struct {
+		input  string
+		output string
+		ok     bool
+	}
+
+	cases := []testCase{
+		{
+			input:  "3d6+2",
+			output: "3d6+2",
+			ok:     true,
+		},
+		{
+			input:  "d20 - 1",
+			output: "1d20-1",
+			ok:     true,
+		},
+		{
+			input:  "1D8-1d4+3-1",
+			output: "1d8-1d4+2",
+			ok:     true,
+		},
+		{
+			input:  "5",
+			output: "5",
+			ok:     true,
+		},
+		{
+			input: "3d",
+			ok:    false,
+		},
+		{
+			input: "0d6",
+			ok:    false,
+		},
+		{
+			input: "2x6",
+			ok:    false,
+		},
+		{
+			input: "",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		roll, err := Parse(tc.input)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error for %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, roll.String(), tc.output)
+		}
+	}
+}
+
+func TestRoll(t *testing.T) {
+	roll, _ := Parse("3d6+1d4-2")
+
+	for seed := int64(0); seed < 50; seed++ {
+		result := roll.Roll(rand.New(rand.NewSource(seed)))
+
+		total := result.Modifier
+
+		for i, faces := range result.Faces {
+			assert.Equal(t, len(faces), roll.Dice[i].Count)
+
+			for _, face := range faces {
+				if face < 1 || face > roll.Dice[i].Sides {
+					t.Fatalf("face %d out of %s", face, roll.Dice[i])
+				}
+
+				total += face
+			}
+		}
+
+		assert.Equal(t, result.Total, total)
+
+		// the same seed gives the same roll
+		again := roll.Roll(rand.New(rand.NewSource(seed)))
+		assert.Equal(t, again.Total, result.Total)
+	}
+}
+
+func TestUniform(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		n, err := Uniform(random, -3, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n < -3 || n > 3 {
+			t.Fatalf("%d out of [-3, 3]", n)
+		}
+	}
+
+	if _, err := Uniform(random, 3, -3); err == nil {
+		t.Error("expected error for an empty range")
+	}
+}
+
+func TestDraw(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	numbers, err := Draw(random, 1, 6, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(numbers), 3)
+
+	for _, count := range []int{0, -1} {
+		if _, err := Draw(random, 1, 6, count); !errors.Is(err, ErrCount) {
+			t.Errorf("count %d: expected ErrCount, got %v", count, err)
+		}
+	}
+}
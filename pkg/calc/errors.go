@@ -0,0 +1,81 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errors of the operations, match them with errors.Is
+var (
+	ErrDivisionByZero = errors.New("division by zero")
+	ErrOverflow       = errors.New("integer overflow")
+	ErrInvalidOperand = errors.New("invalid operand")
+	ErrTimeout        = errors.New("timed out")
+	// front-ends calling operations by name
+	ErrUnknownOperation = errors.New("unknown operation")
+	ErrArity            = errors.New("wrong number of operands")
+)
+
+// OperandError is an operand that could not be parsed, it matches
+// ErrInvalidOperand
+type OperandError struct {
+	Operand string
+	Err     error
+}
+
+func (e *OperandError) Error() string {
+	return fmt.Sprintf("invalid operand %q: %v", e.Operand, e.Err)
+}
+
+func (e *OperandError) Unwrap() error {
+	return e.Err
+}
+
+func (e *OperandError) Is(target error) bool {
+	return target == ErrInvalidOperand
+}
+
+// timeoutError matches both ErrTimeout and context.DeadlineExceeded
+type timeoutError struct {
+	err error
+}
+
+func (e timeoutError) Error() string {
+	return ErrTimeout.Error()
+}
+
+func (e timeoutError) Unwrap() error {
+	return e.err
+}
+
+func (e timeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// contextError is the error of a done ctx, an expired deadline is
+// reported as ErrTimeout
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return timeoutError{err: err}
+	}
+
+	return err
+}
+
+type checkedKey struct{}
+
+// Checked makes the operations run with the returned context report
+// ErrOverflow instead of wrapping around like Go's integers
+func Checked(ctx context.Context) context.Context {
+	return context.WithValue(ctx, checkedKey{}, true)
+}
+
+// IsChecked reports whether ctx was returned by Checked
+func IsChecked(ctx context.Context) bool {
+	checked, _ := ctx.Value(checkedKey{}).(bool)
+
+	return checked
+}
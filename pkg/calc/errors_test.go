@@ -0,0 +1,157 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestChecked(t *testing.T) {
+	type testCase struct {
+		operation string
+		first     int
+		second    int
+		result    int
+		overflow  bool
+	}
+
+	cases := []testCase{
+		{
+			operation: "sum",
+			first:     math.MaxInt,
+			second:    1,
+			overflow:  true,
+		},
+		{
+			operation: "sum",
+			first:     math.MaxInt,
+			second:    math.MinInt,
+			result:    -1,
+		},
+		{
+			operation: "sub",
+			first:     math.MinInt,
+			second:    1,
+			overflow:  true,
+		},
+		{
+			operation: "sub",
+			first:     0,
+			second:    math.MinInt,
+			overflow:  true,
+		},
+		{
+			operation: "sub",
+			first:     -1,
+			second:    math.MinInt,
+			result:    math.MaxInt,
+		},
+		{
+			operation: "mul",
+			first:     math.MaxInt,
+			second:    2,
+			overflow:  true,
+		},
+		{
+			operation: "mul",
+			first:     -1,
+			second:    math.MinInt,
+			overflow:  true,
+		},
+		{
+			operation: "mul",
+			first:     -3,
+			second:    7,
+			result:    -21,
+		},
+		{
+			operation: "div",
+			first:     math.MinInt,
+			second:    -1,
+			overflow:  true,
+		},
+		{
+			operation: "pow",
+			first:     2,
+			second:    strconv.IntSize - 1,
+			overflow:  true,
+		},
+		{
+			operation: "pow",
+			first:     -2,
+			second:    strconv.IntSize - 1,
+			result:    math.MinInt,
+		},
+	}
+
+	ctx := Checked(context.Background())
+
+	for _, tc := range cases {
+		op, ok := Lookup(tc.operation)
+		if !ok {
+			t.Fatalf("%s is not registered", tc.operation)
+		}
+
+		result, err := op.Eval(ctx, tc.first, tc.second)
+
+		if tc.overflow && !errors.Is(err, ErrOverflow) {
+			t.Errorf("%s %d %d: expected ErrOverflow got %d, %v", tc.operation, tc.first, tc.second, result, err)
+		}
+
+		if !tc.overflow {
+			if err != nil {
+				t.Errorf("%s %d %d: %v", tc.operation, tc.first, tc.second, err)
+				continue
+			}
+
+			assert.Equal(t, result, tc.result)
+		}
+
+		// wrapping around is still the default
+		if _, err := op.Eval(context.Background(), tc.first, tc.second); err != nil {
+			t.Errorf("%s %d %d unchecked: %v", tc.operation, tc.first, tc.second, err)
+		}
+	}
+}
+
+func TestCheckedMul(t *testing.T) {
+	// the loop would take hours, the overflow is known before it
+	ctx, cancel := context.WithTimeout(Checked(context.Background()), time.Second)
+	defer cancel()
+
+	for _, operands := range [][2]int{{1 << 32, 1 << 31}, {math.MinInt, math.MinInt}, {-(1 << 40), 1 << 40}} {
+		if _, err := MulContext(ctx, operands[0], operands[1]); !errors.Is(err, ErrOverflow) {
+			t.Errorf("%d * %d: expected ErrOverflow got %v", operands[0], operands[1], err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := Div(1, 0); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("expected ErrDivisionByZero got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+
+	<-ctx.Done()
+
+	_, err := PowContext(ctx, 3, 20)
+
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected ErrTimeout and context.DeadlineExceeded got %v", err)
+	}
+
+	err = &OperandError{Operand: "x", Err: strconv.ErrSyntax}
+
+	if !errors.Is(err, ErrInvalidOperand) || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected ErrInvalidOperand and strconv.ErrSyntax got %v", err)
+	}
+
+	assert.Equal(t, err.Error(), `invalid operand "x": invalid syntax`)
+}
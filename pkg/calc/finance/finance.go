@@ -0,0 +1,321 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+// decimal places kept by the IRR iterations
+const irrPlaces = 10
+
+const maxIterations = 100
+
+var (
+	ErrNoIRR          = errors.New("cash flows have no internal rate of return")
+	ErrInvalidPeriods = errors.New("periods must be positive")
+	// discounting at -100% divides by zero
+	ErrInvalidRate = errors.New("rates must be above -100%")
+)
+
+// ParseRate parses a rate given as a decimal (0.05), a fraction (1/20)
+// or a percentage (5%)
+func ParseRate(s string) (*big.Rat, error) {
+	percent := strings.HasSuffix(s, "%")
+
+	rate, ok := new(big.Rat).SetString(strings.TrimSuffix(s, "%"))
+	if !ok {
+		return nil, fmt.Errorf("invalid rate %q", s)
+	}
+
+	if percent {
+		rate.Quo(rate, big.NewRat(100, 1))
+	}
+
+	if rate.Cmp(big.NewRat(-1, 1)) <= 0 {
+		return nil, &calc.OperandError{Operand: s, Err: ErrInvalidRate}
+	}
+
+	return rate, nil
+}
+
+// checkRate rejects the rates ParseRate rejects
+func checkRate(rate *big.Rat) error {
+	if rate.Cmp(big.NewRat(-1, 1)) <= 0 {
+		return &calc.OperandError{Operand: rate.RatString(), Err: ErrInvalidRate}
+	}
+
+	return nil
+}
+
+// ParseDecimal parses an exact decimal amount such as 1000.50
+func ParseDecimal(s string) (*big.Rat, error) {
+	amount, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+
+	return amount, nil
+}
+
+// growth returns (1 + rate)^periods, periods may be negative, rate is
+// above -1
+func growth(rate *big.Rat, periods int) *big.Rat {
+	base := new(big.Rat).Add(big.NewRat(1, 1), rate)
+
+	if periods < 0 {
+		base.Inv(base)
+		periods = -periods
+	}
+
+	result := big.NewRat(1, 1)
+
+	// square and multiply
+	for ; periods > 0; periods >>= 1 {
+		if periods&1 == 1 {
+			result.Mul(result, base)
+		}
+
+		base = new(big.Rat).Mul(base, base)
+	}
+
+	return result
+}
+
+// FutureValue of a present amount after periods at rate per period
+func FutureValue(present, rate *big.Rat, periods int) (*big.Rat, error) {
+	if err := checkRate(rate); err != nil {
+		return nil, err
+	}
+
+	return new(big.Rat).Mul(present, growth(rate, periods)), nil
+}
+
+// PresentValue of a future amount discounted at rate per period
+func PresentValue(future, rate *big.Rat, periods int) (*big.Rat, error) {
+	if err := checkRate(rate); err != nil {
+		return nil, err
+	}
+
+	return new(big.Rat).Mul(future, growth(rate, -periods)), nil
+}
+
+// CompoundInterest earned by principal at a yearly rate compounded
+// compounding times a year for years
+func CompoundInterest(principal, rate *big.Rat, years, compounding int) (*big.Rat, error) {
+	if compounding <= 0 {
+		return nil, ErrInvalidPeriods
+	}
+
+	periodRate := new(big.Rat).Quo(rate, big.NewRat(int64(compounding), 1))
+
+	future, err := FutureValue(principal, periodRate, calc.Mul(years, compounding))
+	if err != nil {
+		return nil, err
+	}
+
+	return future.Sub(future, principal), nil
+}
+
+// NPV discounts cash flows at rate, the first cash flow happens at time zero
+func NPV(rate *big.Rat, cashFlows []*big.Rat) (*big.Rat, error) {
+	if err := checkRate(rate); err != nil {
+		return nil, err
+	}
+
+	return npv(rate, cashFlows), nil
+}
+
+// npv is NPV at a rate above -1
+func npv(rate *big.Rat, cashFlows []*big.Rat) *big.Rat {
+	total := new(big.Rat)
+
+	discount := big.NewRat(1, 1)
+	factor := new(big.Rat).Inv(new(big.Rat).Add(big.NewRat(1, 1), rate))
+
+	for _, cashFlow := range cashFlows {
+		total.Add(total, new(big.Rat).Mul(cashFlow, discount))
+		discount.Mul(discount, factor)
+	}
+
+	return total
+}
+
+// derivative of NPV with respect to the rate
+func npvDerivative(rate *big.Rat, cashFlows []*big.Rat) *big.Rat {
+	derivative := new(big.Rat)
+
+	onePlusRate := new(big.Rat).Add(big.NewRat(1, 1), rate)
+
+	for t, cashFlow := range cashFlows {
+		if t == 0 {
+			continue
+		}
+
+		term := new(big.Rat).Mul(cashFlow, big.NewRat(int64(-t), 1))
+		term.Mul(term, growth(rate, -t))
+		term.Quo(term, onePlusRate)
+
+		derivative.Add(derivative, term)
+	}
+
+	return derivative
+}
+
+// IRR finds the rate zeroing the NPV of cash flows with Newton's method,
+// falling back to bisection when Newton does not converge
+func IRR(cashFlows []*big.Rat) (*big.Rat, error) {
+	if !changesSign(cashFlows) {
+		return nil, ErrNoIRR
+	}
+
+	if rate, ok := irrNewton(cashFlows); ok {
+		return rate, nil
+	}
+
+	return irrBisection(cashFlows)
+}
+
+func changesSign(cashFlows []*big.Rat) bool {
+	var positive, negative bool
+
+	for _, cashFlow := range cashFlows {
+		positive = positive || cashFlow.Sign() > 0
+		negative = negative || cashFlow.Sign() < 0
+	}
+
+	return positive && negative
+}
+
+func irrNewton(cashFlows []*big.Rat) (*big.Rat, bool) {
+	rate := big.NewRat(1, 10)
+	tolerance := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(irrPlaces), nil))
+	lowest := big.NewRat(-1, 1)
+
+	for i := 0; i < maxIterations; i++ {
+		derivative := npvDerivative(rate, cashFlows)
+
+		if derivative.Sign() == 0 {
+			return nil, false
+		}
+
+		step := new(big.Rat).Quo(npv(rate, cashFlows), derivative)
+
+		next := calc.RoundHalfEven(new(big.Rat).Sub(rate, step), irrPlaces)
+
+		// the rate must stay above -100%
+		if next.Cmp(lowest) <= 0 {
+			return nil, false
+		}
+
+		if new(big.Rat).Abs(new(big.Rat).Sub(next, rate)).Cmp(tolerance) <= 0 {
+			return next, true
+		}
+
+		rate = next
+	}
+
+	return nil, false
+}
+
+func irrBisection(cashFlows []*big.Rat) (*big.Rat, error) {
+	low := big.NewRat(-9999, 10000)
+	high := big.NewRat(100, 1)
+	tolerance := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(irrPlaces), nil))
+
+	lowSign := npv(low, cashFlows).Sign()
+
+	if lowSign*npv(high, cashFlows).Sign() > 0 {
+		return nil, ErrNoIRR
+	}
+
+	for new(big.Rat).Sub(high, low).Cmp(tolerance) > 0 {
+		middle := calc.RoundHalfEven(new(big.Rat).Quo(new(big.Rat).Add(low, high), big.NewRat(2, 1)), irrPlaces+2)
+
+		sign := npv(middle, cashFlows).Sign()
+
+		if sign == 0 {
+			return calc.RoundHalfEven(middle, irrPlaces), nil
+		}
+
+		if sign == lowSign {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	return calc.RoundHalfEven(low, irrPlaces), nil
+}
+
+// Installment is a row of an amortization schedule
+type Installment struct {
+	Period    int
+	Payment   *big.Rat
+	Interest  *big.Rat
+	Principal *big.Rat
+	Balance   *big.Rat
+}
+
+// Payment is the constant installment repaying principal in periods at rate
+// per period, rounded half to even to places decimals
+func Payment(principal, rate *big.Rat, periods, places int) (*big.Rat, error) {
+	if periods <= 0 {
+		return nil, ErrInvalidPeriods
+	}
+
+	if err := checkRate(rate); err != nil {
+		return nil, err
+	}
+
+	if rate.Sign() == 0 {
+		return calc.RoundHalfEven(new(big.Rat).Quo(principal, big.NewRat(int64(periods), 1)), places), nil
+	}
+
+	// P * r / (1 - (1 + r)^-n)
+	denominator := new(big.Rat).Sub(big.NewRat(1, 1), growth(rate, -periods))
+
+	payment := new(big.Rat).Mul(principal, rate)
+	payment.Quo(payment, denominator)
+
+	return calc.RoundHalfEven(payment, places), nil
+}
+
+// Amortize builds the schedule of a fixed-rate loan, every amount is
+// rounded to places decimals and the last installment settles the
+// rounding difference so that the balance ends at zero
+func Amortize(principal, rate *big.Rat, periods, places int) ([]Installment, error) {
+	payment, err := Payment(principal, rate, periods, places)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := make([]Installment, 0, periods)
+
+	balance := new(big.Rat).Set(principal)
+
+	for period := 1; period <= periods; period++ {
+		interest := calc.RoundHalfEven(new(big.Rat).Mul(balance, rate), places)
+
+		installment := new(big.Rat).Set(payment)
+		if period == periods {
+			installment.Add(balance, interest)
+		}
+
+		repaid := new(big.Rat).Sub(installment, interest)
+		balance = new(big.Rat).Sub(balance, repaid)
+
+		schedule = append(schedule, Installment{
+			Period:    period,
+			Payment:   installment,
+			Interest:  interest,
+			Principal: repaid,
+			Balance:   balance,
+		})
+	}
+
+	return schedule, nil
+}
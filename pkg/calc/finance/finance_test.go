@@ -0,0 +1,223 @@
+package finance
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+func rats(values ...string) []*big.Rat {
+	result := make([]*big.Rat, 0, len(values))
+
+	for _, value := range values {
+		r, _ := new(big.Rat).SetString(value)
+		result = append(result, r)
+	}
+
+	return result
+}
+
+func TestParseRate(t *testing.T) {
+	type testCase struct {
+		input string
+		rate  string
+		ok    bool
+	}
+
+	cases := []testCase{
+		{
+			input: "5%",
+			rate:  "1/20",
+			ok:    true,
+		},
+		{
+			input: "0.05",
+			rate:  "1/20",
+			ok:    true,
+		},
+		{
+			input: "1/12",
+			rate:  "1/12",
+			ok:    true,
+		},
+		{
+			input: "five",
+			ok:    false,
+		},
+		{
+			input: "-100%",
+			ok:    false,
+		},
+		{
+			input: "-2",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		rate, err := ParseRate(tc.input)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error for %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, rate.String(), tc.rate)
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	values := rats("1000", "0.05")
+	amount, rate := values[0], values[1]
+
+	future, err := FutureValue(amount, rate, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, future.FloatString(2), "1102.50")
+
+	present, err := PresentValue(big.NewRat(110250, 100), rate, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, present.FloatString(2), "1000.00")
+
+	interest, err := CompoundInterest(amount, rate, 1, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, interest.FloatString(2), "51.16")
+
+	if _, err := CompoundInterest(amount, rate, 1, 0); !errors.Is(err, ErrInvalidPeriods) {
+		t.Errorf("expected invalid periods, got %v", err)
+	}
+}
+
+func TestNPV(t *testing.T) {
+	npv, err := NPV(big.NewRat(1, 10), rats("-1000", "500", "500", "500"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, npv.FloatString(2), "243.43")
+}
+
+func TestInvalidRate(t *testing.T) {
+	amount, minus100 := big.NewRat(100, 1), big.NewRat(-1, 1)
+
+	_, err := NPV(minus100, rats("100", "200"))
+	assert.Equal(t, errors.Is(err, calc.ErrInvalidOperand), true)
+
+	_, err = PresentValue(amount, minus100, 3)
+	assert.Equal(t, errors.Is(err, calc.ErrInvalidOperand), true)
+
+	_, err = FutureValue(amount, minus100, -3)
+	assert.Equal(t, errors.Is(err, calc.ErrInvalidOperand), true)
+
+	_, err = Payment(amount, minus100, 3, 2)
+	assert.Equal(t, errors.Is(err, calc.ErrInvalidOperand), true)
+
+	_, err = ParseRate("-100%")
+	assert.Equal(t, errors.Is(err, ErrInvalidRate), true)
+}
+
+func TestIRR(t *testing.T) {
+	type testCase struct {
+		cashFlows []*big.Rat
+		irr       string
+		ok        bool
+	}
+
+	cases := []testCase{
+		{
+			cashFlows: rats("-1000", "1100"),
+			irr:       "0.100000",
+			ok:        true,
+		},
+		{
+			cashFlows: rats("-1000", "500", "500", "500"),
+			irr:       "0.233752",
+			ok:        true,
+		},
+		{
+			cashFlows: rats("-100", "0", "0", "0", "0", "1000000"),
+			irr:       "5.309573",
+			ok:        true,
+		},
+		{
+			cashFlows: rats("100", "200"),
+			ok:        false,
+		},
+	}
+
+	for _, tc := range cases {
+		irr, err := IRR(tc.cashFlows)
+
+		if !tc.ok && err == nil {
+			t.Error("expected irr error")
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, irr.FloatString(6), tc.irr)
+		}
+	}
+
+	// the bisection fallback agrees with newton
+	irr, err := irrBisection(rats("-1000", "500", "500", "500"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, irr.FloatString(6), "0.233752")
+}
+
+func TestAmortize(t *testing.T) {
+	schedule, err := Amortize(big.NewRat(1000, 1), big.NewRat(1, 100), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(schedule), 3)
+
+	type row struct {
+		payment, interest, principal, balance string
+	}
+
+	expected := []row{
+		{"340.02", "10.00", "330.02", "669.98"},
+		{"340.02", "6.70", "333.32", "336.66"},
+		{"340.03", "3.37", "336.66", "0.00"},
+	}
+
+	for i, installment := range schedule {
+		assert.Equal(t, installment.Period, i+1)
+		assert.Equal(t, installment.Payment.FloatString(2), expected[i].payment)
+		assert.Equal(t, installment.Interest.FloatString(2), expected[i].interest)
+		assert.Equal(t, installment.Principal.FloatString(2), expected[i].principal)
+		assert.Equal(t, installment.Balance.FloatString(2), expected[i].balance)
+	}
+
+	zero, err := Amortize(big.NewRat(100, 1), new(big.Rat), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, zero[0].Payment.FloatString(2), "33.33")
+	assert.Equal(t, zero[2].Payment.FloatString(2), "33.34")
+	assert.Equal(t, zero[2].Balance.Sign(), 0)
+}
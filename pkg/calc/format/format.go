@@ -0,0 +1,200 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Style is how a result is written
+type Style string
+
+const (
+	Plain       Style = "plain"
+	Grouped     Style = "grouped"
+	Words       Style = "words"
+	Roman       Style = "roman"
+	Scientific  Style = "scientific"
+	Engineering Style = "engineering"
+)
+
+var styles = []Style{Plain, Grouped, Words, Roman, Scientific, Engineering}
+
+func ParseStyle(s string) (Style, error) {
+	for _, style := range styles {
+		if string(style) == strings.ToLower(s) {
+			return style, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown format %q", s)
+}
+
+// Locale carries the separators of a language and region
+type Locale struct {
+	Tag     string
+	Group   string
+	Decimal string
+}
+
+const (
+	noBreakSpace       = "\u00a0"
+	narrowNoBreakSpace = "\u202f"
+)
+
+var locales = []Locale{
+	{Tag: "en-US", Group: ",", Decimal: "."},
+	{Tag: "en-GB", Group: ",", Decimal: "."},
+	{Tag: "it-IT", Group: ".", Decimal: ","},
+	{Tag: "de-DE", Group: ".", Decimal: ","},
+	{Tag: "es-ES", Group: ".", Decimal: ","},
+	{Tag: "fr-FR", Group: narrowNoBreakSpace, Decimal: ","},
+}
+
+// LookupLocale accepts it-IT as well as it_IT and it-it
+func LookupLocale(tag string) (Locale, error) {
+	normalized := strings.ReplaceAll(tag, "_", "-")
+
+	for _, locale := range locales {
+		if strings.EqualFold(locale.Tag, normalized) {
+			return locale, nil
+		}
+	}
+
+	return Locale{}, fmt.Errorf("unknown locale %q", tag)
+}
+
+// Language is the language subtag, it for it-IT
+func (l Locale) Language() string {
+	language, _, _ := strings.Cut(l.Tag, "-")
+
+	return language
+}
+
+func Format(n int, style Style, locale Locale) (string, error) {
+	switch style {
+	case Plain:
+		return strconv.Itoa(n), nil
+	case Grouped:
+		return Group(n, locale), nil
+	case Words:
+		return Spell(n, locale.Language())
+	case Roman:
+		return ToRoman(n)
+	case Scientific:
+		return exponential(n, 1, locale), nil
+	case Engineering:
+		return exponential(n, 3, locale), nil
+	}
+
+	return "", fmt.Errorf("unknown format %q", style)
+}
+
+// FormatBase writes n in base 2 to 36 with lower case digits and no
+// prefix, -ff is -255 in base 16
+func FormatBase(n int, base int) (string, error) {
+	if base < 2 || base > 36 {
+		return "", fmt.Errorf("invalid base %d, use 2 to 36", base)
+	}
+
+	return strconv.FormatInt(int64(n), base), nil
+}
+
+// Group separates the thousands with the locale group separator
+func Group(n int, locale Locale) string {
+	digits := strconv.Itoa(n)
+
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	head := len(digits) % 3
+	if head == 0 {
+		head = 3
+	}
+
+	groups := []string{digits[:head]}
+
+	for i := head; i < len(digits); i += 3 {
+		groups = append(groups, digits[i:i+3])
+	}
+
+	return sign + strings.Join(groups, locale.Group)
+}
+
+// exponential writes n as m×10^e with 1 <= |m| < 10^step and e a multiple
+// of step, 1 for scientific notation and 3 for engineering notation
+func exponential(n int, step int, locale Locale) string {
+	digits := strconv.Itoa(n)
+
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	exponent := len(digits) - 1
+	exponent -= exponent % step
+
+	integer := digits[:len(digits)-exponent]
+	fraction := strings.TrimRight(digits[len(digits)-exponent:], "0")
+
+	if fraction == "" {
+		return fmt.Sprintf("%s%se%d", sign, integer, exponent)
+	}
+
+	return fmt.Sprintf("%s%s%s%se%d", sign, integer, locale.Decimal, fraction, exponent)
+}
+
+// ParseInt reads integers written plainly or grouped with the locale
+// separator such as 1.234.567 in it-IT, groups after the first must
+// have three digits so 1.5 is not mistaken for 15
+func ParseInt(s string, locale Locale) (int, error) {
+	s = strings.TrimSpace(s)
+
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+
+	sign := ""
+	digits := s
+
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+
+	separators := []string{locale.Group}
+
+	// spaces look the same, accept any of them
+	if locale.Group == narrowNoBreakSpace {
+		separators = append(separators, " ", noBreakSpace)
+	}
+
+	for _, separator := range separators {
+		groups := strings.Split(digits, separator)
+
+		if len(groups) < 2 || !validGroups(groups) {
+			continue
+		}
+
+		return strconv.Atoi(sign + strings.Join(groups, ""))
+	}
+
+	return 0, fmt.Errorf("invalid number %q for %s", s, locale.Tag)
+}
+
+func validGroups(groups []string) bool {
+	for i, group := range groups {
+		if group == "" || len(group) > 3 || (i > 0 && len(group) != 3) {
+			return false
+		}
+
+		for _, c := range group {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,233 @@
+package format
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestFormat(t *testing.T) {
+	type testCase struct {
+		n      int
+		style  Style
+		locale string
+		output string
+		ok     bool
+	}
+
+	cases := []testCase{
+		{
+			n:      1234567,
+			style:  Grouped,
+			locale: "it-IT",
+			output: "1.234.567",
+			ok:     true,
+		},
+		{
+			n:      -1234567,
+			style:  Grouped,
+			locale: "en-US",
+			output: "-1,234,567",
+			ok:     true,
+		},
+		{
+			n:      123,
+			style:  Grouped,
+			locale: "en-US",
+			output: "123",
+			ok:     true,
+		},
+		{
+			n:      1234567,
+			style:  Scientific,
+			locale: "en-US",
+			output: "1.234567e6",
+			ok:     true,
+		},
+		{
+			n:      1234567,
+			style:  Engineering,
+			locale: "it-IT",
+			output: "1,234567e6",
+			ok:     true,
+		},
+		{
+			n:      12345,
+			style:  Engineering,
+			locale: "en-US",
+			output: "12.345e3",
+			ok:     true,
+		},
+		{
+			n:      -5000,
+			style:  Scientific,
+			locale: "en-US",
+			output: "-5e3",
+			ok:     true,
+		},
+		{
+			n:      1994,
+			style:  Roman,
+			locale: "en-US",
+			output: "MCMXCIV",
+			ok:     true,
+		},
+		{
+			n:      0,
+			style:  Roman,
+			locale: "en-US",
+			ok:     false,
+		},
+		{
+			n:      42,
+			style:  Words,
+			locale: "de-DE",
+			ok:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		locale, err := LookupLocale(tc.locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := Format(tc.n, tc.style, locale)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error formatting %d as %s", tc.n, tc.style)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, output, tc.output)
+		}
+	}
+}
+
+func TestSpell(t *testing.T) {
+	type testCase struct {
+		n        int
+		language string
+		words    string
+	}
+
+	cases := []testCase{
+		{n: 0, language: "en", words: "zero"},
+		{n: 21, language: "en", words: "twenty-one"},
+		{n: -1234567, language: "en", words: "minus one million two hundred thirty-four thousand five hundred sixty-seven"},
+		{n: 1000000000, language: "en", words: "one billion"},
+		{n: 0, language: "it", words: "zero"},
+		{n: 3, language: "it", words: "tre"},
+		{n: 21, language: "it", words: "ventuno"},
+		{n: 23, language: "it", words: "ventitré"},
+		{n: 38, language: "it", words: "trentotto"},
+		{n: 108, language: "it", words: "centotto"},
+		{n: 180, language: "it", words: "centottanta"},
+		{n: 1003, language: "it", words: "milletré"},
+		{n: 2000, language: "it", words: "duemila"},
+		{n: 1234567, language: "it", words: "un milione duecentotrentaquattromilacinquecentosessantasette"},
+		{n: 3000000, language: "it", words: "tre milioni"},
+		{n: -2000000001, language: "it", words: "meno due miliardi uno"},
+	}
+
+	for _, tc := range cases {
+		words, err := Spell(tc.n, tc.language)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		assert.Equal(t, words, tc.words)
+	}
+
+	if _, err := Spell(math.MinInt64, "en"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	type testCase struct {
+		input  string
+		locale string
+		n      int
+		ok     bool
+	}
+
+	cases := []testCase{
+		{input: "1234567", locale: "it-IT", n: 1234567, ok: true},
+		{input: "1.234.567", locale: "it-IT", n: 1234567, ok: true},
+		{input: "-1,234", locale: "en-US", n: -1234, ok: true},
+		{input: "1 234", locale: "fr-FR", n: 1234, ok: true},
+		{input: "1.5", locale: "it-IT", ok: false},
+		{input: "1.234", locale: "en-US", ok: false},
+		{input: "12,34,567", locale: "en-US", ok: false},
+		{input: "1,234", locale: "it-IT", ok: false},
+	}
+
+	for _, tc := range cases {
+		locale, _ := LookupLocale(tc.locale)
+
+		n, err := ParseInt(tc.input, locale)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error parsing %q in %s", tc.input, tc.locale)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, n, tc.n)
+		}
+	}
+}
+
+func TestFormatBase(t *testing.T) {
+	type testCase struct {
+		n      int
+		base   int
+		output string
+	}
+
+	cases := []testCase{
+		{n: 255, base: 16, output: "ff"},
+		{n: -255, base: 16, output: "-ff"},
+		{n: 5, base: 2, output: "101"},
+		{n: 35, base: 36, output: "z"},
+		{n: 42, base: 10, output: "42"},
+	}
+
+	for _, tc := range cases {
+		output, err := FormatBase(tc.n, tc.base)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		assert.Equal(t, output, tc.output)
+	}
+
+	if _, err := FormatBase(1, 37); err == nil {
+		t.Error("expected error for base 37")
+	}
+}
+
+func TestLookupLocale(t *testing.T) {
+	locale, err := LookupLocale("it_it")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, locale.Tag, "it-IT")
+	assert.Equal(t, locale.Language(), "it")
+
+	if _, err := LookupLocale("xx-XX"); err == nil {
+		t.Error("expected error for an unknown locale")
+	}
+}
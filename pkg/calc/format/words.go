@@ -0,0 +1,200 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spell writes n in words, language is en or it
+func Spell(n int, language string) (string, error) {
+	// the magnitude of the smallest int does not fit an int
+	magnitude := uint64(n)
+	if n < 0 {
+		magnitude = uint64(-n)
+	}
+
+	switch language {
+	case "en":
+		if n < 0 {
+			return "minus " + english(magnitude), nil
+		}
+
+		return english(magnitude), nil
+	case "it":
+		if n < 0 {
+			return "meno " + italian(magnitude), nil
+		}
+
+		return italian(magnitude), nil
+	}
+
+	return "", fmt.Errorf("numbers cannot be spelled in %q yet, use en or it", language)
+}
+
+type scale struct {
+	value    uint64
+	singular string
+	plural   string
+}
+
+var englishOnes = []string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+	"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+}
+
+var englishTens = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+var englishScales = []scale{
+	{value: 1e18, singular: "quintillion"},
+	{value: 1e15, singular: "quadrillion"},
+	{value: 1e12, singular: "trillion"},
+	{value: 1e9, singular: "billion"},
+	{value: 1e6, singular: "million"},
+	{value: 1e3, singular: "thousand"},
+}
+
+func english(n uint64) string {
+	if n == 0 {
+		return englishOnes[0]
+	}
+
+	words := []string{}
+
+	for _, s := range englishScales {
+		if n >= s.value {
+			words = append(words, englishHundreds(n/s.value), s.singular)
+			n %= s.value
+		}
+	}
+
+	if n > 0 {
+		words = append(words, englishHundreds(n))
+	}
+
+	return strings.Join(words, " ")
+}
+
+// englishHundreds spells 1 to 999
+func englishHundreds(n uint64) string {
+	words := []string{}
+
+	if n >= 100 {
+		words = append(words, englishOnes[n/100], "hundred")
+		n %= 100
+	}
+
+	switch {
+	case n == 0:
+	case n < 20:
+		words = append(words, englishOnes[n])
+	case n%10 == 0:
+		words = append(words, englishTens[n/10])
+	default:
+		words = append(words, englishTens[n/10]+"-"+englishOnes[n%10])
+	}
+
+	return strings.Join(words, " ")
+}
+
+var italianOnes = []string{
+	"zero", "uno", "due", "tre", "quattro", "cinque", "sei", "sette", "otto", "nove",
+	"dieci", "undici", "dodici", "tredici", "quattordici", "quindici", "sedici", "diciassette", "diciotto", "diciannove",
+}
+
+var italianTens = []string{"", "", "venti", "trenta", "quaranta", "cinquanta", "sessanta", "settanta", "ottanta", "novanta"}
+
+var italianScales = []scale{
+	{value: 1e18, singular: "un trilione", plural: "trilioni"},
+	{value: 1e15, singular: "un biliardo", plural: "biliardi"},
+	{value: 1e12, singular: "un bilione", plural: "bilioni"},
+	{value: 1e9, singular: "un miliardo", plural: "miliardi"},
+	{value: 1e6, singular: "un milione", plural: "milioni"},
+}
+
+// italian writes the numbers below a million as one word, millions and
+// above as separate words: un milione duecentomila
+func italian(n uint64) string {
+	if n == 0 {
+		return italianOnes[0]
+	}
+
+	words := []string{}
+
+	for _, s := range italianScales {
+		count := n / s.value
+
+		switch {
+		case count == 1:
+			words = append(words, s.singular)
+		case count > 1:
+			words = append(words, italianThousands(count), s.plural)
+		}
+
+		n %= s.value
+	}
+
+	if n > 0 {
+		words = append(words, italianThousands(n))
+	}
+
+	return strings.Join(words, " ")
+}
+
+// italianThousands spells 1 to 999999 as one word
+func italianThousands(n uint64) string {
+	thousands, rest := n/1000, n%1000
+
+	word := ""
+
+	switch {
+	case thousands == 1:
+		word = "mille"
+	case thousands > 1:
+		word = italianHundreds(thousands) + "mila"
+	}
+
+	word += italianHundreds(rest)
+
+	// a final tre is stressed in compounds: ventitré, milletré
+	if strings.HasSuffix(word, "tre") && word != "tre" {
+		word = strings.TrimSuffix(word, "tre") + "tré"
+	}
+
+	return word
+}
+
+// italianHundreds spells 0 to 999, 0 as the empty string
+func italianHundreds(n uint64) string {
+	hundreds, rest := n/100, n%100
+
+	word := ""
+
+	switch {
+	case hundreds == 1:
+		word = "cento"
+	case hundreds > 1:
+		word = italianOnes[hundreds] + "cento"
+	}
+
+	tens := italianTens[rest/10]
+	ones := rest % 10
+
+	switch {
+	case rest == 0:
+		return word
+	case rest < 20:
+		tens = italianOnes[rest]
+	case ones == 1 || ones == 8:
+		// the vowel of the tens is dropped before uno and otto: ventuno
+		tens = tens[:len(tens)-1] + italianOnes[ones]
+	case ones > 0:
+		tens += italianOnes[ones]
+	}
+
+	// and the one of cento before otto and ottanta: centotto
+	if word != "" && strings.HasPrefix(tens, "o") {
+		word = strings.TrimSuffix(word, "o")
+	}
+
+	return word + tens
+}
@@ -0,0 +1,289 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type resolver func(args map[string]interface{}) (interface{}, error)
+
+// node is a value of an object type, a resolver per field, fields
+// without one are null
+type node struct {
+	typename string
+	fields   map[string]resolver
+}
+
+// values is a node of constant fields
+func values(typename string, fields map[string]interface{}) *node {
+	n := &node{typename: typename, fields: map[string]resolver{}}
+
+	for name, value := range fields {
+		n.fields[name] = constant(value)
+	}
+
+	return n
+}
+
+func constant(value interface{}) resolver {
+	return func(map[string]interface{}) (interface{}, error) {
+		return value, nil
+	}
+}
+
+// object is a JSON object keeping the order of the selection
+type object []entry
+
+type entry struct {
+	key   string
+	value interface{}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteByte('{')
+
+	for i, e := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		key, err := json.Marshal(e.key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// executor executes a validated operation, resolver errors are collected
+// and their fields are null
+type executor struct {
+	handler   *Handler
+	ctx       context.Context
+	document  *ast.QueryDocument
+	variables map[string]interface{}
+	errors    gqlerror.List
+}
+
+func (e *executor) query() *node {
+	n := &node{typename: e.handler.schema.Query.Name, fields: map[string]resolver{}}
+
+	for _, op := range e.handler.registry.Operations() {
+		if !exposed(op.Name()) {
+			continue
+		}
+
+		name := op.Name()
+
+		n.fields[name] = func(args map[string]interface{}) (interface{}, error) {
+			return e.handler.eval(e.ctx, name, args["operands"], args["precision"]), nil
+		}
+	}
+
+	n.fields[BatchField] = func(args map[string]interface{}) (interface{}, error) {
+		requests, _ := args["requests"].([]interface{})
+
+		if len(requests) > maxBatch {
+			return nil, fmt.Errorf("%d requests, at most %d in a batch", len(requests), maxBatch)
+		}
+
+		records := make([]interface{}, 0, len(requests))
+
+		for _, request := range requests {
+			fields, _ := request.(map[string]interface{})
+			name, _ := fields["operation"].(string)
+
+			if _, ok := e.handler.registry.Lookup(name); !ok {
+				records = append(records, recordNode(map[string]interface{}{
+					"operation": name,
+					"operands":  []string{},
+					"precision": "WRAP",
+				}, fmt.Errorf("%w %q", calc.ErrUnknownOperation, name)))
+
+				continue
+			}
+
+			records = append(records, e.handler.eval(e.ctx, name, fields["operands"], fields["precision"]))
+		}
+
+		return records, nil
+	}
+
+	n.fields[OperationsField] = func(map[string]interface{}) (interface{}, error) {
+		operations := []interface{}{}
+
+		for _, op := range e.handler.registry.Operations() {
+			if !exposed(op.Name()) {
+				continue
+			}
+
+			operations = append(operations, values("Operation", map[string]interface{}{
+				"name":        op.Name(),
+				"arity":       op.Arity(),
+				"description": op.Description(),
+			}))
+		}
+
+		return operations, nil
+	}
+
+	n.fields["__schema"] = func(map[string]interface{}) (interface{}, error) {
+		return schemaNode(e.handler.schema), nil
+	}
+
+	n.fields["__type"] = func(args map[string]interface{}) (interface{}, error) {
+		name, _ := args["name"].(string)
+
+		if def, ok := e.handler.schema.Types[name]; ok {
+			return typeNode(e.handler.schema, def), nil
+		}
+
+		return nil, nil
+	}
+
+	return n
+}
+
+func (e *executor) object(n *node, set ast.SelectionSet, path ast.Path) object {
+	keys, groups := e.collect(set, n.typename, nil, map[string][]*ast.Field{})
+
+	result := make(object, 0, len(keys))
+
+	for _, key := range keys {
+		fields := groups[key]
+		field := fields[0]
+
+		if field.Name == "__typename" {
+			result = append(result, entry{key: key, value: n.typename})
+			continue
+		}
+
+		fieldPath := append(append(ast.Path{}, path...), ast.PathName(key))
+
+		resolve, ok := n.fields[field.Name]
+		if !ok {
+			result = append(result, entry{key: key})
+			continue
+		}
+
+		value, err := resolve(field.ArgumentMap(e.variables))
+		if err != nil {
+			e.errors = append(e.errors, gqlerror.WrapPath(fieldPath, err))
+			result = append(result, entry{key: key})
+
+			continue
+		}
+
+		var selections ast.SelectionSet
+
+		for _, f := range fields {
+			selections = append(selections, f.SelectionSet...)
+		}
+
+		result = append(result, entry{key: key, value: e.complete(value, selections, fieldPath)})
+	}
+
+	return result
+}
+
+func (e *executor) complete(value interface{}, set ast.SelectionSet, path ast.Path) interface{} {
+	switch v := value.(type) {
+	case *node:
+		if v == nil {
+			return nil
+		}
+
+		return e.object(v, set, path)
+	case []interface{}:
+		list := make([]interface{}, 0, len(v))
+
+		for i, item := range v {
+			list = append(list, e.complete(item, set, append(append(ast.Path{}, path...), ast.PathIndex(i))))
+		}
+
+		return list
+	}
+
+	return value
+}
+
+// collect groups the fields of a selection set by response key, in the
+// order they are first selected, following the fragments applying to
+// typename
+func (e *executor) collect(set ast.SelectionSet, typename string, keys []string, groups map[string][]*ast.Field) ([]string, map[string][]*ast.Field) {
+	for _, selection := range set {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if !e.included(s.Directives) {
+				continue
+			}
+
+			key := s.Alias
+			if key == "" {
+				key = s.Name
+			}
+
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+
+			groups[key] = append(groups[key], s)
+		case *ast.InlineFragment:
+			if !e.included(s.Directives) || (s.TypeCondition != "" && s.TypeCondition != typename) {
+				continue
+			}
+
+			keys, groups = e.collect(s.SelectionSet, typename, keys, groups)
+		case *ast.FragmentSpread:
+			fragment := e.document.Fragments.ForName(s.Name)
+
+			if !e.included(s.Directives) || fragment == nil || fragment.TypeCondition != typename {
+				continue
+			}
+
+			keys, groups = e.collect(fragment.SelectionSet, typename, keys, groups)
+		}
+	}
+
+	return keys, groups
+}
+
+// included applies @skip and @include
+func (e *executor) included(directives ast.DirectiveList) bool {
+	if skip := directives.ForName("skip"); skip != nil && isTrue(skip.ArgumentMap(e.variables)["if"]) {
+		return false
+	}
+
+	if include := directives.ForName("include"); include != nil && !isTrue(include.ArgumentMap(e.variables)["if"]) {
+		return false
+	}
+
+	return true
+}
+
+func isTrue(value interface{}) bool {
+	v := reflect.ValueOf(value)
+
+	return v.Kind() == reflect.Bool && v.Bool()
+}
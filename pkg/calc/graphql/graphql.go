@@ -0,0 +1,333 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+	_ "github.com/vektah/gqlparser/v2/validator/rules"
+)
+
+const (
+	maxBody  = 1 << 20
+	maxBatch = 100
+)
+
+// codes of the request errors, in the extensions of the errors
+const (
+	ParseFailed      = "GRAPHQL_PARSE_FAILED"
+	ValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	BadUserInput     = "BAD_USER_INPUT"
+)
+
+// Params is a GraphQL request, a JSON array of them is a batch answered
+// with an array of responses
+type Params struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type Response struct {
+	Data   interface{}   `json:"data"`
+	Errors gqlerror.List `json:"errors,omitempty"`
+}
+
+type Options struct {
+	// Timeout of each evaluation, none when zero
+	Timeout time.Duration
+}
+
+// Handler executes queries against the schema generated from the
+// operations of a registry
+type Handler struct {
+	registry *calc.Registry
+	schema   *ast.Schema
+	opts     Options
+}
+
+func New(registry *calc.Registry, opts Options) (*Handler, error) {
+	schema, err := Schema(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{registry: registry, schema: schema, opts: opts}, nil
+}
+
+// ServeHTTP takes the query in the body of POST requests or in the query
+// string of GET requests
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		params := Params{Query: r.URL.Query().Get("query"), OperationName: r.URL.Query().Get("operationName")}
+
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := unmarshal([]byte(variables), &params.Variables); err != nil {
+				writeJSON(w, http.StatusBadRequest, failed(BadUserInput, "invalid variables: %v", err))
+				return
+			}
+		}
+
+		writeJSON(w, http.StatusOK, h.Execute(r.Context(), params))
+	case http.MethodPost:
+		body, err := readBody(w, r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, failed(BadUserInput, "%v", err))
+			return
+		}
+
+		if len(body) > 0 && body[0] == '[' {
+			h.batch(w, r, body)
+			return
+		}
+
+		var params Params
+
+		if err := unmarshal(body, &params); err != nil {
+			writeJSON(w, http.StatusBadRequest, failed(BadUserInput, "invalid request: %v", err))
+			return
+		}
+
+		writeJSON(w, http.StatusOK, h.Execute(r.Context(), params))
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, failed(BadUserInput, "use GET or POST"))
+	}
+}
+
+func (h *Handler) batch(w http.ResponseWriter, r *http.Request, body []byte) {
+	var batch []Params
+
+	if err := unmarshal(body, &batch); err != nil {
+		writeJSON(w, http.StatusBadRequest, failed(BadUserInput, "invalid batch: %v", err))
+		return
+	}
+
+	if len(batch) > maxBatch {
+		writeJSON(w, http.StatusRequestEntityTooLarge, failed(BadUserInput, "%d queries, at most %d in a batch", len(batch), maxBatch))
+		return
+	}
+
+	responses := make([]Response, 0, len(batch))
+
+	for _, params := range batch {
+		responses = append(responses, h.Execute(r.Context(), params))
+	}
+
+	writeJSON(w, http.StatusOK, responses)
+}
+
+// Execute parses, validates and executes a query, only queries are
+// defined so there is nothing to mutate
+func (h *Handler) Execute(ctx context.Context, params Params) Response {
+	document, err := parser.ParseQuery(&ast.Source{Name: "query", Input: params.Query})
+	if err != nil {
+		return Response{Errors: withCode(gqlerror.List{asGQLError(err)}, ParseFailed)}
+	}
+
+	if errs := validator.Validate(h.schema, document); errs != nil {
+		return Response{Errors: withCode(errs, ValidationFailed)}
+	}
+
+	operation, err := selectOperation(document, params.OperationName)
+	if err != nil {
+		return Response{Errors: withCode(gqlerror.List{asGQLError(err)}, ValidationFailed)}
+	}
+
+	variables, err := validator.VariableValues(h.schema, operation, params.Variables)
+	if err != nil {
+		return Response{Errors: withCode(gqlerror.List{asGQLError(err)}, BadUserInput)}
+	}
+
+	e := &executor{handler: h, ctx: ctx, document: document, variables: variables}
+
+	data := e.object(e.query(), operation.SelectionSet, nil)
+
+	return Response{Data: data, Errors: e.errors}
+}
+
+func selectOperation(document *ast.QueryDocument, name string) (*ast.OperationDefinition, error) {
+	if name != "" {
+		if operation := document.Operations.ForName(name); operation != nil {
+			return operation, nil
+		}
+
+		return nil, fmt.Errorf("unknown operation %q", name)
+	}
+
+	if len(document.Operations) != 1 {
+		return nil, errors.New("operationName is required with several operations")
+	}
+
+	return document.Operations[0], nil
+}
+
+// eval evaluates an operation field, errors are data of the record
+func (h *Handler) eval(ctx context.Context, name string, operands interface{}, precision interface{}) *node {
+	record := map[string]interface{}{
+		"operation": name,
+		"operands":  []string{},
+		"precision": "WRAP",
+	}
+
+	if p, _ := precision.(string); strings.EqualFold(p, "CHECKED") {
+		ctx = calc.Checked(ctx)
+		record["precision"] = "CHECKED"
+	}
+
+	values, err := integers(operands)
+	if err != nil {
+		return recordNode(record, err)
+	}
+
+	for _, value := range values {
+		record["operands"] = append(record["operands"].([]string), strconv.Itoa(value))
+	}
+
+	if h.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, h.opts.Timeout)
+		defer cancel()
+	}
+
+	result, err := h.registry.Eval(ctx, name, values...)
+	if err != nil {
+		return recordNode(record, err)
+	}
+
+	record["result"] = strconv.Itoa(result)
+
+	return recordNode(record, nil)
+}
+
+// Code maps the errors of pkg/calc to the ErrorCode enum
+func Code(err error) string {
+	switch {
+	case errors.Is(err, calc.ErrInvalidOperand):
+		return "INVALID_OPERAND"
+	case errors.Is(err, calc.ErrArity):
+		return "ARITY"
+	case errors.Is(err, calc.ErrDivisionByZero):
+		return "DIVISION_BY_ZERO"
+	case errors.Is(err, calc.ErrOverflow):
+		return "OVERFLOW"
+	case errors.Is(err, calc.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "TIMEOUT"
+	case errors.Is(err, calc.ErrUnknownOperation):
+		return "UNKNOWN_OPERATION"
+	}
+
+	return "INTERNAL"
+}
+
+func recordNode(record map[string]interface{}, err error) *node {
+	if err != nil {
+		record["error"] = values("Error", map[string]interface{}{"code": Code(err), "message": err.Error()})
+	}
+
+	return values("Record", record)
+}
+
+// integers coerces Int64 values: numbers of literals and variables or
+// strings, a single value is a list of one
+func integers(value interface{}) ([]int, error) {
+	list := reflect.ValueOf(value)
+
+	if list.Kind() != reflect.Slice {
+		list = reflect.ValueOf([]interface{}{value})
+	}
+
+	operands := make([]int, 0, list.Len())
+
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i).Interface()
+
+		var (
+			operand int64
+			err     error
+		)
+
+		switch v := item.(type) {
+		case int64:
+			operand = v
+		case json.Number:
+			operand, err = v.Int64()
+		case string:
+			operand, err = strconv.ParseInt(v, 10, 64)
+		default:
+			err = fmt.Errorf("not an integer: %v", v)
+		}
+
+		if err != nil {
+			return nil, &calc.OperandError{Operand: fmt.Sprint(item), Err: err}
+		}
+
+		operands = append(operands, int(operand))
+	}
+
+	return operands, nil
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	var b bytes.Buffer
+
+	if _, err := b.ReadFrom(http.MaxBytesReader(w, r.Body, maxBody)); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(b.Bytes()), nil
+}
+
+// unmarshal keeps the numbers of the variables exact
+func unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func failed(code, format string, args ...interface{}) Response {
+	return Response{Errors: withCode(gqlerror.List{gqlerror.Errorf(format, args...)}, code)}
+}
+
+func asGQLError(err error) *gqlerror.Error {
+	var e *gqlerror.Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	return gqlerror.Errorf("%s", strings.TrimSpace(err.Error()))
+}
+
+func withCode(errs gqlerror.List, code string) gqlerror.List {
+	for _, err := range errs {
+		if err.Extensions == nil {
+			err.Extensions = map[string]interface{}{}
+		}
+
+		err.Extensions["code"] = code
+	}
+
+	return errs
+}
@@ -0,0 +1,164 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+func execute(t *testing.T, h *Handler, params Params) string {
+	t.Helper()
+
+	response, err := json.Marshal(h.Execute(context.Background(), params))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(response)
+}
+
+func handler(t *testing.T, opts Options) *Handler {
+	t.Helper()
+
+	h, err := New(calc.Default, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema(calc.Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range calc.Operations() {
+		if schema.Query.Fields.ForName(op.Name()) == nil {
+			t.Errorf("missing field for %s", op.Name())
+		}
+	}
+
+	assert.Equal(t, exposed("sum"), true)
+	assert.Equal(t, exposed("to-hex"), false)
+	assert.Equal(t, exposed(BatchField), false)
+}
+
+func TestExecute(t *testing.T) {
+	h := handler(t, Options{})
+
+	type testCase struct {
+		params   Params
+		expected string
+	}
+
+	cases := []testCase{
+		{
+			params:   Params{Query: `{ sum(operands: [2, 3]) { result error { code } } }`},
+			expected: `{"data":{"sum":{"result":"5","error":null}}}`,
+		},
+		{
+			params:   Params{Query: `{ q: div(operands: [7, 2]) { result } r: div(operands: [7, 0]) { result error { code message } } }`},
+			expected: `{"data":{"q":{"result":"3"},"r":{"result":null,"error":{"code":"DIVISION_BY_ZERO","message":"division by zero"}}}}`,
+		},
+		{
+			params:   Params{Query: `query ($n: Int64!) { mul(operands: [$n, 2], precision: CHECKED) { precision error { code } } }`, Variables: map[string]interface{}{"n": json.Number("9223372036854775807")}},
+			expected: `{"data":{"mul":{"precision":"CHECKED","error":{"code":"OVERFLOW"}}}}`,
+		},
+		{
+			params:   Params{Query: `{ sum(operands: [1]) { error { code } } }`},
+			expected: `{"data":{"sum":{"error":{"code":"ARITY"}}}}`,
+		},
+		{
+			params:   Params{Query: `{ sum(operands: ["9223372036854775807", "x"]) { operands error { code } } }`},
+			expected: `{"data":{"sum":{"operands":[],"error":{"code":"INVALID_OPERAND"}}}}`,
+		},
+		{
+			params:   Params{Query: `{ batch(requests: [{operation: "sub", operands: [5, 8]}, {operation: "mod", operands: [1, 2]}]) { operation result error { code } } }`},
+			expected: `{"data":{"batch":[{"operation":"sub","result":"-3","error":null},{"operation":"mod","result":null,"error":{"code":"UNKNOWN_OPERATION"}}]}}`,
+		},
+		{
+			params:   Params{Query: `query ($s: Boolean!) { pow(operands: [2, 10]) { ...r } __typename @skip(if: $s) } fragment r on Record { result __typename }`, Variables: map[string]interface{}{"s": true}},
+			expected: `{"data":{"pow":{"result":"1024","__typename":"Record"}}}`,
+		},
+		{
+			params:   Params{Query: `{ sum(operands: [1, 2]) { result`},
+			expected: `"GRAPHQL_PARSE_FAILED"`,
+		},
+		{
+			params:   Params{Query: `{ mod(operands: [1, 2]) { result } }`},
+			expected: `"GRAPHQL_VALIDATION_FAILED"`,
+		},
+		{
+			params:   Params{Query: `mutation { sum(operands: [1, 2]) { result } }`},
+			expected: `"GRAPHQL_VALIDATION_FAILED"`,
+		},
+	}
+
+	for _, tc := range cases {
+		assert.StringContains(t, execute(t, h, tc.params), tc.expected)
+	}
+}
+
+func TestIntrospection(t *testing.T) {
+	h := handler(t, Options{})
+
+	response := execute(t, h, Params{Query: `{
+		__schema { queryType { name } }
+		__type(name: "Query") {
+			kind
+			fields { name args { name defaultValue type { kind ofType { kind ofType { kind ofType { kind name } } } } } }
+		}
+		precision: __type(name: "Precision") { enumValues { name } }
+	}`})
+
+	assert.StringContains(t, response, `"__schema":{"queryType":{"name":"Query"}}`)
+	assert.StringContains(t, response, `{"name":"sum","args":[{"name":"operands","defaultValue":null,"type":{"kind":"NON_NULL","ofType":{"kind":"LIST","ofType":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"Int64"}}}}},{"name":"precision","defaultValue":"WRAP"`)
+	assert.StringContains(t, response, `"precision":{"enumValues":[{"name":"WRAP"},{"name":"CHECKED"}]}`)
+	assert.Equal(t, strings.Contains(response, "__schema\",\"args"), false)
+}
+
+func TestServeHTTP(t *testing.T) {
+	h := handler(t, Options{Timeout: time.Millisecond})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`[
+		{"query": "{ sum(operands: [1, 2]) { result } }"},
+		{"query": "{ pow(operands: [3, 100000000]) { error { code } } }"}
+	]`)))
+
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, strings.TrimSpace(w.Body.String()), `[{"data":{"sum":{"result":"3"}}},{"data":{"pow":{"error":{"code":"TIMEOUT"}}}}]`)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{ operations { name } }"), nil))
+
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.StringContains(t, w.Body.String(), `{"name":"sum"}`)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": `)))
+
+	assert.Equal(t, w.Code, http.StatusBadRequest)
+}
+
+func TestLargeExponent(t *testing.T) {
+	h := handler(t, Options{Timeout: 100 * time.Millisecond})
+
+	response := execute(t, h, Params{Query: `{
+		wrap: pow(operands: [3, 200000000]) { result error { code } }
+		checked: pow(operands: [3, 200000000], precision: CHECKED) { result error { code } }
+		one: pow(operands: [1, 200000000]) { result error { code } }
+	}`})
+
+	assert.Equal(t, response, `{"data":{"wrap":{"result":null,"error":{"code":"TIMEOUT"}},"checked":{"result":null,"error":{"code":"OVERFLOW"}},"one":{"result":"1","error":null}}}`)
+}
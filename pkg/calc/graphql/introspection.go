@@ -0,0 +1,247 @@
+package graphql
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// the introspection types of the specification, built lazily from the
+// schema as types reference each other
+
+func schemaNode(schema *ast.Schema) *node {
+	return &node{typename: "__Schema", fields: map[string]resolver{
+		"description": constant(optional(schema.Description)),
+		"types": func(map[string]interface{}) (interface{}, error) {
+			names := make([]string, 0, len(schema.Types))
+
+			for name := range schema.Types {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			types := make([]interface{}, 0, len(names))
+
+			for _, name := range names {
+				types = append(types, typeNode(schema, schema.Types[name]))
+			}
+
+			return types, nil
+		},
+		"queryType": func(map[string]interface{}) (interface{}, error) {
+			return typeNode(schema, schema.Query), nil
+		},
+		"directives": func(map[string]interface{}) (interface{}, error) {
+			names := make([]string, 0, len(schema.Directives))
+
+			for name := range schema.Directives {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			directives := make([]interface{}, 0, len(names))
+
+			for _, name := range names {
+				directives = append(directives, directiveNode(schema, schema.Directives[name]))
+			}
+
+			return directives, nil
+		},
+	}}
+}
+
+func typeNode(schema *ast.Schema, def *ast.Definition) *node {
+	n := &node{typename: "__Type", fields: map[string]resolver{
+		"kind":        constant(string(def.Kind)),
+		"name":        constant(def.Name),
+		"description": constant(optional(def.Description)),
+	}}
+
+	switch def.Kind {
+	case ast.Object, ast.Interface:
+		n.fields["fields"] = func(args map[string]interface{}) (interface{}, error) {
+			fields := []interface{}{}
+
+			for _, field := range def.Fields {
+				if strings.HasPrefix(field.Name, "__") || (deprecated(field.Directives) && !isTrue(args["includeDeprecated"])) {
+					continue
+				}
+
+				fields = append(fields, fieldNode(schema, field))
+			}
+
+			return fields, nil
+		}
+	}
+
+	switch def.Kind {
+	case ast.Object:
+		n.fields["interfaces"] = func(map[string]interface{}) (interface{}, error) {
+			interfaces := []interface{}{}
+
+			for _, name := range def.Interfaces {
+				interfaces = append(interfaces, typeNode(schema, schema.Types[name]))
+			}
+
+			return interfaces, nil
+		}
+	case ast.Interface, ast.Union:
+		n.fields["possibleTypes"] = func(map[string]interface{}) (interface{}, error) {
+			types := []interface{}{}
+
+			for _, possible := range schema.PossibleTypes[def.Name] {
+				types = append(types, typeNode(schema, possible))
+			}
+
+			return types, nil
+		}
+	case ast.Enum:
+		n.fields["enumValues"] = func(args map[string]interface{}) (interface{}, error) {
+			enumValues := []interface{}{}
+
+			for _, value := range def.EnumValues {
+				if deprecated(value.Directives) && !isTrue(args["includeDeprecated"]) {
+					continue
+				}
+
+				enumValues = append(enumValues, values("__EnumValue", map[string]interface{}{
+					"name":              value.Name,
+					"description":       optional(value.Description),
+					"isDeprecated":      deprecated(value.Directives),
+					"deprecationReason": deprecationReason(value.Directives),
+				}))
+			}
+
+			return enumValues, nil
+		}
+	case ast.InputObject:
+		n.fields["inputFields"] = func(map[string]interface{}) (interface{}, error) {
+			inputFields := []interface{}{}
+
+			for _, field := range def.Fields {
+				inputFields = append(inputFields, inputValueNode(schema, field.Name, field.Description, field.Type, field.DefaultValue))
+			}
+
+			return inputFields, nil
+		}
+	}
+
+	return n
+}
+
+// typeRefNode is the type of a field or argument, lists and non-null
+// types wrap the named type in ofType
+func typeRefNode(schema *ast.Schema, t *ast.Type) *node {
+	switch {
+	case t.NonNull:
+		nullable := *t
+		nullable.NonNull = false
+
+		return &node{typename: "__Type", fields: map[string]resolver{
+			"kind": constant("NON_NULL"),
+			"ofType": func(map[string]interface{}) (interface{}, error) {
+				return typeRefNode(schema, &nullable), nil
+			},
+		}}
+	case t.Elem != nil:
+		return &node{typename: "__Type", fields: map[string]resolver{
+			"kind": constant("LIST"),
+			"ofType": func(map[string]interface{}) (interface{}, error) {
+				return typeRefNode(schema, t.Elem), nil
+			},
+		}}
+	}
+
+	return typeNode(schema, schema.Types[t.NamedType])
+}
+
+func fieldNode(schema *ast.Schema, field *ast.FieldDefinition) *node {
+	return &node{typename: "__Field", fields: map[string]resolver{
+		"name":              constant(field.Name),
+		"description":       constant(optional(field.Description)),
+		"isDeprecated":      constant(deprecated(field.Directives)),
+		"deprecationReason": constant(deprecationReason(field.Directives)),
+		"args": func(map[string]interface{}) (interface{}, error) {
+			args := []interface{}{}
+
+			for _, arg := range field.Arguments {
+				args = append(args, inputValueNode(schema, arg.Name, arg.Description, arg.Type, arg.DefaultValue))
+			}
+
+			return args, nil
+		},
+		"type": func(map[string]interface{}) (interface{}, error) {
+			return typeRefNode(schema, field.Type), nil
+		},
+	}}
+}
+
+func inputValueNode(schema *ast.Schema, name, description string, t *ast.Type, defaultValue *ast.Value) *node {
+	var value interface{}
+
+	if defaultValue != nil {
+		value = defaultValue.String()
+	}
+
+	return &node{typename: "__InputValue", fields: map[string]resolver{
+		"name":         constant(name),
+		"description":  constant(optional(description)),
+		"defaultValue": constant(value),
+		"type": func(map[string]interface{}) (interface{}, error) {
+			return typeRefNode(schema, t), nil
+		},
+	}}
+}
+
+func directiveNode(schema *ast.Schema, directive *ast.DirectiveDefinition) *node {
+	locations := make([]string, 0, len(directive.Locations))
+
+	for _, location := range directive.Locations {
+		locations = append(locations, string(location))
+	}
+
+	return &node{typename: "__Directive", fields: map[string]resolver{
+		"name":         constant(directive.Name),
+		"description":  constant(optional(directive.Description)),
+		"locations":    constant(locations),
+		"isRepeatable": constant(directive.IsRepeatable),
+		"args": func(map[string]interface{}) (interface{}, error) {
+			args := []interface{}{}
+
+			for _, arg := range directive.Arguments {
+				args = append(args, inputValueNode(schema, arg.Name, arg.Description, arg.Type, arg.DefaultValue))
+			}
+
+			return args, nil
+		},
+	}}
+}
+
+func deprecated(directives ast.DirectiveList) bool {
+	return directives.ForName("deprecated") != nil
+}
+
+func deprecationReason(directives ast.DirectiveList) interface{} {
+	directive := directives.ForName("deprecated")
+	if directive == nil {
+		return nil
+	}
+
+	if reason := directive.Arguments.ForName("reason"); reason != nil {
+		return reason.Value.Raw
+	}
+
+	return "No longer supported"
+}
+
+// optional is null for empty descriptions
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
@@ -0,0 +1,105 @@
+package graphql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// fields of Query that are not operations, operations with these names
+// are not exposed
+const (
+	BatchField      = "batch"
+	OperationsField = "operations"
+)
+
+// names the GraphQL grammar accepts, plugin operations may not match
+var fieldName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// types shared by every schema, Query is appended with a field per
+// operation
+const types = `"Integers of 64 bits, written as numbers or strings, Int is limited to 32 bits"
+scalar Int64
+
+"How integer overflows are handled"
+enum Precision {
+  "wrap around like Go's int"
+  WRAP
+  "fail with OVERFLOW"
+  CHECKED
+}
+
+enum ErrorCode {
+  INVALID_OPERAND
+  ARITY
+  DIVISION_BY_ZERO
+  OVERFLOW
+  TIMEOUT
+  UNKNOWN_OPERATION
+  INTERNAL
+}
+
+"Errors are returned in the records so a failed operation does not fail the others of a query"
+type Error {
+  code: ErrorCode!
+  message: String!
+}
+
+"The result of an operation, the schema of the CLI structured outputs"
+type Record {
+  operation: String!
+  operands: [String!]!
+  "null when the operation failed"
+  result: String
+  precision: Precision!
+  error: Error
+}
+
+type Operation {
+  name: String!
+  arity: Int!
+  description: String!
+}
+
+input Request {
+  operation: String!
+  operands: [Int64!]!
+  precision: Precision = WRAP
+}
+`
+
+// exposed reports whether the operation called name is a field of Query
+func exposed(name string) bool {
+	return fieldName.MatchString(name) && !strings.HasPrefix(name, "__") && name != BatchField && name != OperationsField
+}
+
+// SDL is the schema of registry in the GraphQL schema language
+func SDL(registry *calc.Registry) string {
+	var b strings.Builder
+
+	b.WriteString(types)
+	b.WriteString("\ntype Query {\n")
+
+	for _, op := range registry.Operations() {
+		if !exposed(op.Name()) {
+			continue
+		}
+
+		fmt.Fprintf(&b, "  %q\n  %s(operands: [Int64!]!, precision: Precision = WRAP): Record!\n", fmt.Sprintf("%s, %d operands", op.Description(), op.Arity()), op.Name())
+	}
+
+	fmt.Fprintf(&b, "  \"evaluate several requests, failed requests carry their error\"\n  %s(requests: [Request!]!): [Record!]!\n", BatchField)
+	fmt.Fprintf(&b, "  \"the operations of the schema\"\n  %s: [Operation!]!\n", OperationsField)
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Schema loads the schema of registry
+func Schema(registry *calc.Registry) (*ast.Schema, error) {
+	return gqlparser.LoadSchema(&ast.Source{Name: "calc.graphql", Input: SDL(registry)})
+}
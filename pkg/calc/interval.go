@@ -0,0 +1,292 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Interval is the closed set of reals [Lo, Hi], bounds may be infinite.
+// Bounds are rounded outward, toward -Inf for Lo and +Inf for Hi, when a
+// literal or a result falls between two float64, so the interval always
+// holds the exact value
+type Interval struct {
+	Lo float64
+	Hi float64
+}
+
+var (
+	Entire = Interval{Lo: math.Inf(-1), Hi: math.Inf(1)}
+
+	ErrInvalidInterval = errors.New("invalid interval")
+)
+
+func NewInterval(lo, hi float64) (Interval, error) {
+	if math.IsNaN(lo) || math.IsNaN(hi) || lo > hi {
+		return Interval{}, fmt.Errorf("%w [%g,%g]", ErrInvalidInterval, lo, hi)
+	}
+
+	return Interval{Lo: lo, Hi: hi}, nil
+}
+
+// IsInterval reports whether s looks like an interval literal
+func IsInterval(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "[")
+}
+
+// ParseInterval parses [lo,hi] literals, a plain number is the
+// degenerate interval [n,n]
+func ParseInterval(s string) (Interval, error) {
+	s = strings.TrimSpace(s)
+
+	if !IsInterval(s) {
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return Interval{}, fmt.Errorf("%w %q", ErrInvalidInterval, s)
+		}
+
+		return NewInterval(down(value, literalError(s, value)), up(value, literalError(s, value)))
+	}
+
+	lo, hi, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"), ",")
+
+	if !ok || !strings.HasSuffix(s, "]") {
+		return Interval{}, fmt.Errorf("%w %q, expected [lo,hi]", ErrInvalidInterval, s)
+	}
+
+	low, err := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+	if err != nil {
+		return Interval{}, fmt.Errorf("%w %q: %s", ErrInvalidInterval, s, err)
+	}
+
+	high, err := strconv.ParseFloat(strings.TrimSpace(hi), 64)
+	if err != nil {
+		return Interval{}, fmt.Errorf("%w %q: %s", ErrInvalidInterval, s, err)
+	}
+
+	return NewInterval(down(low, literalError(lo, low)), up(high, literalError(hi, high)))
+}
+
+// literalError is the sign of the error of parsing s as value, 0 when s
+// is exactly value or infinite
+func literalError(s string, value float64) float64 {
+	exact, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || math.IsInf(value, 0) {
+		return 0
+	}
+
+	return float64(exact.Cmp(new(big.Rat).SetFloat64(value)))
+}
+
+func (x Interval) Contains(value float64) bool {
+	return x.Lo <= value && value <= x.Hi
+}
+
+func (x Interval) String() string {
+	return fmt.Sprintf("[%s,%s]", formatBound(x.Lo), formatBound(x.Hi))
+}
+
+func formatBound(bound float64) string {
+	return strconv.FormatFloat(bound, 'g', -1, 64)
+}
+
+func (x Interval) Add(y Interval) Interval {
+	return Interval{Lo: down(twoSum(x.Lo, y.Lo)), Hi: up(twoSum(x.Hi, y.Hi))}
+}
+
+func (x Interval) Sub(y Interval) Interval {
+	return Interval{Lo: down(twoSum(x.Lo, -y.Hi)), Hi: up(twoSum(x.Hi, -y.Lo))}
+}
+
+func (x Interval) Mul(y Interval) Interval {
+	result := Interval{Lo: math.Inf(1), Hi: math.Inf(-1)}
+
+	for _, a := range []float64{x.Lo, x.Hi} {
+		for _, b := range []float64{y.Lo, y.Hi} {
+			result.Lo = math.Min(result.Lo, down(twoProduct(a, b)))
+			result.Hi = math.Max(result.Hi, up(twoProduct(a, b)))
+		}
+	}
+
+	return result
+}
+
+// down is result rounded toward -Inf when err, the sign of exact - result,
+// is negative
+func down(result, err float64) float64 {
+	if err < 0 {
+		return math.Nextafter(result, math.Inf(-1))
+	}
+
+	return result
+}
+
+// up is result rounded toward +Inf when err is positive
+func up(result, err float64) float64 {
+	if err > 0 {
+		return math.Nextafter(result, math.Inf(1))
+	}
+
+	return result
+}
+
+// twoSum is a + b with the exact error of its rounding
+func twoSum(a, b float64) (float64, float64) {
+	s := a + b
+	if math.IsInf(s, 0) {
+		return s, overflow(s, a, b)
+	}
+
+	bb := s - a
+
+	return s, (a - (s - bb)) + (b - bb)
+}
+
+// twoProduct is a * b with the exact error of its rounding, 0 * inf is 0
+// as an infinite bound is never reached
+func twoProduct(a, b float64) (float64, float64) {
+	if a == 0 || b == 0 {
+		return 0, 0
+	}
+
+	p := a * b
+	if math.IsInf(p, 0) {
+		return p, overflow(p, a, b)
+	}
+
+	return p, math.FMA(a, b, -p)
+}
+
+// twoQuotient is a / b with the sign of the error of its rounding
+func twoQuotient(a, b float64) (float64, float64) {
+	q := a / b
+	if math.IsInf(q, 0) {
+		return q, overflow(q, a, b)
+	}
+
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return q, 0
+	}
+
+	// the remainder of the rounded quotient is exact
+	rest := math.FMA(-q, b, a)
+
+	switch {
+	case rest == 0:
+		return q, 0
+	case (rest > 0) == (b > 0):
+		return q, 1
+	}
+
+	return q, -1
+}
+
+// overflow is the error of an infinite result: none when an operand is
+// infinite, else the finite exact result lies before the infinity
+func overflow(result, a, b float64) float64 {
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return 0
+	}
+
+	return -result
+}
+
+// Div returns the set {a / b | a in x, b in y, b != 0}, when y contains
+// zero in its interior the result is made of two disjoint intervals
+func (x Interval) Div(y Interval) ([]Interval, error) {
+	switch {
+	case y.Lo == 0 && y.Hi == 0:
+		return nil, ErrDivisionByZero
+	case !y.Contains(0):
+		return []Interval{x.Mul(Interval{Lo: down(twoQuotient(1, y.Hi)), Hi: up(twoQuotient(1, y.Lo))})}, nil
+	case x.Contains(0):
+		return []Interval{Entire}, nil
+	}
+
+	// y contains zero, x is either all negative or all positive
+	if x.Hi < 0 {
+		switch {
+		case y.Lo == 0:
+			return []Interval{{Lo: math.Inf(-1), Hi: up(twoQuotient(x.Hi, y.Hi))}}, nil
+		case y.Hi == 0:
+			return []Interval{{Lo: down(twoQuotient(x.Hi, y.Lo)), Hi: math.Inf(1)}}, nil
+		}
+
+		return []Interval{{Lo: math.Inf(-1), Hi: up(twoQuotient(x.Hi, y.Hi))}, {Lo: down(twoQuotient(x.Hi, y.Lo)), Hi: math.Inf(1)}}, nil
+	}
+
+	switch {
+	case y.Lo == 0:
+		return []Interval{{Lo: down(twoQuotient(x.Lo, y.Hi)), Hi: math.Inf(1)}}, nil
+	case y.Hi == 0:
+		return []Interval{{Lo: math.Inf(-1), Hi: up(twoQuotient(x.Lo, y.Lo))}}, nil
+	}
+
+	return []Interval{{Lo: math.Inf(-1), Hi: up(twoQuotient(x.Lo, y.Lo))}, {Lo: down(twoQuotient(x.Lo, y.Hi)), Hi: math.Inf(1)}}, nil
+}
+
+// Pow raises every element of x to the integer exponent
+func (x Interval) Pow(exponent int) (Interval, error) {
+	if exponent >= 0 {
+		return x.pow(uint(exponent)), nil
+	}
+
+	if x.Contains(0) {
+		return Interval{}, ErrDivisionByZero
+	}
+
+	// uint(-exponent) is right for MinInt too
+	power := x.pow(uint(-exponent))
+
+	return Interval{Lo: down(twoQuotient(1, power.Hi)), Hi: up(twoQuotient(1, power.Lo))}, nil
+}
+
+func (x Interval) pow(exponent uint) Interval {
+	switch {
+	case exponent == 0:
+		return Interval{Lo: 1, Hi: 1}
+	case exponent%2 == 1 || x.Lo >= 0:
+		return Interval{Lo: power(x.Lo, exponent, false), Hi: power(x.Hi, exponent, true)}
+	case x.Hi <= 0:
+		return Interval{Lo: power(x.Hi, exponent, false), Hi: power(x.Lo, exponent, true)}
+	}
+
+	// even exponent of an interval containing zero
+	return Interval{Lo: 0, Hi: math.Max(power(x.Lo, exponent, true), power(x.Hi, exponent, true))}
+}
+
+// power is base^exponent rounded toward +Inf when up, toward -Inf
+// otherwise, squaring with the rounding of every product
+func power(base float64, exponent uint, up bool) float64 {
+	if base < 0 {
+		if exponent%2 == 0 {
+			return power(-base, exponent, up)
+		}
+
+		return -power(-base, exponent, !up)
+	}
+
+	result := 1.0
+
+	for ; exponent > 0; exponent >>= 1 {
+		if exponent&1 == 1 {
+			result = rounded(result, base, up)
+		}
+
+		base = rounded(base, base, up)
+	}
+
+	return result
+}
+
+// rounded is a * b of non-negative a and b rounded toward +Inf when up
+func rounded(a, b float64, toward bool) float64 {
+	if toward {
+		return up(twoProduct(a, b))
+	}
+
+	return down(twoProduct(a, b))
+}
@@ -0,0 +1,310 @@
+package calc
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestParseInterval(t *testing.T) {
+	type testCase struct {
+		input    string
+		interval string
+		ok       bool
+	}
+
+	cases := []testCase{
+		{
+			input:    "[1,2]",
+			interval: "[1,2]",
+			ok:       true,
+		},
+		{
+			input:    "[ -1.5 , 2e3 ]",
+			interval: "[-1.5,2000]",
+			ok:       true,
+		},
+		{
+			input:    "3",
+			interval: "[3,3]",
+			ok:       true,
+		},
+		{
+			input:    "[-inf,0]",
+			interval: "[-Inf,0]",
+			ok:       true,
+		},
+		{
+			input: "[2,1]",
+			ok:    false,
+		},
+		{
+			input: "[1,2",
+			ok:    false,
+		},
+		{
+			input: "[1;2]",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		interval, err := ParseInterval(tc.input)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error for %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, interval.String(), tc.interval)
+		}
+	}
+}
+
+func TestIntervalArithmetic(t *testing.T) {
+	type testCase struct {
+		op     func(x, y Interval) Interval
+		x      string
+		y      string
+		result string
+	}
+
+	cases := []testCase{
+		{
+			op:     Interval.Add,
+			x:      "[1,2]",
+			y:      "[10,20]",
+			result: "[11,22]",
+		},
+		{
+			op:     Interval.Sub,
+			x:      "[1,2]",
+			y:      "[10,20]",
+			result: "[-19,-8]",
+		},
+		{
+			op:     Interval.Mul,
+			x:      "[-1,2]",
+			y:      "[-3,4]",
+			result: "[-6,8]",
+		},
+		{
+			op:     Interval.Mul,
+			x:      "[0,1]",
+			y:      "[-inf,inf]",
+			result: "[-Inf,+Inf]",
+		},
+	}
+
+	for _, tc := range cases {
+		x, _ := ParseInterval(tc.x)
+		y, _ := ParseInterval(tc.y)
+
+		assert.Equal(t, tc.op(x, y).String(), tc.result)
+	}
+}
+
+func TestIntervalDiv(t *testing.T) {
+	type testCase struct {
+		x      string
+		y      string
+		result string
+		ok     bool
+	}
+
+	cases := []testCase{
+		{
+			x:      "[1,2]",
+			y:      "[4,8]",
+			result: "[0.125,0.5]",
+			ok:     true,
+		},
+		{
+			x:      "[1,2]",
+			y:      "[-1,1]",
+			result: "[-Inf,-1] [1,+Inf]",
+			ok:     true,
+		},
+		{
+			x:      "[-2,-1]",
+			y:      "[0,4]",
+			result: "[-Inf,-0.25]",
+			ok:     true,
+		},
+		{
+			x:      "[1,2]",
+			y:      "[-4,0]",
+			result: "[-Inf,-0.25]",
+			ok:     true,
+		},
+		{
+			x:      "[-1,1]",
+			y:      "[-1,1]",
+			result: "[-Inf,+Inf]",
+			ok:     true,
+		},
+		{
+			x:  "[1,2]",
+			y:  "[0,0]",
+			ok: false,
+		},
+	}
+
+	for _, tc := range cases {
+		x, _ := ParseInterval(tc.x)
+		y, _ := ParseInterval(tc.y)
+
+		parts, err := x.Div(y)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error dividing %s by %s", tc.x, tc.y)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			result := []string{}
+			for _, part := range parts {
+				result = append(result, part.String())
+			}
+
+			assert.Equal(t, strings.Join(result, " "), tc.result)
+		}
+	}
+}
+
+func TestIntervalPow(t *testing.T) {
+	type testCase struct {
+		x        string
+		exponent int
+		result   string
+		ok       bool
+	}
+
+	cases := []testCase{
+		{
+			x:        "[-2,3]",
+			exponent: 2,
+			result:   "[0,9]",
+			ok:       true,
+		},
+		{
+			x:        "[-3,-2]",
+			exponent: 2,
+			result:   "[4,9]",
+			ok:       true,
+		},
+		{
+			x:        "[-2,3]",
+			exponent: 3,
+			result:   "[-8,27]",
+			ok:       true,
+		},
+		{
+			x:        "[2,4]",
+			exponent: -1,
+			result:   "[0.25,0.5]",
+			ok:       true,
+		},
+		{
+			x:        "[5,6]",
+			exponent: 0,
+			result:   "[1,1]",
+			ok:       true,
+		},
+		{
+			x:        "[-1,1]",
+			exponent: -2,
+			ok:       false,
+		},
+	}
+
+	for _, tc := range cases {
+		x, _ := ParseInterval(tc.x)
+
+		power, err := x.Pow(tc.exponent)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error raising %s to %d", tc.x, tc.exponent)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, power.String(), tc.result)
+		}
+	}
+}
+
+func TestIntervalOutwardRounding(t *testing.T) {
+	type testCase struct {
+		name   string
+		result func(x, y Interval) Interval
+		x      string
+		y      string
+		exact  string
+	}
+
+	cases := []testCase{
+		{name: "sum", result: Interval.Add, x: "0.1", y: "0.2", exact: "3/10"},
+		{name: "difference", result: Interval.Sub, x: "0.3", y: "[0.1,0.1]", exact: "1/5"},
+		{name: "product", result: Interval.Mul, x: "0.1", y: "3", exact: "3/10"},
+		{
+			name: "quotient",
+			result: func(x, y Interval) Interval {
+				parts, _ := x.Div(y)
+				return parts[0]
+			},
+			x:     "1",
+			y:     "3",
+			exact: "1/3",
+		},
+		{
+			name: "power",
+			result: func(x, y Interval) Interval {
+				power, _ := x.Pow(-3)
+				return power
+			},
+			x:     "[0.1,0.1]",
+			exact: "1000",
+		},
+	}
+
+	for _, tc := range cases {
+		x, _ := ParseInterval(tc.x)
+		y, _ := ParseInterval(tc.y)
+		exact, _ := new(big.Rat).SetString(tc.exact)
+
+		result := tc.result(x, y)
+
+		lo := new(big.Rat).SetFloat64(result.Lo)
+		hi := new(big.Rat).SetFloat64(result.Hi)
+
+		if lo.Cmp(exact) >= 0 || hi.Cmp(exact) <= 0 {
+			t.Errorf("%s: %s does not hold %s", tc.name, result, tc.exact)
+		}
+
+		// the bounds stay close to each other
+		if result.Hi-result.Lo > 1e-12*math.Abs(result.Hi) {
+			t.Errorf("%s: %s is wider than needed", tc.name, result)
+		}
+	}
+
+	exact, _ := ParseInterval("[0.5,0.75]")
+	assert.Equal(t, exact.Add(exact).String(), "[1,1.5]")
+
+	large, _ := ParseInterval("[1e308,1e308]")
+	assert.Equal(t, large.Add(large).Lo, math.MaxFloat64)
+	assert.Equal(t, math.IsInf(large.Add(large).Hi, 1), true)
+}
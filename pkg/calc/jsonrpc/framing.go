@@ -0,0 +1,81 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxMessage bounds the Content-Length a peer may announce
+const maxMessage = 1 << 20
+
+// errFraming is a header that cannot be parsed, the stream cannot be
+// resynchronized after it
+var errFraming = errors.New("invalid message header")
+
+// ReadMessage reads a message framed like the Language Server Protocol:
+// headers, Content-Length being required, an empty line and the body
+func ReadMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+
+			return nil, fmt.Errorf("%w: %v", errFraming, io.ErrUnexpectedEOF)
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errFraming, line)
+		}
+
+		// Content-Type is accepted and ignored, the body is always JSON
+		if !strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			continue
+		}
+
+		length, err = strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("%w: Content-Length %q", errFraming, strings.TrimSpace(value))
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("%w: missing Content-Length", errFraming)
+	}
+
+	if length > maxMessage {
+		return nil, fmt.Errorf("%w: Content-Length %d, at most %d", errFraming, length, maxMessage)
+	}
+
+	body := make([]byte, length)
+
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("%w: %v", errFraming, err)
+	}
+
+	return body, nil
+}
+
+// WriteMessage frames body with its Content-Length
+func WriteMessage(w io.Writer, body []byte) error {
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+
+	_, err := w.Write(body)
+
+	return err
+}
@@ -0,0 +1,517 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+)
+
+const Version = "2.0"
+
+// methods besides the operations, they take precedence over operations
+// with the same name
+const (
+	CapabilitiesMethod = "capabilities"
+	// CancelMethod is a notification with the id of the request to cancel,
+	// named as in the Language Server Protocol
+	CancelMethod = "$/cancelRequest"
+)
+
+// error codes, the reserved ones of the specification, RequestCancelled
+// of the Language Server Protocol and the calc errors
+const (
+	ParseError       = -32700
+	InvalidRequest   = -32600
+	MethodNotFound   = -32601
+	InvalidParams    = -32602
+	InternalError    = -32603
+	RequestCancelled = -32800
+	DivisionByZero   = -32001
+	Overflow         = -32002
+	Timeout          = -32003
+)
+
+// errInvalidParams are params that are neither the operands nor Params
+var errInvalidParams = errors.New("invalid params")
+
+type Request struct {
+	JSONRPC string `json:"jsonrpc"`
+	// ID is nil for notifications, which get no response
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type Response struct {
+	JSONRPC string `json:"jsonrpc"`
+	// ID is null when the id of the request could not be read
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// Data is the record of a failed operation
+	Data interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Params of the operations, the operands alone may be passed as an array
+type Params struct {
+	Operands []int `json:"operands"`
+	// Precision is wrap, the default, or checked to fail on overflow
+	Precision string `json:"precision,omitempty"`
+}
+
+type CancelParams struct {
+	ID json.RawMessage `json:"id"`
+}
+
+type OperationInfo struct {
+	Name        string `json:"name"`
+	Arity       int    `json:"arity"`
+	Description string `json:"description"`
+}
+
+// Capabilities is the result of the capabilities method
+type Capabilities struct {
+	Operations []OperationInfo `json:"operations"`
+	Methods    []string        `json:"methods"`
+	Precisions []string        `json:"precisions"`
+	Batch      bool            `json:"batch"`
+}
+
+type Options struct {
+	// Timeout of each evaluation, none when zero
+	Timeout time.Duration
+}
+
+// Server answers JSON-RPC requests calling the operations of a registry,
+// requests are evaluated concurrently and answered as they complete
+type Server struct {
+	registry *calc.Registry
+	opts     Options
+
+	mu      sync.Mutex
+	pending map[string]context.CancelFunc
+
+	out sync.Mutex
+}
+
+func New(registry *calc.Registry, opts Options) *Server {
+	return &Server{registry: registry, opts: opts, pending: map[string]context.CancelFunc{}}
+}
+
+// Serve reads framed messages from r and writes the responses to w until
+// r ends or ctx is done, then it waits for the requests in flight
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type message struct {
+		body []byte
+		err  error
+	}
+
+	messages := make(chan message)
+
+	// reads block on r, they are left behind when ctx is done
+	go func() {
+		reader := bufio.NewReader(r)
+
+		for {
+			body, err := ReadMessage(reader)
+
+			select {
+			case messages <- message{body: body, err: err}:
+			case <-ctx.Done():
+				return
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		var m message
+
+		select {
+		case m = <-messages:
+		case <-ctx.Done():
+			return nil
+		}
+
+		if errors.Is(m.err, io.EOF) {
+			return nil
+		}
+
+		if m.err != nil {
+			return m.err
+		}
+
+		s.dispatch(ctx, &wg, w, m.body)
+	}
+}
+
+// dispatch registers the requests of a message before evaluating them so
+// a cancellation read next always finds them
+func (s *Server) dispatch(ctx context.Context, wg *sync.WaitGroup, w io.Writer, body []byte) {
+	body = bytes.TrimSpace(body)
+
+	if len(body) == 0 || body[0] != '[' {
+		call := s.start(ctx, body)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if response := call(); response != nil {
+				s.write(w, response)
+			}
+		}()
+
+		return
+	}
+
+	var batch []json.RawMessage
+
+	if err := json.Unmarshal(body, &batch); err != nil {
+		s.write(w, failure(nil, ParseError, err.Error(), nil))
+		return
+	}
+
+	if len(batch) == 0 {
+		s.write(w, failure(nil, InvalidRequest, "empty batch", nil))
+		return
+	}
+
+	calls := make([]func() *Response, 0, len(batch))
+
+	for _, raw := range batch {
+		calls = append(calls, s.start(ctx, raw))
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		responses := make([]*Response, len(calls))
+
+		var batchWG sync.WaitGroup
+
+		for i, call := range calls {
+			batchWG.Add(1)
+
+			go func(i int, call func() *Response) {
+				defer batchWG.Done()
+
+				responses[i] = call()
+			}(i, call)
+		}
+
+		batchWG.Wait()
+
+		answered := make([]*Response, 0, len(responses))
+
+		for _, response := range responses {
+			if response != nil {
+				answered = append(answered, response)
+			}
+		}
+
+		// a batch of notifications gets no response at all
+		if len(answered) > 0 {
+			s.write(w, answered)
+		}
+	}()
+}
+
+// start decodes a request and returns the call evaluating it, the call
+// returns nil for notifications
+func (s *Server) start(ctx context.Context, raw []byte) func() *Response {
+	var request Request
+
+	if err := json.Unmarshal(raw, &request); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			return answer(failure(nil, ParseError, err.Error(), nil))
+		}
+
+		return answer(failure(nil, InvalidRequest, err.Error(), nil))
+	}
+
+	if request.JSONRPC != Version || request.Method == "" {
+		return answer(failure(request.ID, InvalidRequest, fmt.Sprintf("expected jsonrpc %q and a method", Version), nil))
+	}
+
+	if request.Method == CancelMethod {
+		s.cancel(request.Params)
+
+		return answer(nil)
+	}
+
+	id, tracked, err := pendingKey(request.ID)
+	if err != nil {
+		return answer(failure(nil, InvalidRequest, err.Error(), nil))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	if tracked {
+		s.mu.Lock()
+		_, duplicate := s.pending[id]
+		if !duplicate {
+			s.pending[id] = cancel
+		}
+		s.mu.Unlock()
+
+		if duplicate {
+			cancel()
+
+			return answer(failure(request.ID, InvalidRequest, fmt.Sprintf("request %s is already in flight", request.ID), nil))
+		}
+	}
+
+	return func() *Response {
+		defer func() {
+			cancel()
+
+			if tracked {
+				s.mu.Lock()
+				delete(s.pending, id)
+				s.mu.Unlock()
+			}
+		}()
+
+		result, err := s.call(ctx, request)
+
+		if request.ID == nil {
+			return nil
+		}
+
+		if err != nil {
+			var e *Error
+			if errors.As(err, &e) {
+				return failure(request.ID, e.Code, e.Message, e.Data)
+			}
+
+			return failure(request.ID, Code(err), err.Error(), result)
+		}
+
+		return &Response{JSONRPC: Version, ID: request.ID, Result: result}
+	}
+}
+
+func (s *Server) call(ctx context.Context, request Request) (interface{}, error) {
+	if request.Method == CapabilitiesMethod {
+		return s.capabilities(), nil
+	}
+
+	if _, ok := s.registry.Lookup(request.Method); !ok {
+		return nil, &Error{Code: MethodNotFound, Message: fmt.Sprintf("%v %q", calc.ErrUnknownOperation, request.Method)}
+	}
+
+	params, err := decodeParams(request.Params)
+	if err != nil {
+		return nil, &Error{Code: InvalidParams, Message: err.Error()}
+	}
+
+	return s.Eval(ctx, request.Method, params)
+}
+
+// Eval evaluates an operation, the record carries the error as well
+func (s *Server) Eval(ctx context.Context, name string, params Params) (render.Record, error) {
+	record := render.Record{Operation: name, Operands: []string{}, Precision: render.Wrap}
+
+	for _, operand := range params.Operands {
+		record.Operands = append(record.Operands, strconv.Itoa(operand))
+	}
+
+	switch params.Precision {
+	case "", render.Wrap:
+	case render.Checked:
+		ctx = calc.Checked(ctx)
+		record.Precision = render.Checked
+	default:
+		err := fmt.Errorf("%w: unknown precision %q, use %s or %s", errInvalidParams, params.Precision, render.Wrap, render.Checked)
+		record.Error = err.Error()
+
+		return record, err
+	}
+
+	if s.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, s.opts.Timeout)
+		defer cancel()
+	}
+
+	result, err := s.registry.Eval(ctx, name, params.Operands...)
+	if err != nil {
+		record.Error = err.Error()
+
+		return record, err
+	}
+
+	record.Result = strconv.Itoa(result)
+
+	return record, nil
+}
+
+func (s *Server) capabilities() Capabilities {
+	capabilities := Capabilities{
+		Operations: []OperationInfo{},
+		Methods:    []string{CapabilitiesMethod, CancelMethod},
+		Precisions: []string{render.Wrap, render.Checked},
+		Batch:      true,
+	}
+
+	for _, op := range s.registry.Operations() {
+		capabilities.Operations = append(capabilities.Operations, OperationInfo{
+			Name:        op.Name(),
+			Arity:       op.Arity(),
+			Description: op.Description(),
+		})
+		capabilities.Methods = append(capabilities.Methods, op.Name())
+	}
+
+	return capabilities
+}
+
+// cancel stops the request with the id in params, unknown or completed
+// requests are ignored as cancellation is a notification
+func (s *Server) cancel(params json.RawMessage) {
+	var cancel CancelParams
+
+	if err := json.Unmarshal(params, &cancel); err != nil || cancel.ID == nil {
+		return
+	}
+
+	id, tracked, err := pendingKey(cancel.ID)
+	if err != nil || !tracked {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stop, ok := s.pending[id]; ok {
+		stop()
+	}
+}
+
+// pendingKey normalises the id of a request so 1 and "1" are the same
+// request, tracked is false for notifications and null ids which cannot
+// be cancelled. Ids other than strings and numbers are invalid
+func pendingKey(raw json.RawMessage) (key string, tracked bool, err error) {
+	if raw == nil {
+		return "", false, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var id interface{}
+	if err := decoder.Decode(&id); err != nil {
+		return "", false, err
+	}
+
+	switch id := id.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return id, true, nil
+	case json.Number:
+		return id.String(), true, nil
+	}
+
+	return "", false, fmt.Errorf("id %s is neither a string nor a number", raw)
+}
+
+func (s *Server) write(w io.Writer, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		body, _ = json.Marshal(failure(nil, InternalError, err.Error(), nil))
+	}
+
+	s.out.Lock()
+	defer s.out.Unlock()
+
+	_ = WriteMessage(w, body)
+}
+
+// Code maps the errors of pkg/calc to JSON-RPC error codes
+func Code(err error) int {
+	switch {
+	case errors.Is(err, calc.ErrUnknownOperation):
+		return MethodNotFound
+	case errors.Is(err, errInvalidParams), errors.Is(err, calc.ErrInvalidOperand), errors.Is(err, calc.ErrArity):
+		return InvalidParams
+	case errors.Is(err, context.Canceled):
+		return RequestCancelled
+	case errors.Is(err, calc.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, calc.ErrDivisionByZero):
+		return DivisionByZero
+	case errors.Is(err, calc.ErrOverflow):
+		return Overflow
+	}
+
+	return InternalError
+}
+
+// decodeParams accepts [1, 2] as well as {"operands": [1, 2]}
+func decodeParams(raw json.RawMessage) (Params, error) {
+	var params Params
+
+	raw = bytes.TrimSpace(raw)
+
+	if len(raw) > 0 && raw[0] == '[' {
+		if err := json.Unmarshal(raw, &params.Operands); err != nil {
+			return params, fmt.Errorf("%w: %v", errInvalidParams, err)
+		}
+
+		return params, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&params); err != nil {
+		return params, fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+
+	return params, nil
+}
+
+func failure(id json.RawMessage, code int, message string, data interface{}) *Response {
+	return &Response{JSONRPC: Version, ID: id, Error: &Error{Code: code, Message: message, Data: data}}
+}
+
+func answer(response *Response) func() *Response {
+	return func() *Response {
+		return response
+	}
+}
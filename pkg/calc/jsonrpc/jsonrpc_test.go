@@ -0,0 +1,326 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+func frame(messages ...string) io.Reader {
+	var b bytes.Buffer
+
+	for _, message := range messages {
+		_ = WriteMessage(&b, []byte(message))
+	}
+
+	return &b
+}
+
+// serve runs a session over messages and returns the responses by id,
+// batches are returned under "batch"
+func serve(t *testing.T, s *Server, messages ...string) map[string]json.RawMessage {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	if err := s.Serve(context.Background(), frame(messages...), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := map[string]json.RawMessage{}
+	reader := bufio.NewReader(&out)
+
+	for {
+		body, err := ReadMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return responses
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if body[0] == '[' {
+			responses["batch"] = body
+			continue
+		}
+
+		var response Response
+		if err := json.Unmarshal(body, &response); err != nil {
+			t.Fatal(err)
+		}
+
+		responses[string(response.ID)] = body
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	type testCase struct {
+		input string
+		body  string
+		err   bool
+	}
+
+	cases := []testCase{
+		{input: "Content-Length: 2\r\n\r\n{}", body: "{}"},
+		{input: "content-length: 2\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n[]", body: "[]"},
+		{input: "Content-Type: application/json\r\n\r\n{}", err: true},
+		{input: "Content-Length: x\r\n\r\n{}", err: true},
+		{input: "Content-Length: 10\r\n\r\n{}", err: true},
+		{input: "garbage\r\n\r\n", err: true},
+	}
+
+	for _, tc := range cases {
+		body, err := ReadMessage(bufio.NewReader(strings.NewReader(tc.input)))
+
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", tc.input)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: %v", tc.input, err)
+			continue
+		}
+
+		assert.Equal(t, string(body), tc.body)
+	}
+
+	_, err := ReadMessage(bufio.NewReader(strings.NewReader("")))
+	assert.Equal(t, errors.Is(err, io.EOF), true)
+}
+
+func TestServe(t *testing.T) {
+	s := New(calc.Default, Options{})
+
+	responses := serve(t, s,
+		`{"jsonrpc": "2.0", "id": 1, "method": "sum", "params": [2, 3]}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "mul", "params": {"operands": [9223372036854775807, 2], "precision": "checked"}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "div", "params": [1, 0]}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "mod", "params": [1, 2]}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "sum", "params": [1]}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "sum", "params": {"values": [1, 2]}}`,
+		`{"jsonrpc": "2.0", "method": "sum", "params": [1, 2]}`,
+		`{"id": 7, "method": "sum"}`,
+		`{"jsonrpc": "2.0", "id": "eight", "method": "pow", "params": [2, 10]}`,
+		`{"jsonrpc": "2.0", "id": 9`,
+	)
+
+	type testCase struct {
+		id     string
+		result string
+		code   int
+	}
+
+	cases := []testCase{
+		{id: "1", result: "5"},
+		{id: "2", code: Overflow},
+		{id: "3", code: DivisionByZero},
+		{id: "4", code: MethodNotFound},
+		{id: "5", code: InvalidParams},
+		{id: "6", code: InvalidParams},
+		{id: "7", code: InvalidRequest},
+		{id: `"eight"`, result: "1024"},
+		{id: "null", code: ParseError},
+	}
+
+	// the notification got no response
+	assert.Equal(t, len(responses), len(cases))
+
+	for _, tc := range cases {
+		var response struct {
+			Result struct {
+				Result string `json:"result"`
+			} `json:"result"`
+			Error *Error `json:"error"`
+		}
+
+		if err := json.Unmarshal(responses[tc.id], &response); err != nil {
+			t.Errorf("%s: %v", tc.id, err)
+			continue
+		}
+
+		if tc.code != 0 {
+			if response.Error == nil {
+				t.Errorf("%s: expected error %d", tc.id, tc.code)
+				continue
+			}
+
+			assert.Equal(t, response.Error.Code, tc.code)
+			continue
+		}
+
+		assert.Equal(t, response.Result.Result, tc.result)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	responses := serve(t, New(calc.Default, Options{}), `{"jsonrpc": "2.0", "id": 1, "method": "capabilities"}`)
+
+	var response struct {
+		Result Capabilities `json:"result"`
+	}
+
+	if err := json.Unmarshal(responses["1"], &response); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(response.Result.Operations), len(calc.Operations()))
+	assert.Equal(t, response.Result.Batch, true)
+	assert.Equal(t, strings.Join(response.Result.Methods[:2], " "), CapabilitiesMethod+" "+CancelMethod)
+}
+
+func TestBatch(t *testing.T) {
+	responses := serve(t, New(calc.Default, Options{}),
+		`[
+			{"jsonrpc": "2.0", "id": 1, "method": "sum", "params": [1, 2]},
+			{"jsonrpc": "2.0", "method": "sum", "params": [1, 2]},
+			{"jsonrpc": "2.0", "id": 2, "method": "div", "params": [1, 0]}
+		]`,
+		`[]`,
+	)
+
+	var batch []Response
+	if err := json.Unmarshal(responses["batch"], &batch); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(batch), 2)
+	assert.Equal(t, string(batch[0].ID), "1")
+	assert.Equal(t, batch[0].Error == nil, true)
+	assert.Equal(t, batch[1].Error.Code, DivisionByZero)
+
+	var empty Response
+	if err := json.Unmarshal(responses["null"], &empty); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, empty.Error.Code, InvalidRequest)
+}
+
+func TestCancel(t *testing.T) {
+	registry := calc.NewRegistry()
+
+	block := calc.NewOperation("block", 0, "wait for the cancellation", func(ctx context.Context, operands ...int) (int, error) {
+		<-ctx.Done()
+
+		return 0, ctx.Err()
+	})
+
+	if err := registry.Register(block); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := serve(t, New(registry, Options{}),
+		`{"jsonrpc": "2.0", "id": 1, "method": "block", "params": []}`,
+		`{"jsonrpc": "2.0", "method": "$/cancelRequest", "params": {"id": 1}}`,
+	)
+
+	var response Response
+	if err := json.Unmarshal(responses["1"], &response); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, response.Error.Code, RequestCancelled)
+}
+
+func TestDuplicateID(t *testing.T) {
+	registry := calc.NewRegistry()
+
+	block := calc.NewOperation("block", 0, "wait for the cancellation", func(ctx context.Context, operands ...int) (int, error) {
+		<-ctx.Done()
+
+		return 0, ctx.Err()
+	})
+
+	if err := registry.Register(block); err != nil {
+		t.Fatal(err)
+	}
+
+	// "1" is the id of the request in flight, the cancellation by "1"
+	// stops it
+	responses := serve(t, New(registry, Options{}),
+		`{"jsonrpc": "2.0", "id": 1, "method": "block", "params": []}`,
+		`{"jsonrpc": "2.0", "id": "1", "method": "block", "params": []}`,
+		`{"jsonrpc": "2.0", "id": {"n": 2}, "method": "block", "params": []}`,
+		`{"jsonrpc": "2.0", "method": "$/cancelRequest", "params": {"id": "1"}}`,
+	)
+
+	type testCase struct {
+		id   string
+		code int
+	}
+
+	cases := []testCase{
+		{id: "1", code: RequestCancelled},
+		{id: `"1"`, code: InvalidRequest},
+		{id: "null", code: InvalidRequest},
+	}
+
+	assert.Equal(t, len(responses), len(cases))
+
+	for _, tc := range cases {
+		var response Response
+		if err := json.Unmarshal(responses[tc.id], &response); err != nil {
+			t.Errorf("%s: %v", tc.id, err)
+			continue
+		}
+
+		if response.Error == nil {
+			t.Errorf("%s: expected error %d", tc.id, tc.code)
+			continue
+		}
+
+		assert.Equal(t, response.Error.Code, tc.code)
+	}
+}
+
+func TestLargeExponent(t *testing.T) {
+	responses := serve(t, New(calc.Default, Options{Timeout: 100 * time.Millisecond}),
+		`{"jsonrpc": "2.0", "id": 1, "method": "pow", "params": [3, 200000000]}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "pow", "params": {"operands": [3, 200000000], "precision": "checked"}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "pow", "params": [1, 200000000]}`,
+	)
+
+	codes := map[string]int{"1": Timeout, "2": Overflow, "3": 0}
+
+	for id, code := range codes {
+		var response struct {
+			Result struct {
+				Result string `json:"result"`
+			} `json:"result"`
+			Error *Error `json:"error"`
+		}
+
+		if err := json.Unmarshal(responses[id], &response); err != nil {
+			t.Errorf("%s: %v", id, err)
+			continue
+		}
+
+		if code == 0 {
+			assert.Equal(t, response.Error == nil, true)
+			assert.Equal(t, response.Result.Result, "1")
+
+			continue
+		}
+
+		if response.Error == nil {
+			t.Errorf("%s: expected error %d", id, code)
+			continue
+		}
+
+		assert.Equal(t, response.Error.Code, code)
+	}
+}
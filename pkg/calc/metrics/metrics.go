@@ -0,0 +1,254 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+// ContentType is the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// bucket bounds of the histograms
+var (
+	durationBuckets = []float64{1e-6, 1e-5, 1e-4, 1e-3, 1e-2, 0.1, 1, 10}
+	bitsBuckets     = []float64{1, 8, 16, 32, 48, 64}
+)
+
+// Metrics is a calc.Observer counting the calls of the operations, it
+// writes them in the Prometheus text format
+type Metrics struct {
+	mu         sync.Mutex
+	operations map[string]*operation
+}
+
+type operation struct {
+	calls    uint64
+	errors   map[string]uint64
+	duration *histogram
+	bits     *histogram
+}
+
+type histogram struct {
+	bounds []float64
+	// counts are per bucket, the last one is +Inf
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// Default collects the metrics of calc serve and --metrics-file
+var Default = New()
+
+func New() *Metrics {
+	return &Metrics{operations: map[string]*operation{}}
+}
+
+func (m *Metrics) Observe(call calc.Call) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op, ok := m.operations[call.Op]
+	if !ok {
+		op = &operation{
+			errors:   map[string]uint64{},
+			duration: newHistogram(durationBuckets),
+			bits:     newHistogram(bitsBuckets),
+		}
+		m.operations[call.Op] = op
+	}
+
+	op.calls++
+	op.duration.observe(call.Duration.Seconds())
+
+	for _, bits := range call.OperandBits {
+		op.bits.observe(float64(bits))
+	}
+
+	if call.Err != nil {
+		op.errors[Reason(call.Err)]++
+	}
+}
+
+// Reason is the error label of a failed call
+func Reason(err error) string {
+	switch {
+	case errors.Is(err, calc.ErrDivisionByZero):
+		return "division_by_zero"
+	case errors.Is(err, calc.ErrOverflow):
+		return "overflow"
+	case errors.Is(err, calc.ErrInvalidOperand):
+		return "invalid_operand"
+	case errors.Is(err, calc.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+
+	return "other"
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.operations))
+
+	for name := range m.operations {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	counter := &countingWriter{w: bufio.NewWriter(w)}
+
+	header(counter, "calc_operations_total", "counter", "Calls of the calc operations.")
+
+	for _, name := range names {
+		fmt.Fprintf(counter, "calc_operations_total{operation=%s} %d\n", quote(name), m.operations[name].calls)
+	}
+
+	header(counter, "calc_operation_errors_total", "counter", "Failed calls of the calc operations by error.")
+
+	for _, name := range names {
+		reasons := make([]string, 0, len(m.operations[name].errors))
+
+		for reason := range m.operations[name].errors {
+			reasons = append(reasons, reason)
+		}
+
+		sort.Strings(reasons)
+
+		for _, reason := range reasons {
+			fmt.Fprintf(counter, "calc_operation_errors_total{operation=%s,error=%s} %d\n", quote(name), quote(reason), m.operations[name].errors[reason])
+		}
+	}
+
+	header(counter, "calc_operation_duration_seconds", "histogram", "Duration of the calls of the calc operations.")
+
+	for _, name := range names {
+		m.operations[name].duration.write(counter, "calc_operation_duration_seconds", name)
+	}
+
+	header(counter, "calc_operand_bits", "histogram", "Significant bits of the operands of the calc operations.")
+
+	for _, name := range names {
+		m.operations[name].bits.write(counter, "calc_operand_bits", name)
+	}
+
+	if err := counter.w.Flush(); err != nil {
+		return counter.n, err
+	}
+
+	return counter.n, counter.err
+}
+
+// ServeHTTP exposes the metrics to Prometheus, GET /metrics
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "use GET", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+
+	_, _ = m.WriteTo(w)
+}
+
+// WriteFile replaces path with the metrics, for the textfile collector of
+// the node exporter. The file is renamed into place so collectors never
+// read half of it
+func (m *Metrics) WriteFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := m.WriteTo(f); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (h *histogram) write(w io.Writer, name, op string) {
+	var cumulative uint64
+
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+
+		fmt.Fprintf(w, "%s_bucket{operation=%s,le=%s} %d\n", name, quote(op), quote(strconv.FormatFloat(bound, 'g', -1, 64)), cumulative)
+	}
+
+	fmt.Fprintf(w, "%s_bucket{operation=%s,le=\"+Inf\"} %d\n", name, quote(op), h.count)
+	fmt.Fprintf(w, "%s_sum{operation=%s} %s\n", name, quote(op), strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count{operation=%s} %d\n", name, quote(op), h.count)
+}
+
+func header(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote is a label value of the text format
+func quote(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+// countingWriter keeps the first error so the writes need no checks
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+
+	return n, err
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New()
+
+	m.Observe(calc.Call{Op: "sum", OperandBits: []int{2, 40}, Duration: 5 * time.Microsecond})
+	m.Observe(calc.Call{Op: "div", OperandBits: []int{3, 0}, Duration: 2 * time.Second, Err: calc.ErrDivisionByZero})
+	m.Observe(calc.Call{Op: "div", OperandBits: []int{3, 2}, Duration: time.Millisecond})
+	m.Observe(calc.Call{Op: "pow", OperandBits: []int{2, 27}, Err: context.DeadlineExceeded})
+	m.Observe(calc.Call{Op: `a"b`})
+
+	var b bytes.Buffer
+
+	n, err := m.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, n, int64(b.Len()))
+
+	for _, line := range []string{
+		"# TYPE calc_operations_total counter\n",
+		`calc_operations_total{operation="div"} 2` + "\n",
+		`calc_operations_total{operation="a\"b"} 1` + "\n",
+		`calc_operation_errors_total{operation="div",error="division_by_zero"} 1` + "\n",
+		`calc_operation_errors_total{operation="pow",error="timeout"} 1` + "\n",
+		"# TYPE calc_operation_duration_seconds histogram\n",
+		`calc_operation_duration_seconds_bucket{operation="div",le="0.001"} 1` + "\n",
+		`calc_operation_duration_seconds_bucket{operation="div",le="1"} 1` + "\n",
+		`calc_operation_duration_seconds_bucket{operation="div",le="+Inf"} 2` + "\n",
+		`calc_operation_duration_seconds_sum{operation="div"} 2.001` + "\n",
+		`calc_operation_duration_seconds_count{operation="div"} 2` + "\n",
+		`calc_operation_duration_seconds_bucket{operation="sum",le="1e-05"} 1` + "\n",
+		`calc_operand_bits_bucket{operation="sum",le="32"} 1` + "\n",
+		`calc_operand_bits_bucket{operation="sum",le="48"} 2` + "\n",
+		`calc_operand_bits_count{operation="sum"} 2` + "\n",
+	} {
+		assert.StringContains(t, b.String(), line)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	m := New()
+
+	calc.SetObserver(m)
+	defer calc.SetObserver(nil)
+
+	calc.Sum(1, 2)
+	_, _ = calc.Div(1, 0)
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("Content-Type"), ContentType)
+	assert.StringContains(t, w.Body.String(), `calc_operations_total{operation="sum"} 1`)
+	assert.StringContains(t, w.Body.String(), `calc_operation_errors_total{operation="div",error="division_by_zero"} 1`)
+}
+
+func TestWriteFile(t *testing.T) {
+	m := New()
+	m.Observe(calc.Call{Op: "mul", OperandBits: []int{1, 1}})
+
+	path := filepath.Join(t.TempDir(), "calc.prom")
+
+	if err := m.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.StringContains(t, string(content), `calc_operations_total{operation="mul"} 1`)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the temporary file was renamed
+	assert.Equal(t, len(entries), 1)
+}
@@ -0,0 +1,200 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+)
+
+// Money is an amount of minor units (cents for EUR) of a currency
+type Money struct {
+	Amount   int
+	Currency string
+}
+
+func NewMoney(amount int, currency string) (Money, error) {
+	if _, ok := CurrencyExponent(currency); !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+var moneyRegexp = regexp.MustCompile(`^([+-]?\d+(?:\.\d+)?)\s*([A-Za-z]{3})$`)
+
+// ParseMoney parses amounts such as 100.00EUR or 12 JPY, amounts with
+// more decimal digits than the currency has minor units are refused
+func ParseMoney(s string) (Money, error) {
+	match := moneyRegexp.FindStringSubmatch(strings.TrimSpace(s))
+
+	if match == nil {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	currency := strings.ToUpper(match[2])
+
+	exponent, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+
+	amount, _ := new(big.Rat).SetString(match[1])
+
+	minor := new(big.Rat).Mul(amount, new(big.Rat).SetInt(pow10(exponent)))
+
+	if !minor.IsInt() {
+		return Money{}, fmt.Errorf("amount %q has more than %d decimal digits for %s", s, exponent, currency)
+	}
+
+	if !fitsInt(minor.Num()) {
+		return Money{}, fmt.Errorf("amount %q out of range", s)
+	}
+
+	return Money{Amount: int(minor.Num().Int64()), Currency: currency}, nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	return money(new(big.Int).Add(big.NewInt(int64(m.Amount)), big.NewInt(int64(other.Amount))), m.Currency)
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	return money(new(big.Int).Sub(big.NewInt(int64(m.Amount)), big.NewInt(int64(other.Amount))), m.Currency)
+}
+
+// Multiply scales the amount by factor rounding half to even
+func (m Money) Multiply(factor *big.Rat) (Money, error) {
+	return m.MultiplyRounding(factor, HalfEven)
+}
+
+// MultiplyRounding scales the amount by factor rounding to a minor unit
+// with rounding
+func (m Money) MultiplyRounding(factor *big.Rat, rounding Rounding) (Money, error) {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m.Amount)), factor)
+
+	return money(rounding.round(product), m.Currency)
+}
+
+// money is an amount of minor units computed exactly, ErrOverflow when it
+// doesn't fit an int
+func money(minor *big.Int, currency string) (Money, error) {
+	if !fitsInt(minor) {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{Amount: int(minor.Int64()), Currency: currency}, nil
+}
+
+var (
+	minInt = big.NewInt(math.MinInt)
+	maxInt = big.NewInt(math.MaxInt)
+)
+
+// fitsInt reports whether n is in the range of int, which is narrower
+// than int64 on 32-bit platforms
+func fitsInt(n *big.Int) bool {
+	return n.Cmp(minInt) >= 0 && n.Cmp(maxInt) <= 0
+}
+
+// Split divides the amount in n parts that differ by at most one minor unit
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("cannot split in %d parts", n)
+	}
+
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+
+	return m.Allocate(ratios...)
+}
+
+// Allocate divides the amount proportionally to ratios with the largest
+// remainder method, the parts always add up to the original amount
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	total := big.NewInt(0)
+
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("negative ratio %d", ratio)
+		}
+
+		total.Add(total, big.NewInt(int64(ratio)))
+	}
+
+	if total.Sign() == 0 {
+		return nil, errors.New("ratios must not all be zero")
+	}
+
+	// allocate the absolute amount, the sign is restored at the end
+	amount := big.NewInt(int64(m.Amount))
+	amount.Abs(amount)
+
+	type share struct {
+		index     int
+		remainder *big.Int
+	}
+
+	parts := make([]int, len(ratios))
+	shares := make([]share, len(ratios))
+	allocated := 0
+
+	for i, ratio := range ratios {
+		quota, remainder := new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(int64(ratio))), total, new(big.Int))
+
+		parts[i] = int(quota.Int64())
+		shares[i] = share{index: i, remainder: remainder}
+		allocated = Sum(allocated, parts[i])
+	}
+
+	sort.SliceStable(shares, func(i, j int) bool {
+		return shares[i].remainder.Cmp(shares[j].remainder) > 0
+	})
+
+	leftover := Sub(int(amount.Int64()), allocated)
+
+	for i := 0; i < leftover; i++ {
+		parts[shares[i].index] = Sum(parts[shares[i].index], 1)
+	}
+
+	allocation := make([]Money, len(parts))
+
+	for i, part := range parts {
+		if m.Amount < 0 {
+			part = -part
+		}
+
+		allocation[i] = Money{Amount: part, Currency: m.Currency}
+	}
+
+	return allocation, nil
+}
+
+func (m Money) String() string {
+	exponent, _ := CurrencyExponent(m.Currency)
+
+	amount := new(big.Rat).SetFrac(big.NewInt(int64(m.Amount)), pow10(exponent))
+
+	return amount.FloatString(exponent) + m.Currency
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
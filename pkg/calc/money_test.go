@@ -0,0 +1,281 @@
+package calc
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestRoundHalfEven(t *testing.T) {
+	type testCase struct {
+		value   string
+		places  int
+		rounded string
+	}
+
+	cases := []testCase{
+		{
+			value:   "2.5",
+			places:  0,
+			rounded: "2",
+		},
+		{
+			value:   "3.5",
+			places:  0,
+			rounded: "4",
+		},
+		{
+			value:   "-2.5",
+			places:  0,
+			rounded: "-2",
+		},
+		{
+			value:   "1.005",
+			places:  2,
+			rounded: "1.00",
+		},
+		{
+			value:   "1.015",
+			places:  2,
+			rounded: "1.02",
+		},
+		{
+			value:   "1.0151",
+			places:  2,
+			rounded: "1.02",
+		},
+	}
+
+	for _, tc := range cases {
+		value, _ := new(big.Rat).SetString(tc.value)
+
+		assert.Equal(t, RoundHalfEven(value, tc.places).FloatString(tc.places), tc.rounded)
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	for input, expected := range map[string]string{"9.99": "999/100", "-12": "-12", "+0.50": "1/2"} {
+		x, err := ParseDecimal(input)
+		if err != nil {
+			t.Errorf("%q: %v", input, err)
+			continue
+		}
+
+		assert.Equal(t, x.RatString(), expected)
+	}
+
+	for _, invalid := range []string{"1/3", "1e3", ".5", "x"} {
+		if _, err := ParseDecimal(invalid); !errors.Is(err, ErrInvalidOperand) {
+			t.Errorf("%q: expected an invalid operand, got %v", invalid, err)
+		}
+	}
+}
+
+func TestMoneyRounding(t *testing.T) {
+	amount := Money{Amount: 1005, Currency: "EUR"}
+
+	halves := map[Rounding]string{HalfUp: "5.03EUR", Down: "5.02EUR", HalfEven: "5.02EUR"}
+
+	for rounding, expected := range halves {
+		half, err := amount.MultiplyRounding(big.NewRat(1, 2), rounding)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, half.String(), expected)
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	type testCase struct {
+		input string
+		money Money
+		ok    bool
+	}
+
+	cases := []testCase{
+		{
+			input: "100.00EUR",
+			money: Money{Amount: 10000, Currency: "EUR"},
+			ok:    true,
+		},
+		{
+			input: "12 jpy",
+			money: Money{Amount: 12, Currency: "JPY"},
+			ok:    true,
+		},
+		{
+			input: "1.234KWD",
+			money: Money{Amount: 1234, Currency: "KWD"},
+			ok:    true,
+		},
+		{
+			input: "1.2345KWD",
+			ok:    false,
+		},
+		{
+			input: "1.5JPY",
+			ok:    false,
+		},
+		{
+			input: "-0.5USD",
+			money: Money{Amount: -50, Currency: "USD"},
+			ok:    true,
+		},
+		{
+			// one cent more than math.MaxInt on 64-bit platforms
+			input: "92233720368547758.08EUR",
+			ok:    false,
+		},
+		{
+			input: "10XXX",
+			ok:    false,
+		},
+		{
+			input: "EUR",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		money, err := ParseMoney(tc.input)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error for %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, money, tc.money)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	first, _ := ParseMoney("10.50EUR")
+	second, _ := ParseMoney("0.75EUR")
+
+	sum, err := first.Add(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, sum.String(), "11.25EUR")
+
+	sub, err := second.Sub(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, sub.String(), "-9.75EUR")
+
+	dollars, _ := ParseMoney("1.00USD")
+
+	_, err = first.Add(dollars)
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch, got %v", err)
+	}
+
+	// 0.125EUR rounds to the even cent
+	vat, err := first.Multiply(big.NewRat(1, 84))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, vat.String(), "0.12EUR")
+}
+
+func TestMoneyOverflow(t *testing.T) {
+	large, _ := ParseMoney("90000000000000000.00EUR")
+	cent, _ := ParseMoney("0.01EUR")
+
+	_, err := large.Multiply(big.NewRat(1000, 1))
+	assert.Equal(t, errors.Is(err, ErrOverflow), true)
+
+	_, err = large.Add(large)
+	assert.Equal(t, errors.Is(err, ErrOverflow), true)
+
+	_, err = Money{Amount: math.MinInt, Currency: "EUR"}.Sub(cent)
+	assert.Equal(t, errors.Is(err, ErrOverflow), true)
+
+	sum, err := large.Add(cent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, sum.String(), "90000000000000000.01EUR")
+}
+
+func TestFitsInt(t *testing.T) {
+	one := big.NewInt(1)
+
+	assert.Equal(t, fitsInt(big.NewInt(math.MaxInt)), true)
+	assert.Equal(t, fitsInt(big.NewInt(math.MinInt)), true)
+	assert.Equal(t, fitsInt(new(big.Int).Add(big.NewInt(math.MaxInt), one)), false)
+	assert.Equal(t, fitsInt(new(big.Int).Sub(big.NewInt(math.MinInt), one)), false)
+}
+
+func TestMoneyAllocate(t *testing.T) {
+	type testCase struct {
+		amount     string
+		ratios     []int
+		allocation string
+	}
+
+	cases := []testCase{
+		{
+			amount:     "100.00EUR",
+			ratios:     []int{1, 1, 1},
+			allocation: "33.34EUR 33.33EUR 33.33EUR",
+		},
+		{
+			amount:     "0.05EUR",
+			ratios:     []int{3, 7},
+			allocation: "0.02EUR 0.03EUR",
+		},
+		{
+			amount:     "-100.00EUR",
+			ratios:     []int{1, 1, 1},
+			allocation: "-33.34EUR -33.33EUR -33.33EUR",
+		},
+		{
+			amount:     "10JPY",
+			ratios:     []int{1, 2, 0},
+			allocation: "3JPY 7JPY 0JPY",
+		},
+	}
+
+	for _, tc := range cases {
+		amount, _ := ParseMoney(tc.amount)
+
+		allocation, err := amount.Allocate(tc.ratios...)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		parts := []string{}
+		for _, part := range allocation {
+			parts = append(parts, part.String())
+		}
+
+		assert.Equal(t, strings.Join(parts, " "), tc.allocation)
+	}
+
+	amount, _ := ParseMoney("1.00EUR")
+
+	if _, err := amount.Split(0); err == nil {
+		t.Error("expected split error")
+	}
+
+	if _, err := amount.Allocate(0, 0); err == nil {
+		t.Error("expected allocation error")
+	}
+}
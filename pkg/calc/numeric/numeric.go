@@ -0,0 +1,278 @@
+package numeric
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Func is a real function of a real variable
+type Func func(x float64) float64
+
+type Opts struct {
+	// Tolerance is the absolute error the result must be within
+	Tolerance float64
+	// MaxIterations bounds the work done before giving up
+	MaxIterations int
+}
+
+var defaultOpts = Opts{
+	Tolerance:     1e-12,
+	MaxIterations: 100,
+}
+
+func (opts Opts) withDefaults() Opts {
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = defaultOpts.Tolerance
+	}
+
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = defaultOpts.MaxIterations
+	}
+
+	return opts
+}
+
+// Result carries the convergence diagnostics of a method
+type Result struct {
+	Value         float64
+	Iterations    int
+	ErrorEstimate float64
+}
+
+// ConvergenceError is returned when a method runs out of iterations
+// before reaching the tolerance
+type ConvergenceError struct {
+	Method        string
+	Iterations    int
+	ErrorEstimate float64
+	Tolerance     float64
+}
+
+func (e *ConvergenceError) Error() string {
+	return fmt.Sprintf("%s did not converge in %d iterations: error estimate %g above tolerance %g", e.Method, e.Iterations, e.ErrorEstimate, e.Tolerance)
+}
+
+var (
+	ErrNotBracketed   = errors.New("the interval does not bracket a root")
+	ErrZeroDerivative = errors.New("zero derivative")
+)
+
+// Bisection halves [lo, hi] keeping the half where f changes sign
+func Bisection(f Func, lo, hi float64, opts Opts) (Result, error) {
+	opts = opts.withDefaults()
+
+	flo, fhi := f(lo), f(hi)
+
+	switch {
+	case flo == 0:
+		return Result{Value: lo}, nil
+	case fhi == 0:
+		return Result{Value: hi}, nil
+	case math.Signbit(flo) == math.Signbit(fhi):
+		return Result{}, ErrNotBracketed
+	}
+
+	for i := 1; i <= opts.MaxIterations; i++ {
+		mid := lo + (hi-lo)/2
+		fmid := f(mid)
+
+		if fmid == 0 || (hi-lo)/2 <= opts.Tolerance {
+			return Result{Value: mid, Iterations: i, ErrorEstimate: (hi - lo) / 2}, nil
+		}
+
+		if math.Signbit(fmid) == math.Signbit(flo) {
+			lo, flo = mid, fmid
+		} else {
+			hi = mid
+		}
+	}
+
+	return Result{}, &ConvergenceError{Method: "bisection", Iterations: opts.MaxIterations, ErrorEstimate: (hi - lo) / 2, Tolerance: opts.Tolerance}
+}
+
+// Newton follows the tangent of f from x0
+func Newton(f, df Func, x0 float64, opts Opts) (Result, error) {
+	opts = opts.withDefaults()
+
+	x := x0
+	step := math.Inf(1)
+
+	for i := 1; i <= opts.MaxIterations; i++ {
+		slope := df(x)
+
+		if slope == 0 {
+			return Result{}, ErrZeroDerivative
+		}
+
+		step = f(x) / slope
+		x -= step
+
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			break
+		}
+
+		if math.Abs(step) <= opts.Tolerance {
+			return Result{Value: x, Iterations: i, ErrorEstimate: math.Abs(step)}, nil
+		}
+	}
+
+	return Result{}, &ConvergenceError{Method: "newton", Iterations: opts.MaxIterations, ErrorEstimate: math.Abs(step), Tolerance: opts.Tolerance}
+}
+
+// Brent combines bisection, secant and inverse quadratic interpolation,
+// it needs a bracketing interval like bisection but converges faster
+func Brent(f Func, lo, hi float64, opts Opts) (Result, error) {
+	opts = opts.withDefaults()
+
+	a, b := lo, hi
+	fa, fb := f(a), f(b)
+
+	switch {
+	case fa == 0:
+		return Result{Value: a}, nil
+	case fb == 0:
+		return Result{Value: b}, nil
+	case math.Signbit(fa) == math.Signbit(fb):
+		return Result{}, ErrNotBracketed
+	}
+
+	// b is the best estimate, keep |f(b)| <= |f(a)|
+	if math.Abs(fa) < math.Abs(fb) {
+		a, b, fa, fb = b, a, fb, fa
+	}
+
+	c, fc := a, fa
+	d := b - a
+	bisected := true
+
+	for i := 1; i <= opts.MaxIterations; i++ {
+		var s float64
+
+		if fa != fc && fb != fc {
+			// inverse quadratic interpolation
+			s = a*fb*fc/((fa-fb)*(fa-fc)) + b*fa*fc/((fb-fa)*(fb-fc)) + c*fa*fb/((fc-fa)*(fc-fb))
+		} else {
+			// secant
+			s = b - fb*(b-a)/(fb-fa)
+		}
+
+		tolerance := 2 * opts.Tolerance
+
+		lowest, highest := (3*a+b)/4, b
+		if lowest > highest {
+			lowest, highest = highest, lowest
+		}
+
+		if s < lowest || s > highest ||
+			(bisected && math.Abs(s-b) >= math.Abs(b-c)/2) ||
+			(!bisected && math.Abs(s-b) >= math.Abs(c-d)/2) ||
+			(bisected && math.Abs(b-c) < tolerance) ||
+			(!bisected && math.Abs(c-d) < tolerance) {
+			s = (a + b) / 2
+			bisected = true
+		} else {
+			bisected = false
+		}
+
+		fs := f(s)
+		d, c, fc = c, b, fb
+
+		if math.Signbit(fa) == math.Signbit(fs) {
+			a, fa = s, fs
+		} else {
+			b, fb = s, fs
+		}
+
+		if math.Abs(fa) < math.Abs(fb) {
+			a, b, fa, fb = b, a, fb, fa
+		}
+
+		if fb == 0 || math.Abs(b-a) <= opts.Tolerance {
+			return Result{Value: b, Iterations: i, ErrorEstimate: math.Abs(b - a)}, nil
+		}
+	}
+
+	return Result{}, &ConvergenceError{Method: "brent", Iterations: opts.MaxIterations, ErrorEstimate: math.Abs(b - a), Tolerance: opts.Tolerance}
+}
+
+// simpson applies the composite Simpson rule with n (even) subintervals
+func simpson(f Func, a, b float64, n int) float64 {
+	h := (b - a) / float64(n)
+
+	sum := f(a) + f(b)
+
+	for i := 1; i < n; i++ {
+		weight := 4.0
+		if i%2 == 0 {
+			weight = 2
+		}
+
+		sum += weight * f(a+float64(i)*h)
+	}
+
+	return sum * h / 3
+}
+
+// Simpson doubles the subintervals of the composite Simpson rule until
+// two successive estimates agree, the error is estimated with Richardson
+func Simpson(f Func, a, b float64, opts Opts) (Result, error) {
+	opts = opts.withDefaults()
+
+	n := 2
+	previous := simpson(f, a, b, n)
+	estimate := math.Inf(1)
+
+	for i := 1; i <= opts.MaxIterations; i++ {
+		n *= 2
+		current := simpson(f, a, b, n)
+
+		estimate = math.Abs(current-previous) / 15
+
+		if estimate <= opts.Tolerance {
+			return Result{Value: current, Iterations: i, ErrorEstimate: estimate}, nil
+		}
+
+		previous = current
+	}
+
+	return Result{}, &ConvergenceError{Method: "simpson", Iterations: opts.MaxIterations, ErrorEstimate: estimate, Tolerance: opts.Tolerance}
+}
+
+// Romberg extrapolates the trapezoidal rule with Richardson's method
+func Romberg(f Func, a, b float64, opts Opts) (Result, error) {
+	opts = opts.withDefaults()
+
+	h := b - a
+	previous := []float64{h / 2 * (f(a) + f(b))}
+	estimate := math.Inf(1)
+
+	for i := 1; i <= opts.MaxIterations; i++ {
+		h /= 2
+
+		// trapezoid refinement only needs the new midpoints
+		var sum float64
+		for k := 1; k < 1<<i; k += 2 {
+			sum += f(a + float64(k)*h)
+		}
+
+		current := make([]float64, i+1)
+		current[0] = previous[0]/2 + h*sum
+
+		factor := 1.0
+		for j := 1; j <= i; j++ {
+			factor *= 4
+			current[j] = current[j-1] + (current[j-1]-previous[j-1])/(factor-1)
+		}
+
+		estimate = math.Abs(current[i] - previous[i-1])
+
+		if estimate <= opts.Tolerance {
+			return Result{Value: current[i], Iterations: i, ErrorEstimate: estimate}, nil
+		}
+
+		previous = current
+	}
+
+	return Result{}, &ConvergenceError{Method: "romberg", Iterations: opts.MaxIterations, ErrorEstimate: estimate, Tolerance: opts.Tolerance}
+}
@@ -0,0 +1,122 @@
+package numeric
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// x^2 - 2
+func square(x float64) float64 {
+	return x*x - 2
+}
+
+func squareDerivative(x float64) float64 {
+	return 2 * x
+}
+
+func TestRoots(t *testing.T) {
+	type testCase struct {
+		method string
+		find   func() (Result, error)
+	}
+
+	cases := []testCase{
+		{
+			method: "bisection",
+			find: func() (Result, error) {
+				return Bisection(square, 0, 2, Opts{})
+			},
+		},
+		{
+			method: "newton",
+			find: func() (Result, error) {
+				return Newton(square, squareDerivative, 1, Opts{})
+			},
+		},
+		{
+			method: "brent",
+			find: func() (Result, error) {
+				return Brent(square, 0, 2, Opts{})
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		result, err := tc.find()
+		if err != nil {
+			t.Errorf("%s: %v", tc.method, err)
+			continue
+		}
+
+		if math.Abs(result.Value-math.Sqrt2) > 1e-10 {
+			t.Errorf("%s: expected %v got %v", tc.method, math.Sqrt2, result.Value)
+		}
+
+		if result.Iterations == 0 {
+			t.Errorf("%s: expected iterations to be reported", tc.method)
+		}
+	}
+}
+
+func TestRootErrors(t *testing.T) {
+	if _, err := Bisection(square, 2, 3, Opts{}); !errors.Is(err, ErrNotBracketed) {
+		t.Errorf("expected ErrNotBracketed got %v", err)
+	}
+
+	if _, err := Brent(square, -1, 1, Opts{}); !errors.Is(err, ErrNotBracketed) {
+		t.Errorf("expected ErrNotBracketed got %v", err)
+	}
+
+	if _, err := Newton(square, squareDerivative, 0, Opts{}); !errors.Is(err, ErrZeroDerivative) {
+		t.Errorf("expected ErrZeroDerivative got %v", err)
+	}
+
+	_, err := Bisection(square, 0, 2, Opts{Tolerance: 1e-12, MaxIterations: 5})
+
+	var convergence *ConvergenceError
+	if !errors.As(err, &convergence) {
+		t.Fatalf("expected a ConvergenceError got %v", err)
+	}
+
+	if convergence.Iterations != 5 || convergence.ErrorEstimate <= convergence.Tolerance {
+		t.Errorf("unexpected diagnostics %+v", convergence)
+	}
+}
+
+func TestIntegrals(t *testing.T) {
+	type testCase struct {
+		method    string
+		integrate func(f Func, a, b float64, opts Opts) (Result, error)
+	}
+
+	cases := []testCase{
+		{
+			method:    "simpson",
+			integrate: Simpson,
+		},
+		{
+			method:    "romberg",
+			integrate: Romberg,
+		},
+	}
+
+	for _, tc := range cases {
+		result, err := tc.integrate(math.Sin, 0, math.Pi, Opts{Tolerance: 1e-10})
+		if err != nil {
+			t.Errorf("%s: %v", tc.method, err)
+			continue
+		}
+
+		if math.Abs(result.Value-2) > 1e-9 {
+			t.Errorf("%s: expected 2 got %v", tc.method, result.Value)
+		}
+
+		_, err = tc.integrate(math.Exp, 0, 10, Opts{Tolerance: 1e-12, MaxIterations: 2})
+
+		var convergence *ConvergenceError
+		if !errors.As(err, &convergence) {
+			t.Errorf("%s: expected a ConvergenceError got %v", tc.method, err)
+		}
+	}
+}
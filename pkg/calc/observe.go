@@ -0,0 +1,71 @@
+package calc
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Call is a completed call of an operation
+type Call struct {
+	Op string
+	// OperandBits are the significant bits of the magnitude of each operand
+	OperandBits []int
+	Duration    time.Duration
+	// Err is nil for successful calls
+	Err error
+}
+
+// Observer receives every call of the operations, calls made by other
+// operations are not reported. Observers may be called concurrently
+type Observer interface {
+	Observe(call Call)
+}
+
+// ObserverFunc is a function used as an Observer
+type ObserverFunc func(call Call)
+
+func (f ObserverFunc) Observe(call Call) {
+	f(call)
+}
+
+// observer is read by every call, atomically so SetObserver is safe
+// while serve computes concurrently
+var observer atomic.Pointer[Observer]
+
+// SetObserver installs the observer of every following call, nil turns
+// observing off
+func SetObserver(o Observer) {
+	if o == nil {
+		observer.Store(nil)
+
+		return
+	}
+
+	observer.Store(&o)
+}
+
+// currentObserver is the installed observer, nil when observing is off
+func currentObserver() Observer {
+	if o := observer.Load(); o != nil {
+		return *o
+	}
+
+	return nil
+}
+
+// observe reports a call started at start to o, err points to its error,
+// nil for operations that cannot fail. The operations load the observer
+// first so they pay for the clock only while observed
+func observe(o Observer, op string, start time.Time, err *error, operands ...int) {
+	call := Call{Op: op, OperandBits: make([]int, 0, len(operands)), Duration: time.Since(start)}
+
+	for _, operand := range operands {
+		call.OperandBits = append(call.OperandBits, bits(magnitude(operand)))
+	}
+
+	if err != nil {
+		call.Err = *err
+	}
+
+	o.Observe(call)
+}
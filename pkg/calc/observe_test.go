@@ -0,0 +1,71 @@
+package calc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestObserver(t *testing.T) {
+	type testCase struct {
+		op    func()
+		calls []string
+	}
+
+	cases := []testCase{
+		{op: func() { Sum(3, 5) }, calls: []string{"sum [2 3] <nil>"}},
+		{op: func() { Sub(-1, 0) }, calls: []string{"sub [1 0] <nil>"}},
+		{op: func() { _, _ = MulContext(Checked(context.Background()), 1<<62, 4) }, calls: []string{"mul [63 3] integer overflow"}},
+		{op: func() { _, _ = Div(7, 0) }, calls: []string{"div [3 0] division by zero"}},
+		// the multiplications of pow are not reported
+		{op: func() { Pow(2, 10) }, calls: []string{"pow [2 4] <nil>"}},
+		{op: func() { _, _ = Default.Eval(context.Background(), "sum", 1, 2) }, calls: []string{"sum [1 2] <nil>"}},
+	}
+
+	defer SetObserver(nil)
+
+	for _, tc := range cases {
+		calls := []string{}
+
+		SetObserver(ObserverFunc(func(call Call) {
+			if call.Duration < 0 {
+				t.Errorf("%s: negative duration %s", call.Op, call.Duration)
+			}
+
+			calls = append(calls, fmt.Sprintf("%s %v %v", call.Op, call.OperandBits, call.Err))
+		}))
+
+		tc.op()
+
+		assert.Equal(t, strings.Join(calls, "\n"), strings.Join(tc.calls, "\n"))
+	}
+}
+
+// TestSetObserverConcurrently is meant for go test -race, operations
+// read the observer while it is replaced
+func TestSetObserverConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				Sum(j, 1)
+			}
+		}()
+	}
+
+	for j := 0; j < 100; j++ {
+		SetObserver(ObserverFunc(func(call Call) {}))
+		SetObserver(nil)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache keeps the handshakes of the plugins in a file so calc registers
+// their operations at startup without running every plugin, an entry is
+// reused while its executable keeps its size and modification time. The
+// zero Cache keeps nothing
+type Cache struct {
+	Path string
+}
+
+type entry struct {
+	Size       int64     `json:"size"`
+	Modified   time.Time `json:"modified"`
+	Operations []Spec    `json:"operations,omitempty"`
+	Error      string    `json:"error,omitempty"`
+
+	// the handshake error of this run, cached entries only keep its message
+	err error
+}
+
+// DefaultCache is plugins.json in the calc directory of the user cache
+func DefaultCache() Cache {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return Cache{}
+	}
+
+	return Cache{Path: filepath.Join(dir, "calc", "plugins.json")}
+}
+
+// Discover is the package Discover reusing the cached handshakes of the
+// executables that did not change since
+func (c Cache) Discover(ctx context.Context, dirs []string) ([]Plugin, []error) {
+	return c.discover(ctx, dirs, c.load())
+}
+
+// Refresh is the package Discover saving the handshakes to the cache
+func (c Cache) Refresh(ctx context.Context, dirs []string) ([]Plugin, []error) {
+	return c.discover(ctx, dirs, map[string]entry{})
+}
+
+func (c Cache) discover(ctx context.Context, dirs []string, cached map[string]entry) ([]Plugin, []error) {
+	found := candidates(dirs)
+	entries := make([]entry, len(found))
+
+	// the cache is rewritten when a handshake ran or a plugin went away
+	changed := len(cached) != len(found)
+
+	var wg sync.WaitGroup
+
+	for i, plugin := range found {
+		if e, ok := cached[plugin.path]; ok && e.Size == plugin.info.Size() && e.Modified.Equal(plugin.info.ModTime()) {
+			entries[i] = e
+			continue
+		}
+
+		changed = true
+
+		wg.Add(1)
+
+		go func(i int, plugin candidate) {
+			defer wg.Done()
+
+			e := entry{Size: plugin.info.Size(), Modified: plugin.info.ModTime()}
+
+			p, err := Describe(ctx, plugin.path)
+			if err != nil {
+				e.Error, e.err = err.Error(), err
+			}
+
+			e.Operations = p.Operations
+			entries[i] = e
+		}(i, plugin)
+	}
+
+	wg.Wait()
+
+	plugins := []Plugin{}
+	errs := []error{}
+	saved := map[string]entry{}
+
+	for i, plugin := range found {
+		e := entries[i]
+		saved[plugin.path] = e
+
+		switch {
+		case e.err != nil:
+			errs = append(errs, e.err)
+		case e.Error != "":
+			errs = append(errs, errors.New(e.Error))
+		default:
+			plugins = append(plugins, Plugin{Path: plugin.path, Operations: e.Operations})
+		}
+	}
+
+	// handshakes cut short by ctx say nothing about the plugins
+	if changed && ctx.Err() == nil {
+		_ = c.save(saved)
+	}
+
+	return plugins, errs
+}
+
+func (c Cache) load() map[string]entry {
+	entries := map[string]entry{}
+
+	if c.Path == "" {
+		return entries
+	}
+
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return entries
+	}
+
+	// a damaged cache is rebuilt from the handshakes
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return map[string]entry{}
+	}
+
+	return entries
+}
+
+// save replaces the cache file in one rename, so concurrent calc
+// processes never read a partial file
+func (c Cache) save(entries map[string]entry) error {
+	if c.Path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(c.Path), filepath.Base(c.Path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.Path)
+}
@@ -0,0 +1,248 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+// a plugin slower than this to answer the handshake is skipped, the
+// handshakes run concurrently so discovering the plugins takes at most as
+// long
+const describeTimeout = 2 * time.Second
+
+// Plugin is an executable that passed the handshake
+type Plugin struct {
+	Path       string
+	Operations []Spec
+}
+
+// Dirs are searched in order: the calc config directory first, then PATH
+func Dirs() []string {
+	dirs := []string{}
+
+	if config := configDir(); config != "" {
+		dirs = append(dirs, filepath.Join(config, "calc", "plugins"))
+	}
+
+	return append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+}
+
+func configDir() string {
+	if config := os.Getenv("XDG_CONFIG_HOME"); config != "" {
+		return config
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config")
+}
+
+// Discover runs the handshake of every calc-* executable in dirs, the
+// first executable with a given name wins like on PATH. Plugins failing
+// the handshake are reported in the errors and left out
+func Discover(ctx context.Context, dirs []string) ([]Plugin, []error) {
+	return Cache{}.Refresh(ctx, dirs)
+}
+
+// candidate is a calc-* executable found in the plugin directories
+type candidate struct {
+	path string
+	info os.FileInfo
+}
+
+func candidates(dirs []string) []candidate {
+	found := []candidate{}
+
+	seen := map[string]bool{}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+
+			if !strings.HasPrefix(name, Prefix) || seen[name] {
+				continue
+			}
+
+			info, ok := executable(filepath.Join(dir, name))
+			if !ok {
+				continue
+			}
+
+			seen[name] = true
+
+			found = append(found, candidate{path: filepath.Join(dir, name), info: info})
+		}
+	}
+
+	return found
+}
+
+func executable(path string) (os.FileInfo, bool) {
+	info, err := os.Stat(path)
+
+	return info, err == nil && !info.IsDir() && info.Mode()&0o111 != 0
+}
+
+// Describe runs the handshake of the plugin at path
+func Describe(ctx context.Context, path string) (Plugin, error) {
+	ctx, cancel := context.WithTimeout(ctx, describeTimeout)
+	defer cancel()
+
+	response, err := call(ctx, path, Request{Protocol: ProtocolVersion, Method: MethodDescribe})
+	if err != nil {
+		return Plugin{}, err
+	}
+
+	for _, spec := range response.Operations {
+		if !calc.ValidName(spec.Name) || spec.Arity < 0 {
+			return Plugin{}, fmt.Errorf("plugin %s: invalid operation %+v", filepath.Base(path), spec)
+		}
+	}
+
+	return Plugin{Path: path, Operations: response.Operations}, nil
+}
+
+// Eval asks the plugin to evaluate operation
+func (p Plugin) Eval(ctx context.Context, operation string, operands []string) (string, error) {
+	response, err := call(ctx, p.Path, Request{Protocol: ProtocolVersion, Method: MethodEval, Operation: operation, Operands: operands})
+	if err != nil {
+		return "", err
+	}
+
+	return response.Result, nil
+}
+
+func call(ctx context.Context, path string, request Request) (Response, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := run(ctx, cmd); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return Response{}, fmt.Errorf("plugin %s: %w: %s", filepath.Base(path), err, message)
+		}
+
+		return Response{}, fmt.Errorf("plugin %s: %w", filepath.Base(path), err)
+	}
+
+	var response Response
+
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return Response{}, fmt.Errorf("plugin %s: invalid response: %w", filepath.Base(path), err)
+	}
+
+	if response.Protocol != ProtocolVersion {
+		return Response{}, fmt.Errorf("plugin %s: protocol version %d, calc speaks %d", filepath.Base(path), response.Protocol, ProtocolVersion)
+	}
+
+	if response.Error != "" {
+		return Response{}, errors.New(response.Error)
+	}
+
+	return response, nil
+}
+
+// run kills the plugin with the processes it started when ctx is done,
+// killing the plugin alone leaves Wait blocked on the children still
+// holding its stdout
+func run(ctx context.Context, cmd *exec.Cmd) error {
+	group(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			kill(cmd)
+		case <-done:
+		}
+	}()
+
+	err := cmd.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// Serve implements the plugin side of the protocol for plugins written
+// in Go: it reads one request from r and writes the response to w
+func Serve(r io.Reader, w io.Writer, specs []Spec, eval func(operation string, operands []string) (string, error)) error {
+	var request Request
+
+	response := Response{Protocol: ProtocolVersion}
+
+	if err := json.NewDecoder(r).Decode(&request); err != nil {
+		response.Error = fmt.Sprintf("invalid request: %v", err)
+
+		return json.NewEncoder(w).Encode(response)
+	}
+
+	switch {
+	case request.Protocol != ProtocolVersion:
+		response.Error = fmt.Sprintf("unsupported protocol version %d", request.Protocol)
+	case request.Method == MethodDescribe:
+		response.Operations = specs
+	case request.Method == MethodEval:
+		response.Result, response.Error = evaluate(specs, eval, request)
+	default:
+		response.Error = fmt.Sprintf("unknown method %q", request.Method)
+	}
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+func evaluate(specs []Spec, eval func(operation string, operands []string) (string, error), request Request) (string, string) {
+	for _, spec := range specs {
+		if spec.Name != request.Operation {
+			continue
+		}
+
+		if len(request.Operands) != spec.Arity {
+			return "", fmt.Sprintf("%s expects %d operands, got %d", spec.Name, spec.Arity, len(request.Operands))
+		}
+
+		result, err := eval(request.Operation, request.Operands)
+		if err != nil {
+			return "", err.Error()
+		}
+
+		return result, ""
+	}
+
+	return "", fmt.Sprintf("unknown operation %q", request.Operation)
+}
@@ -0,0 +1,253 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+// the test binary doubles as a plugin when this variable is set
+const servePlugin = "CALC_TEST_SERVE_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(servePlugin) != "" {
+		specs := []Spec{
+			{Name: "vat", Arity: 2, Help: "add a VAT percentage to an amount"},
+		}
+
+		err := Serve(os.Stdin, os.Stdout, specs, func(operation string, operands []string) (string, error) {
+			amount, err := strconv.Atoi(operands[0])
+			if err != nil {
+				return "", fmt.Errorf("invalid amount %q", operands[0])
+			}
+
+			rate, err := strconv.Atoi(operands[1])
+			if err != nil {
+				return "", fmt.Errorf("invalid rate %q", operands[1])
+			}
+
+			return strconv.Itoa(amount + amount*rate/100), nil
+		})
+
+		if err != nil {
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+func install(t *testing.T, name string) string {
+	t.Helper()
+
+	t.Setenv(servePlugin, "1")
+
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+
+	if err := os.Symlink(self, filepath.Join(dir, name)); err != nil {
+		t.Skip("symlinks are not available:", err)
+	}
+
+	return dir
+}
+
+func TestDiscover(t *testing.T) {
+	dir := install(t, "calc-tax")
+
+	// not a plugin
+	if err := os.WriteFile(filepath.Join(dir, "tax"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, errs := Discover(context.Background(), []string{dir, filepath.Join(dir, "missing")})
+
+	assert.Equal(t, len(errs), 0)
+	assert.Equal(t, len(plugins), 1)
+	assert.Equal(t, len(plugins[0].Operations), 1)
+	assert.Equal(t, plugins[0].Operations[0].Name, "vat")
+	assert.Equal(t, plugins[0].Operations[0].Arity, 2)
+}
+
+func TestEval(t *testing.T) {
+	dir := install(t, "calc-tax")
+
+	p, err := Describe(context.Background(), filepath.Join(dir, "calc-tax"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		operation string
+		operands  []string
+		result    string
+		ok        bool
+	}
+
+	cases := []testCase{
+		{
+			operation: "vat",
+			operands:  []string{"100", "22"},
+			result:    "122",
+			ok:        true,
+		},
+		{
+			operation: "vat",
+			operands:  []string{"100"},
+			ok:        false,
+		},
+		{
+			operation: "vat",
+			operands:  []string{"x", "22"},
+			ok:        false,
+		},
+		{
+			operation: "net",
+			operands:  []string{"100", "22"},
+			ok:        false,
+		},
+	}
+
+	for _, tc := range cases {
+		result, err := p.Eval(context.Background(), tc.operation, tc.operands)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error for %s %v", tc.operation, tc.operands)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, result, tc.result)
+		}
+	}
+}
+
+func TestProtocolMismatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "calc-old")
+
+	script := "#!/bin/sh\necho '{\"protocol\": 0, \"operations\": [{\"name\": \"old\", \"arity\": 1}]}'\n"
+
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, errs := Discover(context.Background(), []string{dir})
+
+	assert.Equal(t, len(plugins), 0)
+	assert.Equal(t, len(errs), 1)
+}
+
+func TestInvalidName(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "calc-bad")
+
+	script := "#!/bin/sh\necho '{\"protocol\": 1, \"operations\": [{\"name\": \"bad name\", \"arity\": 1}]}'\n"
+
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Describe(context.Background(), path)
+
+	assert.StringContains(t, fmt.Sprint(err), "bad name")
+}
+
+func TestSlowHandshake(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "calc-slow")
+
+	// the child of the shell keeps the stdout of the plugin open
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nsleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err := Describe(ctx, path)
+
+	assert.Equal(t, errors.Is(err, context.DeadlineExceeded), true)
+	assert.Equal(t, time.Since(start) < 2*time.Second, true)
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "calc-rate")
+
+	describe := func(name string) {
+		t.Helper()
+
+		script := "#!/bin/sh\necho '{\"protocol\": 1, \"operations\": [{\"name\": \"" + name + "\", \"arity\": 1}]}'\n"
+
+		if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	operation := func(c Cache) string {
+		t.Helper()
+
+		plugins, errs := c.Discover(context.Background(), []string{dir})
+
+		assert.Equal(t, len(errs), 0)
+		assert.Equal(t, len(plugins), 1)
+
+		return plugins[0].Operations[0].Name
+	}
+
+	cache := Cache{Path: filepath.Join(t.TempDir(), "calc", "plugins.json")}
+
+	describe("eur")
+	assert.Equal(t, operation(cache), "eur")
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// same size and modification time, the cached handshake is reused
+	describe("usd")
+
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, operation(cache), "eur")
+	assert.Equal(t, operation(Cache{}), "usd")
+
+	// a new modification time runs the handshake again
+	if err := os.Chtimes(path, info.ModTime().Add(time.Second), info.ModTime().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, operation(cache), "usd")
+
+	describe("gbp")
+
+	if err := os.Chtimes(path, info.ModTime().Add(time.Second), info.ModTime().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, _ := cache.Refresh(context.Background(), []string{dir})
+	assert.Equal(t, plugins[0].Operations[0].Name, "gbp")
+	assert.Equal(t, operation(cache), "gbp")
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package plugin
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// group starts the plugin in a process group of its own
+func group(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// kill kills the process group of the plugin
+func kill(cmd *exec.Cmd) {
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package plugin
+
+import "os/exec"
+
+func group(cmd *exec.Cmd) {}
+
+// kill kills the plugin, Windows has no process groups to kill with it
+func kill(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
@@ -0,0 +1,38 @@
+package plugin
+
+// ProtocolVersion is sent with every message, plugins answer with the
+// version they speak and calc refuses the ones that differ
+const ProtocolVersion = 1
+
+// Prefix of plugin executables, calc-tax provides the operations it
+// advertises in its handshake
+const Prefix = "calc-"
+
+const (
+	MethodDescribe = "describe"
+	MethodEval     = "eval"
+)
+
+// Request is written as one JSON document on the plugin stdin, a
+// plugin process serves a single request
+type Request struct {
+	Protocol  int      `json:"protocol"`
+	Method    string   `json:"method"`
+	Operation string   `json:"operation,omitempty"`
+	Operands  []string `json:"operands,omitempty"`
+}
+
+// Response is read as one JSON document from the plugin stdout
+type Response struct {
+	Protocol   int    `json:"protocol"`
+	Operations []Spec `json:"operations,omitempty"`
+	Result     string `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Spec is an operation advertised in the handshake
+type Spec struct {
+	Name  string `json:"name"`
+	Arity int    `json:"arity"`
+	Help  string `json:"help"`
+}
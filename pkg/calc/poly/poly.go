@@ -0,0 +1,510 @@
+package poly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// operands with at least this many coefficients are multiplied with
+// Karatsuba rather than schoolbook multiplication
+const karatsubaThreshold = 16
+
+// Poly holds the coefficients from the constant term up, trailing zero
+// coefficients are trimmed so the zero polynomial is empty. Coefficient
+// arithmetic goes through the checked operations of pkg/calc, so it fails
+// with calc.ErrOverflow once a coefficient outgrows an int
+type Poly []Rat
+
+func New(coefficients ...Rat) Poly {
+	return Poly(coefficients).trim()
+}
+
+// FromInts builds a polynomial from integer coefficients, highest
+// degree first like it is written: FromInts(1, 0, -2) is x^2 - 2
+func FromInts(coefficients ...int) Poly {
+	p := make(Poly, len(coefficients))
+
+	for i, c := range coefficients {
+		p[len(coefficients)-1-i] = Int(c)
+	}
+
+	return p.trim()
+}
+
+// Parse reads a comma separated list of coefficients, highest degree
+// first: "1,0,-1/2" is x^2 - 1/2
+func Parse(s string) (Poly, error) {
+	fields := strings.Split(s, ",")
+
+	p := make(Poly, len(fields))
+
+	for i, field := range fields {
+		c, err := ParseRat(field)
+		if err != nil {
+			return nil, err
+		}
+
+		p[len(fields)-1-i] = c
+	}
+
+	return p.trim(), nil
+}
+
+func (p Poly) trim() Poly {
+	n := len(p)
+
+	for n > 0 && p[n-1].IsZero() {
+		n--
+	}
+
+	return p[:n]
+}
+
+// Degree of the polynomial, -1 for the zero polynomial
+func (p Poly) Degree() int {
+	return len(p) - 1
+}
+
+func (p Poly) IsZero() bool {
+	return len(p) == 0
+}
+
+// Lead is the coefficient of the highest degree term
+func (p Poly) Lead() Rat {
+	if p.IsZero() {
+		return Int(0)
+	}
+
+	return p[len(p)-1]
+}
+
+func (p Poly) coefficient(i int) Rat {
+	if i < len(p) {
+		return p[i]
+	}
+
+	return Int(0)
+}
+
+func (p Poly) Add(ctx context.Context, q Poly) (Poly, error) {
+	a := newArith(ctx)
+
+	sum := a.addPoly(p, q)
+
+	return sum, a.err
+}
+
+func (p Poly) Neg(ctx context.Context) (Poly, error) {
+	a := newArith(ctx)
+
+	neg := a.negPoly(p)
+
+	return neg, a.err
+}
+
+func (p Poly) Sub(ctx context.Context, q Poly) (Poly, error) {
+	a := newArith(ctx)
+
+	difference := a.subPoly(p, q)
+
+	return difference, a.err
+}
+
+func (p Poly) Mul(ctx context.Context, q Poly) (Poly, error) {
+	a := newArith(ctx)
+
+	product := a.mulPoly(p, q)
+
+	return product, a.err
+}
+
+func (a *arith) addPoly(p, q Poly) Poly {
+	n := len(p)
+	if len(q) > n {
+		n = len(q)
+	}
+
+	sum := make(Poly, n)
+
+	for i := range sum {
+		sum[i] = a.add(p.coefficient(i), q.coefficient(i))
+	}
+
+	return sum.trim()
+}
+
+func (a *arith) negPoly(p Poly) Poly {
+	neg := make(Poly, len(p))
+
+	for i, c := range p {
+		neg[i] = a.neg(c)
+	}
+
+	return neg
+}
+
+func (a *arith) subPoly(p, q Poly) Poly {
+	return a.addPoly(p, a.negPoly(q))
+}
+
+func (a *arith) mulPoly(p, q Poly) Poly {
+	if p.IsZero() || q.IsZero() {
+		return Poly{}
+	}
+
+	if len(p) >= karatsubaThreshold && len(q) >= karatsubaThreshold {
+		return a.karatsuba(p, q).trim()
+	}
+
+	return a.schoolbook(p, q).trim()
+}
+
+func (a *arith) schoolbook(p, q Poly) Poly {
+	product := make(Poly, len(p)+len(q))
+
+	for i := range product {
+		product[i] = Int(0)
+	}
+
+	for i, c := range p {
+		if c.IsZero() {
+			continue
+		}
+
+		for j, d := range q {
+			product[i+j] = a.add(product[i+j], a.mul(c, d))
+		}
+	}
+
+	return product
+}
+
+// karatsuba splits p = p1*x^m + p0 and q = q1*x^m + q0 and multiplies
+// with three recursive products instead of four
+func (a *arith) karatsuba(p, q Poly) Poly {
+	if len(p) < karatsubaThreshold || len(q) < karatsubaThreshold {
+		return a.schoolbook(p, q)
+	}
+
+	n := len(p)
+	if len(q) > n {
+		n = len(q)
+	}
+
+	m := n / 2
+
+	p0, p1 := split(p, m)
+	q0, q1 := split(q, m)
+
+	z0 := a.karatsuba(p0, q0)
+	z2 := a.karatsuba(p1, q1)
+	z1 := a.subPoly(a.subPoly(a.karatsuba(a.addPoly(p0, p1), a.addPoly(q0, q1)), z0), z2)
+
+	return a.addPoly(a.addPoly(z0, z1.shift(m)), z2.shift(2*m))
+}
+
+// split returns the coefficients below m and the ones from m up
+func split(p Poly, m int) (Poly, Poly) {
+	if len(p) <= m {
+		return p, Poly{}
+	}
+
+	return Poly(p[:m]).trim(), p[m:]
+}
+
+// shift multiplies by x^n
+func (p Poly) shift(n int) Poly {
+	if p.IsZero() {
+		return p
+	}
+
+	shifted := make(Poly, len(p)+n)
+
+	for i := 0; i < n; i++ {
+		shifted[i] = Int(0)
+	}
+
+	copy(shifted[n:], p)
+
+	return shifted
+}
+
+// DivMod is polynomial long division, p = quotient*q + remainder with
+// the remainder of lower degree than q. Every step must lower the degree
+// of the remainder, DivMod fails rather than loop when one does not
+func (p Poly) DivMod(ctx context.Context, q Poly) (Poly, Poly, error) {
+	a := newArith(ctx)
+
+	quotient, remainder := a.divMod(p, q)
+	if a.err != nil {
+		return nil, nil, a.err
+	}
+
+	return quotient, remainder, nil
+}
+
+func (a *arith) divMod(p, q Poly) (Poly, Poly) {
+	if q.IsZero() {
+		a.check(0, ErrDivisionByZero)
+
+		return nil, nil
+	}
+
+	remainder := append(Poly{}, p...)
+	quotient := make(Poly, 0)
+
+	if p.Degree() >= q.Degree() {
+		quotient = make(Poly, p.Degree()-q.Degree()+1)
+	}
+
+	for i := range quotient {
+		quotient[i] = Int(0)
+	}
+
+	for a.err == nil && !remainder.IsZero() && remainder.Degree() >= q.Degree() {
+		degree := remainder.Degree()
+		shift := degree - q.Degree()
+		factor := a.quo(remainder.Lead(), q.Lead())
+
+		quotient[shift] = factor
+		remainder = a.subPoly(remainder, a.mulPoly(q, Poly{factor}).shift(shift))
+
+		if a.err == nil && remainder.Degree() >= degree {
+			a.check(0, fmt.Errorf("dividing by %s left the remainder %s at degree %d", q, remainder, remainder.Degree()))
+		}
+	}
+
+	return quotient.trim(), remainder
+}
+
+// Monic divides by the leading coefficient
+func (p Poly) Monic(ctx context.Context) (Poly, error) {
+	a := newArith(ctx)
+
+	monic := a.monic(p)
+
+	return monic, a.err
+}
+
+func (a *arith) monic(p Poly) Poly {
+	if p.IsZero() {
+		return p
+	}
+
+	lead := p.Lead()
+	monic := make(Poly, len(p))
+
+	for i, c := range p {
+		monic[i] = a.quo(c, lead)
+	}
+
+	return monic
+}
+
+// GCD is the monic greatest common divisor computed with Euclid's
+// algorithm, the remainders can outgrow an int long before the result
+// does and then GCD fails with calc.ErrOverflow
+func GCD(ctx context.Context, p, q Poly) (Poly, error) {
+	a := newArith(ctx)
+
+	for a.err == nil && !q.IsZero() {
+		_, remainder := a.divMod(p, q)
+
+		p, q = q, remainder
+	}
+
+	gcd := a.monic(p)
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	return gcd, nil
+}
+
+// Eval evaluates p at x with Horner's method
+func (p Poly) Eval(ctx context.Context, x Rat) (Rat, error) {
+	a := newArith(ctx)
+
+	result := a.eval(p, x)
+
+	return result, a.err
+}
+
+func (a *arith) eval(p Poly, x Rat) Rat {
+	result := Int(0)
+
+	for i := len(p) - 1; i >= 0; i-- {
+		result = a.add(a.mul(result, x), p[i])
+	}
+
+	return result
+}
+
+// Float evaluates p at a real x with Horner's method, it has the shape
+// of a numeric.Func so polynomials can be handed to root finders
+func (p Poly) Float(x float64) float64 {
+	var result float64
+
+	for i := len(p) - 1; i >= 0; i-- {
+		result = result*x + p[i].Float()
+	}
+
+	return result
+}
+
+func (p Poly) Derivative(ctx context.Context) (Poly, error) {
+	if len(p) <= 1 {
+		return Poly{}, nil
+	}
+
+	a := newArith(ctx)
+
+	derivative := make(Poly, len(p)-1)
+
+	for i := range derivative {
+		derivative[i] = a.mul(p[i+1], Int(i+1))
+	}
+
+	return derivative.trim(), a.err
+}
+
+// RationalRoots returns the distinct rational roots in increasing order
+// using the rational root theorem: a root p/q has p dividing the
+// constant term and q dividing the leading coefficient
+func (p Poly) RationalRoots(ctx context.Context) ([]Rat, error) {
+	if p.IsZero() {
+		return nil, fmt.Errorf("every number is a root of the zero polynomial")
+	}
+
+	a := newArith(ctx)
+
+	integer := a.integer(p)
+
+	roots := []Rat{}
+
+	// factor out x^k
+	for !integer.IsZero() && integer[0].IsZero() {
+		integer = integer[1:]
+		if len(roots) == 0 {
+			roots = append(roots, Int(0))
+		}
+	}
+
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	seen := map[Rat]bool{}
+
+	for _, num := range divisors(integer[0].Num) {
+		for _, den := range divisors(integer.Lead().Num) {
+			positive := a.reduce(num, den)
+
+			for _, candidate := range []Rat{positive, a.neg(positive)} {
+				if seen[candidate] {
+					continue
+				}
+
+				seen[candidate] = true
+
+				if a.eval(integer, candidate).IsZero() {
+					roots = append(roots, candidate)
+				}
+
+				if a.err != nil {
+					return nil, a.err
+				}
+			}
+		}
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		return a.cmp(roots[i], roots[j]) < 0
+	})
+
+	return roots, a.err
+}
+
+// integer scales p to integer coefficients
+func (a *arith) integer(p Poly) Poly {
+	multiple := 1
+
+	for _, c := range p {
+		multiple = a.product(multiple/gcd(multiple, c.Den), c.Den)
+	}
+
+	return a.mulPoly(p, Poly{Int(multiple)})
+}
+
+// divisors returns the positive divisors of n searched one by one up to
+// its square root
+func divisors(n int) []int {
+	m := magnitude(n)
+
+	small, large := []int{}, []int{}
+
+	// i * i <= m without overflowing
+	for i := uint(1); i <= m/i; i++ {
+		if m%i == 0 {
+			small = append(small, int(i))
+
+			if j := m / i; j != i && j <= math.MaxInt {
+				large = append([]int{int(j)}, large...)
+			}
+		}
+	}
+
+	return append(small, large...)
+}
+
+func (p Poly) String() string {
+	if p.IsZero() {
+		return "0"
+	}
+
+	var b strings.Builder
+
+	for i := len(p) - 1; i >= 0; i-- {
+		c := p[i]
+
+		if c.IsZero() {
+			continue
+		}
+
+		negative := c.Sign() < 0
+
+		switch {
+		case b.Len() == 0 && negative:
+			b.WriteString("-")
+		case b.Len() > 0 && negative:
+			b.WriteString(" - ")
+		case b.Len() > 0:
+			b.WriteString(" + ")
+		}
+
+		b.WriteString(term(strings.TrimPrefix(c.String(), "-"), i))
+	}
+
+	return b.String()
+}
+
+// term prints the magnitude of a coefficient times x^degree
+func term(coefficient string, degree int) string {
+	switch {
+	case degree == 0:
+		return coefficient
+	case coefficient == "1":
+		coefficient = ""
+	default:
+		coefficient += "*"
+	}
+
+	if degree == 1 {
+		return coefficient + "x"
+	}
+
+	return fmt.Sprintf("%sx^%d", coefficient, degree)
+}
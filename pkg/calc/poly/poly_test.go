@@ -0,0 +1,289 @@
+package poly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+func TestParse(t *testing.T) {
+	type testCase struct {
+		input  string
+		output string
+		ok     bool
+	}
+
+	cases := []testCase{
+		{
+			input:  "1,0,-2",
+			output: "x^2 - 2",
+			ok:     true,
+		},
+		{
+			input:  "-1,1/2,0",
+			output: "-x^2 + 1/2*x",
+			ok:     true,
+		},
+		{
+			input:  "0,0,3",
+			output: "3",
+			ok:     true,
+		},
+		{
+			input:  "0",
+			output: "0",
+			ok:     true,
+		},
+		{
+			input: "1,x",
+			ok:    false,
+		},
+		{
+			input: "1/0",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		p, err := Parse(tc.input)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error for %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, p.String(), tc.output)
+		}
+	}
+}
+
+// printed is the result of a polynomial operation or its error
+func printed(s fmt.Stringer, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return s.String()
+}
+
+func TestArithmetic(t *testing.T) {
+	ctx := context.Background()
+
+	p := FromInts(1, 0, -2)
+	q := FromInts(1, 1)
+
+	assert.Equal(t, printed(p.Add(ctx, q)), "x^2 + x - 1")
+	assert.Equal(t, printed(p.Sub(ctx, p)), "0")
+	assert.Equal(t, printed(p.Mul(ctx, q)), "x^3 + x^2 - 2*x - 2")
+	assert.Equal(t, printed(p.Derivative(ctx)), "2*x")
+	assert.Equal(t, printed(p.Eval(ctx, Int(3))), "7")
+
+	half, _ := NewRat(1, 2)
+	assert.Equal(t, printed(p.Eval(ctx, half)), "-7/4")
+
+	sixth, _ := Parse("1/6,1")
+	tenth, _ := Parse("1/10,1")
+	assert.Equal(t, printed(sixth.Add(ctx, tenth)), "4/15*x + 2")
+}
+
+func TestKaratsuba(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for _, size := range []int{16, 17, 40} {
+		p := make(Poly, size)
+		q := make(Poly, size+3)
+
+		for i := range p {
+			p[i] = Int(random.Intn(19) - 9)
+		}
+
+		for i := range q {
+			q[i] = Int(random.Intn(19) - 9)
+		}
+
+		p[size-1] = Int(1)
+		q[size+2] = Int(1)
+
+		a := newArith(context.Background())
+
+		assert.Equal(t, a.karatsuba(p, q).trim().String(), a.schoolbook(p, q).trim().String())
+
+		if a.err != nil {
+			t.Error(a.err)
+		}
+	}
+}
+
+func TestDivMod(t *testing.T) {
+	type testCase struct {
+		p         string
+		q         string
+		quotient  string
+		remainder string
+		ok        bool
+	}
+
+	cases := []testCase{
+		{
+			p:         "1,-3,0,-4",
+			q:         "1,-3",
+			quotient:  "x^2",
+			remainder: "-4",
+			ok:        true,
+		},
+		{
+			p:         "1,0,-1",
+			q:         "2,2",
+			quotient:  "1/2*x - 1/2",
+			remainder: "0",
+			ok:        true,
+		},
+		{
+			p:         "1,1",
+			q:         "1,0,0",
+			quotient:  "0",
+			remainder: "x + 1",
+			ok:        true,
+		},
+		{
+			p:  "1,1",
+			q:  "0",
+			ok: false,
+		},
+	}
+
+	for _, tc := range cases {
+		p, _ := Parse(tc.p)
+		q, _ := Parse(tc.q)
+
+		quotient, remainder, err := p.DivMod(context.Background(), q)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error dividing %s by %s", tc.p, tc.q)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, quotient.String(), tc.quotient)
+			assert.Equal(t, remainder.String(), tc.remainder)
+		}
+	}
+}
+
+func TestGCD(t *testing.T) {
+	ctx := context.Background()
+
+	// (x - 1)(x + 2) and (x - 1)(x - 3)
+	p := FromInts(1, 1, -2)
+	q := FromInts(2, -8, 6)
+
+	assert.Equal(t, printed(GCD(ctx, p, q)), "x - 1")
+	assert.Equal(t, printed(GCD(ctx, p, FromInts(1, 5))), "1")
+
+	// dividing x^2 by 3037000500*x + 1 leaves -1/3037000500*x, the next
+	// quotient 1/3037000500^2 does not fit an int
+	p, _ = Parse("1,0,0")
+	q, _ = Parse("3037000500,1")
+
+	if _, err := GCD(ctx, p, q); !errors.Is(err, calc.ErrOverflow) {
+		t.Errorf("expected the remainders to overflow, got %v", err)
+	}
+}
+
+func TestLargeCoefficients(t *testing.T) {
+	ctx := context.Background()
+
+	p, _ := Parse("4000000000,1")
+
+	// 16000000000000000000 does not fit an int
+	if _, err := p.Mul(ctx, p); !errors.Is(err, calc.ErrOverflow) {
+		t.Errorf("expected the product to overflow, got %v", err)
+	}
+
+	max, _ := Parse("9223372036854775807")
+	if _, err := max.Add(ctx, max); !errors.Is(err, calc.ErrOverflow) {
+		t.Errorf("expected the sum to overflow, got %v", err)
+	}
+
+	if _, err := max.Neg(ctx); err != nil {
+		t.Error(err)
+	}
+
+	min, _ := Parse("-9223372036854775808")
+	if _, err := min.Neg(ctx); !errors.Is(err, calc.ErrOverflow) {
+		t.Errorf("expected the negation to overflow, got %v", err)
+	}
+
+	// the fractions are reduced before they are multiplied
+	small, _ := Parse("1/9223372036854775807,-1")
+	assert.Equal(t, printed(small.Eval(ctx, Int(9223372036854775807))), "0")
+
+	square, _ := Parse("1,0,0")
+	if _, err := square.Eval(ctx, Int(4000000000)); !errors.Is(err, calc.ErrOverflow) {
+		t.Errorf("expected the evaluation to overflow, got %v", err)
+	}
+}
+
+func TestRationalRoots(t *testing.T) {
+	type testCase struct {
+		p     string
+		roots string
+	}
+
+	cases := []testCase{
+		{
+			p:     "1,-3,2",
+			roots: "1 2",
+		},
+		{
+			// 2x^3 - 3x^2 - 2x = x(2x + 1)(x - 2)
+			p:     "2,-3,-2,0",
+			roots: "-1/2 0 2",
+		},
+		{
+			p:     "1,0,-2",
+			roots: "",
+		},
+		{
+			p:     "1/2,-1/2",
+			roots: "1",
+		},
+	}
+
+	for _, tc := range cases {
+		p, _ := Parse(tc.p)
+
+		roots, err := p.RationalRoots(context.Background())
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		result := ""
+		for i, root := range roots {
+			if i > 0 {
+				result += " "
+			}
+
+			result += root.String()
+		}
+
+		assert.Equal(t, result, tc.roots)
+	}
+
+	if _, err := (Poly{}).RationalRoots(context.Background()); err == nil {
+		t.Error("expected error for the zero polynomial")
+	}
+}
@@ -0,0 +1,209 @@
+package poly
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+var ErrDivisionByZero = calc.ErrDivisionByZero
+
+// Rat is a reduced fraction with a positive denominator, the zero value
+// is not a valid Rat, use Int(0)
+type Rat struct {
+	Num int
+	Den int
+}
+
+func Int(n int) Rat {
+	return Rat{Num: n, Den: 1}
+}
+
+func NewRat(num, den int) (Rat, error) {
+	if den == 0 {
+		return Rat{}, ErrDivisionByZero
+	}
+
+	a := newArith(context.Background())
+
+	r := a.reduce(num, den)
+
+	return r, a.err
+}
+
+// ParseRat parses integers (3) and fractions (-1/2)
+func ParseRat(s string) (Rat, error) {
+	num, den, fraction := strings.Cut(strings.TrimSpace(s), "/")
+
+	n, err := strconv.Atoi(strings.TrimSpace(num))
+	if err != nil {
+		return Rat{}, fmt.Errorf("invalid coefficient %q", s)
+	}
+
+	if !fraction {
+		return Int(n), nil
+	}
+
+	d, err := strconv.Atoi(strings.TrimSpace(den))
+	if err != nil {
+		return Rat{}, fmt.Errorf("invalid coefficient %q", s)
+	}
+
+	return NewRat(n, d)
+}
+
+func (r Rat) IsZero() bool {
+	return r.Num == 0
+}
+
+func (r Rat) IsInt() bool {
+	return r.Den == 1
+}
+
+// Sign is -1, 0 or 1
+func (r Rat) Sign() int {
+	switch {
+	case r.Num < 0:
+		return -1
+	case r.Num > 0:
+		return 1
+	}
+
+	return 0
+}
+
+// Float is the closest float64, for numeric methods working on reals
+func (r Rat) Float() float64 {
+	return float64(r.Num) / float64(r.Den)
+}
+
+func (r Rat) String() string {
+	if r.IsInt() {
+		return strconv.Itoa(r.Num)
+	}
+
+	return fmt.Sprintf("%d/%d", r.Num, r.Den)
+}
+
+// arith combines coefficients with the checked operations of pkg/calc,
+// so they fail with calc.ErrOverflow rather than wrap around. It keeps
+// the first error, later results are meaningless and are dropped by the
+// polynomial operations returning that error
+type arith struct {
+	ctx context.Context
+	err error
+}
+
+func newArith(ctx context.Context) *arith {
+	return &arith{ctx: calc.Checked(ctx)}
+}
+
+func (a *arith) check(n int, err error) int {
+	if err != nil && a.err == nil {
+		a.err = err
+	}
+
+	return n
+}
+
+func (a *arith) sum(x, y int) int {
+	return a.check(calc.SumContext(a.ctx, x, y))
+}
+
+func (a *arith) product(x, y int) int {
+	return a.check(calc.MulContext(a.ctx, x, y))
+}
+
+func (a *arith) negate(x int) int {
+	return a.check(calc.SubContext(a.ctx, 0, x))
+}
+
+// reduce divides num and den by their gcd, the divisions are exact so
+// they use Go's / rather than calc.Div, which subtracts once per unit of
+// the quotient
+func (a *arith) reduce(num, den int) Rat {
+	if den < 0 {
+		num, den = a.negate(num), a.negate(den)
+	}
+
+	if g := gcd(num, den); g > 1 {
+		num, den = num/g, den/g
+	}
+
+	if num == 0 {
+		den = 1
+	}
+
+	return Rat{Num: num, Den: den}
+}
+
+// add divides by the gcd of the denominators first, so the intermediate
+// products stay as small as the result allows
+func (a *arith) add(r, s Rat) Rat {
+	g := gcd(r.Den, s.Den)
+
+	num := a.sum(a.product(r.Num, s.Den/g), a.product(s.Num, r.Den/g))
+
+	return a.reduce(num, a.product(r.Den/g, s.Den))
+}
+
+func (a *arith) sub(r, s Rat) Rat {
+	return a.add(r, a.neg(s))
+}
+
+func (a *arith) neg(r Rat) Rat {
+	return Rat{Num: a.negate(r.Num), Den: r.Den}
+}
+
+// mul cancels the numerator of each fraction against the denominator of
+// the other before multiplying
+func (a *arith) mul(r, s Rat) Rat {
+	g1, g2 := gcd(r.Num, s.Den), gcd(s.Num, r.Den)
+	if g1 == 0 {
+		g1 = 1
+	}
+
+	if g2 == 0 {
+		g2 = 1
+	}
+
+	return a.reduce(a.product(r.Num/g1, s.Num/g2), a.product(r.Den/g2, s.Den/g1))
+}
+
+func (a *arith) quo(r, s Rat) Rat {
+	if s.IsZero() {
+		a.check(0, ErrDivisionByZero)
+
+		return Int(0)
+	}
+
+	return a.mul(r, a.reduce(s.Den, s.Num))
+}
+
+// cmp is -1, 0 or 1 as r is less than, equal to or greater than s
+func (a *arith) cmp(r, s Rat) int {
+	return a.sub(r, s).Sign()
+}
+
+// gcd of the magnitudes, gcd(0, 0) is 0
+func gcd(x, y int) int {
+	a, b := magnitude(x), magnitude(y)
+
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return int(a)
+}
+
+// magnitude is |n|, the smallest int has no positive int counterpart
+func magnitude(n int) uint {
+	if n < 0 {
+		return uint(-n)
+	}
+
+	return uint(n)
+}
@@ -0,0 +1,199 @@
+package calc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Operation is an integer operation the front-ends expose, the cobra
+// commands are generated from the registered operations
+type Operation interface {
+	Name() string
+	// Arity is the number of operands Eval expects
+	Arity() int
+	Description() string
+	// Eval stops with ctx.Err() once ctx is done
+	Eval(ctx context.Context, operands ...int) (int, error)
+}
+
+// Extended is implemented by the operations that also evaluate operands
+// other than integers, such as intervals or quantities, the front-ends
+// fall back to it when an operand is not an integer
+type Extended interface {
+	Operation
+	// About describes the other operands
+	About() string
+	ValidateOperands(operands ...string) error
+	EvalOperands(operands ...string) (string, error)
+}
+
+type operation struct {
+	name        string
+	arity       int
+	description string
+	eval        func(ctx context.Context, operands ...int) (int, error)
+}
+
+// NewOperation builds an Operation from its evaluation function, the
+// operands are checked against the arity before eval is called
+func NewOperation(name string, arity int, description string, eval func(ctx context.Context, operands ...int) (int, error)) Operation {
+	return &operation{name: name, arity: arity, description: description, eval: eval}
+}
+
+func (o *operation) Name() string {
+	return o.name
+}
+
+func (o *operation) Arity() int {
+	return o.arity
+}
+
+func (o *operation) Description() string {
+	return o.description
+}
+
+func (o *operation) Eval(ctx context.Context, operands ...int) (int, error) {
+	if len(operands) != o.arity {
+		return 0, fmt.Errorf("%w: %s expects %d, got %d", ErrArity, o.name, o.arity, len(operands))
+	}
+
+	return o.eval(ctx, operands...)
+}
+
+// names double as command names
+var validName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// ValidName reports whether name can name an operation and its command
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// Registry keeps operations in registration order
+type Registry struct {
+	mu         sync.RWMutex
+	operations []Operation
+	byName     map[string]Operation
+}
+
+func NewRegistry() *Registry {
+	return &Registry{byName: map[string]Operation{}}
+}
+
+func (r *Registry) Register(op Operation) error {
+	if !validName.MatchString(op.Name()) {
+		return fmt.Errorf("invalid operation name %q", op.Name())
+	}
+
+	if op.Arity() < 0 {
+		return fmt.Errorf("invalid arity %d for %s", op.Arity(), op.Name())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byName[op.Name()]; ok {
+		return fmt.Errorf("operation %s already registered", op.Name())
+	}
+
+	r.operations = append(r.operations, op)
+	r.byName[op.Name()] = op
+
+	return nil
+}
+
+// Wrap replaces the operation called name with wrap(op), keeping its
+// position, and reports whether there is one. Front-ends wrap the
+// built-in operations to extend them, see Extended
+func (r *Registry) Wrap(name string, wrap func(op Operation) Operation) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	op, ok := r.byName[name]
+	if !ok {
+		return false
+	}
+
+	wrapped := wrap(op)
+
+	for i := range r.operations {
+		if r.operations[i] == op {
+			r.operations[i] = wrapped
+		}
+	}
+
+	r.byName[name] = wrapped
+
+	return true
+}
+
+func (r *Registry) Lookup(name string) (Operation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	op, ok := r.byName[name]
+
+	return op, ok
+}
+
+// Eval evaluates the operation called name, ErrUnknownOperation when
+// there is none
+func (r *Registry) Eval(ctx context.Context, name string, operands ...int) (int, error) {
+	op, ok := r.Lookup(name)
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownOperation, name)
+	}
+
+	return op.Eval(ctx, operands...)
+}
+
+// Operations returns a copy of the registered operations
+func (r *Registry) Operations() []Operation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Operation{}, r.operations...)
+}
+
+// Default holds the built-in operations, programs embedding calc add
+// theirs with Register before building the commands
+var Default = NewRegistry()
+
+func Register(op Operation) error {
+	return Default.Register(op)
+}
+
+func Lookup(name string) (Operation, bool) {
+	return Default.Lookup(name)
+}
+
+func Operations() []Operation {
+	return Default.Operations()
+}
+
+func init() {
+	builtins := []Operation{
+		NewOperation("sum", 2, "addition operation", func(ctx context.Context, operands ...int) (int, error) {
+			return SumContext(ctx, operands[0], operands[1])
+		}),
+		NewOperation("sub", 2, "subtraction operation", func(ctx context.Context, operands ...int) (int, error) {
+			return SubContext(ctx, operands[0], operands[1])
+		}),
+		NewOperation("mul", 2, "multiply operation", func(ctx context.Context, operands ...int) (int, error) {
+			return MulContext(ctx, operands[0], operands[1])
+		}),
+		NewOperation("div", 2, "division operation", func(ctx context.Context, operands ...int) (int, error) {
+			return DivContext(ctx, operands[0], operands[1])
+		}),
+		NewOperation("pow", 2, "power operation", func(ctx context.Context, operands ...int) (int, error) {
+			return PowContext(ctx, operands[0], operands[1])
+		}),
+	}
+
+	for _, op := range builtins {
+		if err := Register(op); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestBuiltinOperations(t *testing.T) {
+	type testCase struct {
+		name     string
+		operands []int
+		result   int
+		ok       bool
+	}
+
+	cases := []testCase{
+		{
+			name:     "sum",
+			operands: []int{2, 5},
+			result:   7,
+			ok:       true,
+		},
+		{
+			name:     "sub",
+			operands: []int{2, 5},
+			result:   -3,
+			ok:       true,
+		},
+		{
+			name:     "mul",
+			operands: []int{2, 5},
+			result:   10,
+			ok:       true,
+		},
+		{
+			name:     "div",
+			operands: []int{10, 5},
+			result:   2,
+			ok:       true,
+		},
+		{
+			name:     "div",
+			operands: []int{10, 0},
+			ok:       false,
+		},
+		{
+			name:     "pow",
+			operands: []int{2, 5},
+			result:   32,
+			ok:       true,
+		},
+		{
+			name:     "sum",
+			operands: []int{2},
+			ok:       false,
+		},
+	}
+
+	for _, tc := range cases {
+		op, ok := Lookup(tc.name)
+		if !ok {
+			t.Fatalf("%s is not registered", tc.name)
+		}
+
+		result, err := op.Eval(context.Background(), tc.operands...)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error for %s %v", tc.name, tc.operands)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, result, tc.result)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	registry := NewRegistry()
+
+	negate := NewOperation("neg", 1, "negation", func(ctx context.Context, operands ...int) (int, error) {
+		return Sub(0, operands[0]), nil
+	})
+
+	if err := registry.Register(negate); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.Register(negate); err == nil {
+		t.Error("expected error registering neg twice")
+	}
+
+	if err := registry.Register(NewOperation("Bad Name", 1, "", nil)); err == nil {
+		t.Error("expected error for an invalid name")
+	}
+
+	op, ok := registry.Lookup("neg")
+	if !ok {
+		t.Fatal("neg is not registered")
+	}
+
+	result, _ := op.Eval(context.Background(), 4)
+	assert.Equal(t, result, -4)
+	assert.Equal(t, len(registry.Operations()), 1)
+
+	result, err := registry.Eval(context.Background(), "neg", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, result, -5)
+
+	if _, err := registry.Eval(context.Background(), "sum", 1, 2); !errors.Is(err, ErrUnknownOperation) {
+		t.Errorf("expected ErrUnknownOperation got %v", err)
+	}
+
+	if _, err := registry.Eval(context.Background(), "neg", 1, 2); !errors.Is(err, ErrArity) {
+		t.Errorf("expected ErrArity got %v", err)
+	}
+
+	if _, ok := registry.Lookup("sum"); ok {
+		t.Error("a new registry has no built-in operations")
+	}
+}
+
+type twice struct {
+	Operation
+}
+
+func (t twice) Eval(ctx context.Context, operands ...int) (int, error) {
+	result, err := t.Operation.Eval(ctx, operands...)
+
+	return Mul(result, 2), err
+}
+
+func TestWrap(t *testing.T) {
+	registry := NewRegistry()
+
+	for _, name := range []string{"sum", "sub"} {
+		op, _ := Lookup(name)
+
+		if err := registry.Register(op); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wrapped := registry.Wrap("sum", func(op Operation) Operation {
+		return twice{Operation: op}
+	})
+
+	assert.Equal(t, wrapped, true)
+	assert.Equal(t, registry.Wrap("mul", func(op Operation) Operation { return op }), false)
+
+	result, err := registry.Eval(context.Background(), "sum", 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, result, 10)
+
+	// the wrapped operation keeps its position
+	operations := registry.Operations()
+
+	_, ok := operations[0].(twice)
+
+	assert.Equal(t, ok, true)
+	assert.Equal(t, len(operations), 2)
+}
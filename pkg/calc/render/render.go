@@ -0,0 +1,169 @@
+package render
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is the JSON Schema of the records written in the structured
+// formats, it is published as result.schema.json next to this file
+//
+//go:embed result.schema.json
+var Schema []byte
+
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	YAML Format = "yaml"
+	CSV  Format = "csv"
+)
+
+var formats = []Format{Text, JSON, YAML, CSV}
+
+func ParseFormat(s string) (Format, error) {
+	for _, format := range formats {
+		if string(format) == strings.ToLower(s) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown output %q, use text, json, yaml or csv", s)
+}
+
+// Precision modes reported in the records
+const (
+	// integers wrapping around on overflow like Go's int
+	Wrap = "wrap"
+	// machine integers failing on overflow, --precision checked
+	Checked = "checked"
+	// binary floating point, units and intervals
+	Float64 = "float64"
+	// exact decimals, money and finance
+	Decimal = "decimal"
+	// exact fractions, polynomials and symbolic evaluation
+	Rational = "rational"
+	// integers of any size, sequences
+	Arbitrary = "arbitrary"
+)
+
+// Record is the stable schema of a result, Text replaces Result in the
+// text format when the human output differs from the value
+type Record struct {
+	Operation string            `json:"operation" yaml:"operation"`
+	Operands  []string          `json:"operands" yaml:"operands"`
+	Result    string            `json:"result" yaml:"result"`
+	Details   map[string]string `json:"details,omitempty" yaml:"details,omitempty"`
+	Precision string            `json:"precision" yaml:"precision"`
+	Error     string            `json:"error,omitempty" yaml:"error,omitempty"`
+	Text      string            `json:"-" yaml:"-"`
+}
+
+var csvHeader = []string{"operation", "operands", "result", "details", "precision", "error"}
+
+// Renderer writes records in one format, a command rendering several
+// records shares one Renderer so the CSV header is written once
+type Renderer struct {
+	w       io.Writer
+	format  Format
+	records int
+}
+
+func New(w io.Writer, format Format) *Renderer {
+	return &Renderer{w: w, format: format}
+}
+
+func (r *Renderer) Format() Format {
+	return r.format
+}
+
+// Render writes one record: text as it always was ended by a newline,
+// JSON one object per line, YAML one document per record and CSV one row
+// per record
+func (r *Renderer) Render(record Record) error {
+	if record.Operands == nil {
+		record.Operands = []string{}
+	}
+
+	defer func() {
+		r.records++
+	}()
+
+	switch r.format {
+	case JSON:
+		return json.NewEncoder(r.w).Encode(record)
+	case YAML:
+		if r.records > 0 {
+			if _, err := io.WriteString(r.w, "---\n"); err != nil {
+				return err
+			}
+		}
+
+		encoder := yaml.NewEncoder(r.w)
+		encoder.SetIndent(2)
+
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+
+		return encoder.Close()
+	case CSV:
+		w := csv.NewWriter(r.w)
+
+		if r.records == 0 {
+			if err := w.Write(csvHeader); err != nil {
+				return err
+			}
+		}
+
+		if err := w.Write([]string{record.Operation, strings.Join(record.Operands, " "), record.Result, details(record.Details), record.Precision, record.Error}); err != nil {
+			return err
+		}
+
+		w.Flush()
+
+		return w.Error()
+	}
+
+	if record.Error != "" {
+		return nil
+	}
+
+	text := record.Text
+	if text == "" {
+		text = record.Result
+	}
+
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	_, err := io.WriteString(r.w, text)
+
+	return err
+}
+
+// details are written as sorted key=value pairs in CSV
+func details(d map[string]string) string {
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+d[key])
+	}
+
+	return strings.Join(pairs, ";")
+}
@@ -0,0 +1,129 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestRender(t *testing.T) {
+	type testCase struct {
+		format Format
+		output string
+	}
+
+	records := []Record{
+		{Operation: "sum", Operands: []string{"1", "2"}, Result: "3", Precision: Wrap},
+		{Operation: "roll", Operands: []string{"1d6"}, Result: "4", Details: map[string]string{"seed": "1", "1d6": "4"}, Precision: Wrap, Text: "seed: 1\n4"},
+		{Operation: "div", Operands: []string{"1", "0"}, Precision: Wrap, Error: "division by zero"},
+	}
+
+	cases := []testCase{
+		{
+			format: Text,
+			output: "3\nseed: 1\n4\n",
+		},
+		{
+			format: JSON,
+			output: `{"operation":"sum","operands":["1","2"],"result":"3","precision":"wrap"}
+{"operation":"roll","operands":["1d6"],"result":"4","details":{"1d6":"4","seed":"1"},"precision":"wrap"}
+{"operation":"div","operands":["1","0"],"result":"","precision":"wrap","error":"division by zero"}
+`,
+		},
+		{
+			format: YAML,
+			output: `operation: sum
+operands:
+  - "1"
+  - "2"
+result: "3"
+precision: wrap
+---
+operation: roll
+operands:
+  - 1d6
+result: "4"
+details:
+  1d6: "4"
+  seed: "1"
+precision: wrap
+---
+operation: div
+operands:
+  - "1"
+  - "0"
+result: ""
+precision: wrap
+error: division by zero
+`,
+		},
+		{
+			format: CSV,
+			output: `operation,operands,result,details,precision,error
+sum,1 2,3,,wrap,
+roll,1d6,4,1d6=4;seed=1,wrap,
+div,1 0,,,wrap,division by zero
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		var b bytes.Buffer
+
+		r := New(&b, tc.format)
+
+		for _, record := range records {
+			if err := r.Render(record); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		assert.Equal(t, b.String(), tc.output)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	var schema struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, _ := json.Marshal(Record{Details: map[string]string{"k": "v"}, Error: "e"})
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	// every field of a record is described by the schema
+	for field := range fields {
+		if _, ok := schema.Properties[field]; !ok {
+			t.Errorf("field %s is missing from the schema", field)
+		}
+	}
+
+	for _, field := range schema.Required {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("required field %s is not always written", field)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("JSON")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, format, JSON)
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected error for xml")
+	}
+}
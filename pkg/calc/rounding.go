@@ -0,0 +1,64 @@
+package calc
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Rounding is a rounding mode of decimal results
+type Rounding string
+
+const (
+	// ties go to the even neighbour, the default
+	HalfEven Rounding = "half-even"
+	// ties go away from zero
+	HalfUp Rounding = "half-up"
+	// the extra digits are dropped, toward zero
+	Down Rounding = "down"
+)
+
+var roundings = []Rounding{HalfEven, HalfUp, Down}
+
+func ParseRounding(s string) (Rounding, error) {
+	for _, rounding := range roundings {
+		if string(rounding) == s {
+			return rounding, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown rounding %q, use half-even, half-up or down", s)
+}
+
+// Round rounds x to the given number of decimal places
+func (r Rounding) Round(x *big.Rat, places int) *big.Rat {
+	scale := pow10(places)
+
+	scaled := new(big.Rat).Mul(x, new(big.Rat).SetInt(scale))
+
+	return new(big.Rat).SetFrac(r.round(scaled), scale)
+}
+
+// round rounds x to an integer
+func (r Rounding) round(x *big.Rat) *big.Int {
+	if r == Down {
+		return new(big.Int).Quo(x.Num(), x.Denom())
+	}
+
+	quo, rem := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+
+	// compare twice the remainder with the denominator
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+
+	switch twice.Cmp(x.Denom()) {
+	case 1:
+		quo.Add(quo, big.NewInt(int64(x.Sign())))
+	case 0:
+		// ties go away from zero with HalfUp, to the even one otherwise
+		if r == HalfUp || quo.Bit(0) == 1 {
+			quo.Add(quo, big.NewInt(int64(x.Sign())))
+		}
+	}
+
+	return quo
+}
@@ -0,0 +1,59 @@
+package calc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestRounding(t *testing.T) {
+	type testCase struct {
+		value    string
+		rounding Rounding
+		rounded  string
+	}
+
+	cases := []testCase{
+		{
+			value:    "2.5",
+			rounding: HalfEven,
+			rounded:  "2",
+		},
+		{
+			value:    "2.5",
+			rounding: HalfUp,
+			rounded:  "3",
+		},
+		{
+			value:    "-2.5",
+			rounding: HalfUp,
+			rounded:  "-3",
+		},
+		{
+			value:    "2.4",
+			rounding: HalfUp,
+			rounded:  "2",
+		},
+		{
+			value:    "2.9",
+			rounding: Down,
+			rounded:  "2",
+		},
+		{
+			value:    "-2.9",
+			rounding: Down,
+			rounded:  "-2",
+		},
+	}
+
+	for _, tc := range cases {
+		value, _ := new(big.Rat).SetString(tc.value)
+
+		assert.Equal(t, tc.rounding.Round(value, 0).FloatString(0), tc.rounded)
+	}
+
+	if _, err := ParseRounding("ceiling"); err == nil {
+		t.Error("expected an error for an unknown rounding")
+	}
+}
@@ -0,0 +1,260 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+// failures kept per operation, the counts are always complete
+const maxFailures = 10
+
+// Mul, Div and Pow loop on their operands, the checks keep the loops
+// below maxLoops iterations and the exponents below maxExponent
+const (
+	maxLoops    = 1 << 10
+	maxExponent = 64
+)
+
+// check compares an operation of pkg/calc with Go's native operators
+type check struct {
+	name      string
+	operation func(first, second int) (int, error)
+	reference func(first, second int) (int, error)
+	// valid filters the pairs of edge cases, all are checked when nil
+	valid func(first, second int) bool
+	// draw returns random operands, any int when nil
+	draw func(random *rand.Rand) (int, int)
+}
+
+var checks = []check{
+	{
+		name: "sum",
+		operation: func(first, second int) (int, error) {
+			return calc.Sum(first, second), nil
+		},
+		reference: func(first, second int) (int, error) {
+			return first + second, nil
+		},
+	},
+	{
+		name: "sub",
+		operation: func(first, second int) (int, error) {
+			return calc.Sub(first, second), nil
+		},
+		reference: func(first, second int) (int, error) {
+			return first - second, nil
+		},
+	},
+	{
+		name: "mul",
+		operation: func(first, second int) (int, error) {
+			return calc.Mul(first, second), nil
+		},
+		reference: func(first, second int) (int, error) {
+			return first * second, nil
+		},
+		// Mul loops on the operand of smaller magnitude
+		valid: func(first, second int) bool {
+			return magnitude(first) <= maxLoops || magnitude(second) <= maxLoops
+		},
+		draw: func(random *rand.Rand) (int, int) {
+			return int(random.Uint64()), random.Intn(2*maxLoops+1) - maxLoops
+		},
+	},
+	{
+		name:      "div",
+		operation: calc.Div,
+		reference: func(first, second int) (int, error) {
+			if second == 0 {
+				return 0, calc.ErrDivisionByZero
+			}
+
+			return first / second, nil
+		},
+		// Div subtracts once per unit of the quotient but divides by ±1
+		// right away
+		valid: func(first, second int) bool {
+			return second == 0 || magnitude(second) == 1 || magnitude(first)/magnitude(second) <= maxLoops
+		},
+		draw: func(random *rand.Rand) (int, int) {
+			first := int(random.Uint64())
+
+			// the dividend shifted right and moved by one at most keeps
+			// the quotient around 1 << shift
+			second := first>>random.Intn(bits.Len(maxLoops)) + random.Intn(3) - 1
+
+			if random.Intn(2) == 0 {
+				second = -second
+			}
+
+			return first, second
+		},
+	},
+	{
+		name: "pow",
+		operation: func(first, second int) (int, error) {
+			return calc.Pow(first, second), nil
+		},
+		reference: func(first, second int) (int, error) {
+			power := 1
+
+			for i := 0; i < second; i++ {
+				power *= first
+			}
+
+			return power, nil
+		},
+		// Pow multiplies by the base once per unit of the exponent and
+		// does not multiply at all for 0, 1 and -1
+		valid: func(first, second int) bool {
+			return second >= 0 && second <= maxExponent && (second == 0 || magnitude(first) <= 1 || magnitude(first) <= maxLoops/uint(second))
+		},
+		draw: func(random *rand.Rand) (int, int) {
+			exponent := random.Intn(maxExponent + 1)
+			base := maxLoops / (exponent + 1)
+
+			return random.Intn(2*base+1) - base, exponent
+		},
+	},
+}
+
+// Failure is an operation disagreeing with the native reference
+type Failure struct {
+	First    int
+	Second   int
+	Got      string
+	Expected string
+}
+
+func (f Failure) String() string {
+	return fmt.Sprintf("%d, %d: got %s, expected %s", f.First, f.Second, f.Got, f.Expected)
+}
+
+type Result struct {
+	Operation string
+	Passed    int
+	Failed    int
+	Failures  []Failure
+}
+
+// Report is the outcome of Run, running again with the same seed and
+// count repeats exactly the same checks
+type Report struct {
+	Seed    int64
+	Count   int
+	Results []Result
+}
+
+func (r Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Failed > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EdgeCases are zero, ±1, the int limits and the powers of two with
+// their neighbours
+func EdgeCases() []int {
+	values := []int{0, 1, -1, math.MaxInt, math.MinInt, math.MaxInt - 1, math.MinInt + 1}
+
+	for shift := 1; shift < bits.UintSize-1; shift++ {
+		power := 1 << shift
+
+		values = append(values, power, -power, power-1, power+1)
+	}
+
+	return values
+}
+
+// Run checks every operation on all the pairs of edge cases and on
+// count random pairs drawn from seed
+func Run(ctx context.Context, seed int64, count int) (Report, error) {
+	report := Report{Seed: seed, Count: count}
+
+	edges := EdgeCases()
+
+	for _, c := range checks {
+		result := Result{Operation: c.name}
+
+		random := rand.New(rand.NewSource(seed))
+
+		for _, first := range edges {
+			for _, second := range edges {
+				if c.valid != nil && !c.valid(first, second) {
+					continue
+				}
+
+				result.compare(c, first, second)
+			}
+
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+		}
+
+		for i := 0; i < count; i++ {
+			first, second := int(random.Uint64()), int(random.Uint64())
+
+			if c.draw != nil {
+				first, second = c.draw(random)
+			}
+
+			result.compare(c, first, second)
+
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+func (r *Result) compare(c check, first, second int) {
+	got, gotErr := c.operation(first, second)
+	expected, expectedErr := c.reference(first, second)
+
+	if (gotErr == nil) == (expectedErr == nil) && got == expected {
+		r.Passed++
+
+		return
+	}
+
+	r.Failed++
+
+	if len(r.Failures) < maxFailures {
+		r.Failures = append(r.Failures, Failure{
+			First:    first,
+			Second:   second,
+			Got:      outcome(got, gotErr),
+			Expected: outcome(expected, expectedErr),
+		})
+	}
+}
+
+// magnitude is |n|, the smallest int has no positive int counterpart
+func magnitude(n int) uint {
+	if n < 0 {
+		return uint(-n)
+	}
+
+	return uint(n)
+}
+
+func outcome(value int, err error) string {
+	if err != nil {
+		return "error " + err.Error()
+	}
+
+	return fmt.Sprintf("%d", value)
+}
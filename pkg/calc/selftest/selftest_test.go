@@ -0,0 +1,55 @@
+package selftest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestRun(t *testing.T) {
+	report, err := Run(context.Background(), 1, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(report.Results), len(checks))
+
+	for _, result := range report.Results {
+		for _, failure := range result.Failures {
+			t.Errorf("%s %s", result.Operation, failure)
+		}
+
+		if result.Passed == 0 {
+			t.Errorf("%s ran no checks", result.Operation)
+		}
+	}
+
+	assert.Equal(t, report.Failed(), false)
+}
+
+func TestRunDetectsFailures(t *testing.T) {
+	broken := check{
+		name:      "broken",
+		operation: func(first, second int) (int, error) { return first, nil },
+		reference: func(first, second int) (int, error) { return second, nil },
+	}
+
+	result := Result{}
+
+	result.compare(broken, 1, 1)
+	result.compare(broken, 1, 2)
+
+	assert.Equal(t, result.Passed, 1)
+	assert.Equal(t, result.Failed, 1)
+	assert.Equal(t, result.Failures[0].String(), "1, 2: got 1, expected 2")
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Run(ctx, 1, 10); err == nil {
+		t.Error("expected a cancelled run to fail")
+	}
+}
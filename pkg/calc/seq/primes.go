@@ -0,0 +1,176 @@
+package seq
+
+import (
+	"math/big"
+)
+
+// primes are sieved this many numbers at a time
+const segmentSize = 1 << 16
+
+// the base primes stop here, segments beyond maxBase^2 are sieved with
+// them and the survivors tested one by one, so the base stays small
+const maxBase = 1 << 20
+
+// Primes is the primes in [lower, upper] in increasing order, computed
+// with a segmented sieve so only one segment is in memory at a time
+func Primes(lower, upper int) Sequence {
+	if lower < 2 {
+		lower = 2
+	}
+
+	var base basePrimes
+	var segment []bool
+	var low, index int
+
+	started, done := false, lower > upper
+
+	return Func(func() (*big.Int, bool) {
+		if !started {
+			low = lower
+			started = true
+		}
+
+		for !done {
+			if segment == nil {
+				segment = sieve(&base, low, upper)
+				index = 0
+			}
+
+			for ; index < len(segment); index++ {
+				if segment[index] {
+					prime := low + index
+					index++
+
+					return big.NewInt(int64(prime)), true
+				}
+			}
+
+			// the last segment may end at the largest int
+			if upper-low < len(segment) {
+				done = true
+			}
+
+			low += len(segment)
+			segment = nil
+		}
+
+		return nil, false
+	})
+}
+
+// basePrimes are the primes up to limit, they grow with the segments so
+// that they reach the square root of the segment being sieved and never
+// further than maxBase
+type basePrimes struct {
+	primes []uint32
+	limit  int
+}
+
+// upTo extends the base primes to n with a segmented sieve of its own,
+// after extending them to the square root of n
+func (b *basePrimes) upTo(n int) {
+	if n <= b.limit || n < 2 {
+		return
+	}
+
+	b.upTo(isqrt(n))
+
+	low := b.limit + 1
+	if low < 2 {
+		low = 2
+	}
+
+	for {
+		segment := mark(b.primes, low, segmentLength(low, n))
+
+		for i, prime := range segment {
+			if prime {
+				b.primes = append(b.primes, uint32(low+i))
+			}
+		}
+
+		if n-low < len(segment) {
+			break
+		}
+
+		low += len(segment)
+	}
+
+	b.limit = n
+}
+
+// sieve marks the primes of the segment starting at low, growing base
+// to the square root of its last number
+func sieve(base *basePrimes, low, upper int) []bool {
+	size := segmentLength(low, upper)
+
+	root := isqrt(low + size - 1)
+	if root <= maxBase {
+		base.upTo(root)
+
+		return mark(base.primes, low, size)
+	}
+
+	base.upTo(maxBase)
+
+	segment := mark(base.primes, low, size)
+
+	// ProbablyPrime is exact below 2^64
+	for i, candidate := range segment {
+		if candidate {
+			segment[i] = big.NewInt(int64(low + i)).ProbablyPrime(0)
+		}
+	}
+
+	return segment
+}
+
+// segmentLength is the length of the segment starting at low and ending
+// at upper at the latest
+func segmentLength(low, upper int) int {
+	if upper-low < segmentSize {
+		return upper - low + 1
+	}
+
+	return segmentSize
+}
+
+// mark crosses out the multiples of primes in the size numbers starting
+// at low, primes must reach the square root of the last one
+func mark(primes []uint32, low, size int) []bool {
+	high := low + size - 1
+
+	segment := make([]bool, size)
+	for i := range segment {
+		segment[i] = true
+	}
+
+	for _, prime := range primes {
+		p := int(prime)
+
+		// p*p is beyond the segment, and so are the following primes
+		if p > high/p {
+			break
+		}
+
+		// offsets from low, so the multiples never pass the largest int
+		offset := (p - low%p) % p
+		if square := p * p; square > low {
+			offset = square - low
+		}
+
+		for ; offset < size; offset += p {
+			segment[offset] = false
+		}
+	}
+
+	return segment
+}
+
+func isqrt(n int) int {
+	if n < 0 {
+		return 0
+	}
+
+	return int(new(big.Int).Sqrt(big.NewInt(int64(n))).Int64())
+}
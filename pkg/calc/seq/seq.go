@@ -0,0 +1,153 @@
+package seq
+
+import (
+	"math/big"
+)
+
+// Sequence yields terms one at a time so callers never hold more than
+// the term they are printing, Next reports false once it is exhausted
+type Sequence interface {
+	Next() (*big.Int, bool)
+}
+
+// Func adapts a closure to a Sequence
+type Func func() (*big.Int, bool)
+
+func (f Func) Next() (*big.Int, bool) {
+	return f()
+}
+
+// Take stops s after n terms
+func Take(s Sequence, n int) Sequence {
+	return Func(func() (*big.Int, bool) {
+		if n <= 0 {
+			return nil, false
+		}
+
+		n--
+
+		return s.Next()
+	})
+}
+
+// Arithmetic is first, first+step, first+2*step, ...
+func Arithmetic(first, step *big.Int) Sequence {
+	term := new(big.Int).Set(first)
+	step = new(big.Int).Set(step)
+
+	return Func(func() (*big.Int, bool) {
+		current := new(big.Int).Set(term)
+		term.Add(term, step)
+
+		return current, true
+	})
+}
+
+// Geometric is first, first*ratio, first*ratio^2, ...
+func Geometric(first, ratio *big.Int) Sequence {
+	term := new(big.Int).Set(first)
+	ratio = new(big.Int).Set(ratio)
+
+	return Func(func() (*big.Int, bool) {
+		current := new(big.Int).Set(term)
+		term.Mul(term, ratio)
+
+		return current, true
+	})
+}
+
+// Triangular is T(start), T(start+1), ... with T(n) = 1 + 2 + ... + n
+func Triangular(start int) Sequence {
+	n := big.NewInt(int64(start))
+	term := new(big.Int).Mul(n, big.NewInt(int64(start)+1))
+	term.Rsh(term, 1)
+
+	return Func(func() (*big.Int, bool) {
+		current := new(big.Int).Set(term)
+
+		n.Add(n, big.NewInt(1))
+		term.Add(term, n)
+
+		return current, true
+	})
+}
+
+// Fibonacci is F(start), F(start+1), ... the first two terms come from
+// fast doubling, the rest from plain additions
+func Fibonacci(start int) Sequence {
+	current, next := fibPair(start)
+
+	return pairs(current, next)
+}
+
+// Lucas is L(start), L(start+1), ... with L(n) = 2*F(n+1) - F(n)
+func Lucas(start int) Sequence {
+	f, g := fibPair(start)
+	h := new(big.Int).Add(f, g)
+
+	current := new(big.Int).Sub(new(big.Int).Lsh(g, 1), f)
+	next := new(big.Int).Sub(new(big.Int).Lsh(h, 1), g)
+
+	return pairs(current, next)
+}
+
+// pairs continues a sequence where each term is the sum of the two before
+func pairs(current, next *big.Int) Sequence {
+	return Func(func() (*big.Int, bool) {
+		term := current
+
+		current, next = next, new(big.Int).Add(current, next)
+
+		return term, true
+	})
+}
+
+// Fib is the n-th Fibonacci number, F(0) = 0 and F(1) = 1
+func Fib(n int) *big.Int {
+	f, _ := fibPair(n)
+
+	return f
+}
+
+// LucasNumber is the n-th Lucas number, L(0) = 2 and L(1) = 1
+func LucasNumber(n int) *big.Int {
+	f, g := fibPair(n)
+
+	return new(big.Int).Sub(new(big.Int).Lsh(g, 1), f)
+}
+
+// fibPair returns F(n) and F(n+1) with fast doubling:
+// F(2k) = F(k)*(2*F(k+1) - F(k)) and F(2k+1) = F(k)^2 + F(k+1)^2
+func fibPair(n int) (*big.Int, *big.Int) {
+	f, g := big.NewInt(0), big.NewInt(1)
+
+	if n <= 0 {
+		return f, g
+	}
+
+	for bit := bitLength(n) - 1; bit >= 0; bit-- {
+		double := new(big.Int).Lsh(g, 1)
+		double.Sub(double, f).Mul(double, f)
+
+		squares := new(big.Int).Mul(f, f)
+		squares.Add(squares, new(big.Int).Mul(g, g))
+
+		f, g = double, squares
+
+		if n>>bit&1 == 1 {
+			f, g = g, new(big.Int).Add(f, g)
+		}
+	}
+
+	return f, g
+}
+
+func bitLength(n int) int {
+	length := 0
+
+	for ; n > 0; n >>= 1 {
+		length++
+	}
+
+	return length
+}
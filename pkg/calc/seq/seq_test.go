@@ -0,0 +1,126 @@
+package seq
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func collect(s Sequence) string {
+	terms := []string{}
+
+	for term, ok := s.Next(); ok; term, ok = s.Next() {
+		terms = append(terms, term.String())
+	}
+
+	return strings.Join(terms, " ")
+}
+
+func TestSequences(t *testing.T) {
+	type testCase struct {
+		name     string
+		sequence Sequence
+		terms    string
+	}
+
+	cases := []testCase{
+		{
+			name:     "arithmetic",
+			sequence: Take(Arithmetic(big.NewInt(3), big.NewInt(-2)), 4),
+			terms:    "3 1 -1 -3",
+		},
+		{
+			name:     "geometric",
+			sequence: Take(Geometric(big.NewInt(1), big.NewInt(3)), 5),
+			terms:    "1 3 9 27 81",
+		},
+		{
+			name:     "triangular",
+			sequence: Take(Triangular(0), 6),
+			terms:    "0 1 3 6 10 15",
+		},
+		{
+			name:     "fibonacci",
+			sequence: Take(Fibonacci(0), 10),
+			terms:    "0 1 1 2 3 5 8 13 21 34",
+		},
+		{
+			name:     "fibonacci from 90",
+			sequence: Take(Fibonacci(90), 3),
+			terms:    "2880067194370816120 4660046610375530309 7540113804746346429",
+		},
+		{
+			name:     "lucas",
+			sequence: Take(Lucas(0), 8),
+			terms:    "2 1 3 4 7 11 18 29",
+		},
+		{
+			name:     "lucas from 5",
+			sequence: Take(Lucas(5), 3),
+			terms:    "11 18 29",
+		},
+		{
+			name:     "primes",
+			sequence: Primes(0, 30),
+			terms:    "2 3 5 7 11 13 17 19 23 29",
+		},
+		{
+			name:     "primes in a range",
+			sequence: Primes(100, 130),
+			terms:    "101 103 107 109 113 127",
+		},
+		{
+			name:     "no primes",
+			sequence: Primes(24, 28),
+			terms:    "",
+		},
+	}
+
+	for _, tc := range cases {
+		if terms := collect(tc.sequence); terms != tc.terms {
+			t.Errorf("%s: expected %q got %q", tc.name, tc.terms, terms)
+		}
+	}
+}
+
+func TestFastDoubling(t *testing.T) {
+	assert.Equal(t, Fib(0).String(), "0")
+	assert.Equal(t, Fib(1).String(), "1")
+	assert.Equal(t, Fib(100).String(), "354224848179261915075")
+	assert.Equal(t, LucasNumber(0).String(), "2")
+	assert.Equal(t, LucasNumber(10).String(), "123")
+}
+
+func TestPrimesAcrossSegments(t *testing.T) {
+	count := 0
+
+	s := Primes(0, 3*segmentSize)
+	for _, ok := s.Next(); ok; _, ok = s.Next() {
+		count++
+	}
+
+	// pi(196608)
+	assert.Equal(t, count, 17704)
+}
+
+func TestPrimesNearLimits(t *testing.T) {
+	type testCase struct {
+		lower  int
+		upper  int
+		primes string
+	}
+
+	cases := []testCase{
+		// the largest prime below 2^63
+		{lower: math.MaxInt - 100, upper: math.MaxInt, primes: "9223372036854775783"},
+		{lower: 1000000000000000000, upper: 1000000000000000100, primes: "1000000000000000003 1000000000000000009 1000000000000000031 1000000000000000079"},
+		{lower: 10, upper: 2, primes: ""},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, collect(Primes(tc.lower, tc.upper)), tc.primes)
+	}
+}
@@ -0,0 +1,90 @@
+package calc
+
+import "context"
+
+// Closed-form sums of the first n terms of a series, n <= 0 sums nothing.
+// The divisions are exact so the truncating Div is safe
+
+// ArithmeticSeries sums first, first+step, ..., first+(n-1)*step
+// as n*first + step*n*(n-1)/2
+func ArithmeticSeries(first, step, n int) int {
+	sum, _ := ArithmeticSeriesContext(context.Background(), first, step, n)
+
+	return sum
+}
+
+// ArithmeticSeriesContext is ArithmeticSeries returning ErrOverflow in
+// Checked contexts
+func ArithmeticSeriesContext(ctx context.Context, first, step, n int) (int, error) {
+	if n <= 0 {
+		return 0, nil
+	}
+
+	// n*(n-1)/2 halves the even factor before multiplying, so no
+	// intermediate product is twice as large as the sum, the halving is a
+	// shift since Div subtracts once per unit of the quotient
+	even, odd := n, Sub(n, 1)
+	if n&1 == 1 {
+		even, odd = odd, even
+	}
+
+	pairs, err := MulContext(ctx, even>>1, odd)
+	if err != nil {
+		return 0, err
+	}
+
+	steps, err := MulContext(ctx, step, pairs)
+	if err != nil {
+		return 0, err
+	}
+
+	firsts, err := MulContext(ctx, n, first)
+	if err != nil {
+		return 0, err
+	}
+
+	return SumContext(ctx, firsts, steps)
+}
+
+// GeometricSeries sums first, first*ratio, ..., first*ratio^(n-1)
+// as first*(ratio^n - 1)/(ratio - 1)
+func GeometricSeries(first, ratio, n int) int {
+	sum, _ := GeometricSeriesContext(context.Background(), first, ratio, n)
+
+	return sum
+}
+
+// GeometricSeriesContext is GeometricSeries returning ErrOverflow in
+// Checked contexts
+func GeometricSeriesContext(ctx context.Context, first, ratio, n int) (int, error) {
+	if n <= 0 {
+		return 0, nil
+	}
+
+	if ratio == 1 {
+		return MulContext(ctx, first, n)
+	}
+
+	power, err := PowContext(ctx, ratio, n)
+	if err != nil {
+		return 0, err
+	}
+
+	numerator, err := SubContext(ctx, power, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	// 1 + ratio + ... + ratio^(n-1), divided before multiplying by first
+	terms, err := DivContext(ctx, numerator, Sub(ratio, 1))
+	if err != nil {
+		return 0, err
+	}
+
+	return MulContext(ctx, first, terms)
+}
+
+// Triangular is the n-th triangular number 1 + 2 + ... + n
+func Triangular(n int) int {
+	return ArithmeticSeries(1, 1, n)
+}
@@ -0,0 +1,115 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestArithmeticSeries(t *testing.T) {
+	type testCase struct {
+		first int
+		step  int
+		n     int
+		sum   int
+	}
+
+	cases := []testCase{
+		{
+			first: 1,
+			step:  1,
+			n:     100,
+			sum:   5050,
+		},
+		{
+			first: 3,
+			step:  -2,
+			n:     4,
+			sum:   0,
+		},
+		{
+			first: 7,
+			step:  5,
+			n:     0,
+			sum:   0,
+		},
+		{
+			// n*(2*first + (n-1)*step) would not fit
+			first: 0,
+			step:  math.MaxInt / 3,
+			n:     3,
+			sum:   math.MaxInt / 3 * 3,
+		},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, ArithmeticSeries(tc.first, tc.step, tc.n), tc.sum)
+
+		sum, err := ArithmeticSeriesContext(Checked(context.Background()), tc.first, tc.step, tc.n)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		assert.Equal(t, sum, tc.sum)
+	}
+}
+
+func TestGeometricSeries(t *testing.T) {
+	type testCase struct {
+		first int
+		ratio int
+		n     int
+		sum   int
+	}
+
+	cases := []testCase{
+		{
+			first: 1,
+			ratio: 2,
+			n:     10,
+			sum:   1023,
+		},
+		{
+			first: 3,
+			ratio: -2,
+			n:     3,
+			sum:   9,
+		},
+		{
+			first: 5,
+			ratio: 1,
+			n:     4,
+			sum:   20,
+		},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, GeometricSeries(tc.first, tc.ratio, tc.n), tc.sum)
+	}
+}
+
+func TestSeriesOverflow(t *testing.T) {
+	checked := Checked(context.Background())
+
+	_, err := GeometricSeriesContext(checked, 2, 2, 70)
+	assert.Equal(t, errors.Is(err, ErrOverflow), true)
+
+	_, err = ArithmeticSeriesContext(checked, 1, 1, 1<<33)
+	assert.Equal(t, errors.Is(err, ErrOverflow), true)
+
+	sum, err := GeometricSeriesContext(checked, 1, 2, 62)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, sum, 1<<62-1)
+}
+
+func TestTriangular(t *testing.T) {
+	assert.Equal(t, Triangular(4), 10)
+	assert.Equal(t, Triangular(0), 0)
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+)
+
+// OpenAPIVersion is 3.1 so the record JSON Schema is used as it is
+const OpenAPIVersion = "3.1.0"
+
+type object = map[string]interface{}
+
+func ref(name string) object {
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+func content(schema object) object {
+	return object{"application/json": object{"schema": schema}}
+}
+
+// errors every operation may answer with
+var operationErrors = []struct {
+	status      string
+	description string
+}{
+	{status: "400", description: "invalid body, operands or precision"},
+	{status: "404", description: "unknown operation"},
+	{status: "413", description: "body too large"},
+	{status: "422", description: "division by zero or overflow with the checked precision"},
+	{status: "504", description: "the evaluation timed out"},
+}
+
+// OpenAPI describes the endpoints, a path per operation of registry
+func OpenAPI(registry *calc.Registry) object {
+	paths := object{}
+
+	for _, op := range registry.Operations() {
+		responses := object{
+			"200": object{"description": fmt.Sprintf("the result of %s", op.Name()), "content": content(ref("Record"))},
+		}
+
+		for _, e := range operationErrors {
+			responses[e.status] = object{"description": e.description, "content": content(ref("Record"))}
+		}
+
+		request := object{
+			"type":     "object",
+			"required": []string{"operands"},
+			"properties": object{
+				"operands": object{
+					"type":     "array",
+					"items":    object{"type": "integer"},
+					"minItems": op.Arity(),
+					"maxItems": op.Arity(),
+				},
+				"precision": ref("Precision"),
+			},
+			"additionalProperties": false,
+		}
+
+		paths[Prefix+op.Name()] = object{
+			"post": object{
+				"operationId": op.Name(),
+				"summary":     op.Description(),
+				"requestBody": object{"required": true, "content": content(request)},
+				"responses":   responses,
+			},
+		}
+	}
+
+	paths[BatchPath] = object{
+		"post": object{
+			"operationId": "batch",
+			"summary":     fmt.Sprintf("evaluate up to %d requests, failed requests carry their error", maxBatch),
+			"requestBody": object{"required": true, "content": content(ref("BatchRequest"))},
+			"responses": object{
+				"200": object{"description": "a result per request in the same order", "content": content(ref("BatchResponse"))},
+				"400": object{"description": "invalid body", "content": content(ref("Record"))},
+				"413": object{"description": "too many requests or body too large", "content": content(ref("Record"))},
+			},
+		},
+	}
+
+	status := object{"type": "object", "properties": object{"status": object{"type": "string"}}}
+
+	paths[HealthPath] = object{
+		"get": object{
+			"operationId": "health",
+			"summary":     "liveness",
+			"responses":   object{"200": object{"description": "the server is up", "content": content(status)}},
+		},
+	}
+
+	paths[ReadyPath] = object{
+		"get": object{
+			"operationId": "ready",
+			"summary":     "readiness, failing during the shutdown",
+			"responses": object{
+				"200": object{"description": "the server takes requests", "content": content(status)},
+				"503": object{"description": "the server is shutting down", "content": content(status)},
+			},
+		},
+	}
+
+	return object{
+		"openapi": OpenAPIVersion,
+		"info": object{
+			"title":   "calc",
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": object{
+				"Record": record(),
+				"Precision": object{
+					"type": "string",
+					"enum": []string{render.Wrap, render.Checked},
+				},
+				"BatchRequest": object{
+					"type":     "object",
+					"required": []string{"requests"},
+					"properties": object{
+						"requests": object{
+							"type":     "array",
+							"maxItems": maxBatch,
+							"items": object{
+								"type":     "object",
+								"required": []string{"operation", "operands"},
+								"properties": object{
+									"operation": object{"type": "string"},
+									"operands":  object{"type": "array", "items": object{"type": "integer"}},
+									"precision": ref("Precision"),
+								},
+							},
+						},
+					},
+				},
+				"BatchResponse": object{
+					"type":     "object",
+					"required": []string{"results"},
+					"properties": object{
+						"results": object{"type": "array", "items": ref("Record")},
+					},
+				},
+			},
+		},
+	}
+}
+
+// record is the published JSON Schema of the CLI records
+func record() object {
+	schema := object{}
+
+	if err := json.Unmarshal(render.Schema, &schema); err != nil {
+		panic(err)
+	}
+
+	// the identifiers belong to the standalone document
+	delete(schema, "$schema")
+	delete(schema, "$id")
+
+	return schema
+}
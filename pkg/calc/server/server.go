@@ -0,0 +1,337 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+)
+
+const (
+	// operations are served under this prefix, POST /v1/sum
+	Prefix = "/v1/"
+	// BatchPath takes precedence over an operation named batch
+	BatchPath   = Prefix + "batch"
+	OpenAPIPath = Prefix + "openapi.json"
+	HealthPath  = "/healthz"
+	ReadyPath   = "/readyz"
+	GraphQLPath = "/graphql"
+	MetricsPath = "/metrics"
+)
+
+const (
+	maxBody  = 1 << 20
+	maxBatch = 1000
+)
+
+// errInvalidRequest is a body that cannot be decoded
+var errInvalidRequest = errors.New("invalid request")
+
+// Request is the body of POST /v1/<operation>
+type Request struct {
+	Operands []int `json:"operands"`
+	// Precision is wrap, the default, or checked to fail on overflow
+	Precision string `json:"precision,omitempty"`
+}
+
+// BatchItem is a request of a batch, it names its operation
+type BatchItem struct {
+	Operation string `json:"operation"`
+	Request
+}
+
+type BatchRequest struct {
+	Requests []BatchItem `json:"requests"`
+}
+
+// BatchResponse has a result per request in the same order, failed
+// requests carry their error and do not fail the batch
+type BatchResponse struct {
+	Results []render.Record `json:"results"`
+}
+
+type Options struct {
+	// Timeout of each evaluation, none when zero
+	Timeout time.Duration
+	// ShutdownTimeout is how long Serve waits for the requests in flight
+	ShutdownTimeout time.Duration
+	// GraphQL is mounted at GraphQLPath when set
+	GraphQL http.Handler
+	// Metrics is mounted at MetricsPath when set
+	Metrics http.Handler
+}
+
+// Server exposes the operations of a registry over HTTP, results use
+// the record schema of the CLI structured outputs
+type Server struct {
+	registry *calc.Registry
+	opts     Options
+	ready    atomic.Bool
+}
+
+func New(registry *calc.Registry, opts Options) *Server {
+	return &Server{registry: registry, opts: opts}
+}
+
+// Serve serves HTTP on l until ctx is done, then it stops being ready
+// and waits up to ShutdownTimeout for the requests in flight
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	errs := make(chan error, 1)
+
+	s.ready.Store(true)
+
+	go func() {
+		errs <- srv.Serve(l)
+	}()
+
+	select {
+	case err := <-errs:
+		s.ready.Store(false)
+
+		return err
+	case <-ctx.Done():
+	}
+
+	s.ready.Store(false)
+
+	shutdown, cancel := context.WithTimeout(context.Background(), s.opts.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdown); err != nil {
+		return err
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == HealthPath:
+		s.health(w, r)
+	case r.URL.Path == ReadyPath:
+		s.readiness(w, r)
+	case r.URL.Path == OpenAPIPath:
+		s.openapi(w, r)
+	case r.URL.Path == GraphQLPath && s.opts.GraphQL != nil:
+		s.opts.GraphQL.ServeHTTP(w, r)
+	case r.URL.Path == MetricsPath && s.opts.Metrics != nil:
+		s.opts.Metrics.ServeHTTP(w, r)
+	case r.URL.Path == BatchPath:
+		s.batch(w, r)
+	case strings.HasPrefix(r.URL.Path, Prefix):
+		s.operation(w, r, strings.TrimPrefix(r.URL.Path, Prefix))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readiness fails once the shutdown started so load balancers stop
+// sending requests
+func (s *Server) readiness(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+
+	if !s.ready.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+func (s *Server) openapi(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, OpenAPI(s.registry))
+}
+
+func (s *Server) operation(w http.ResponseWriter, r *http.Request, name string) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+
+	var request Request
+
+	if err := decode(w, r, &request); err != nil {
+		record := render.Record{Operation: name, Error: err.Error()}
+
+		writeJSON(w, Status(err), fix(record))
+		return
+	}
+
+	record, err := s.Eval(r.Context(), name, request)
+
+	status := http.StatusOK
+	if err != nil {
+		status = Status(err)
+	}
+
+	writeJSON(w, status, record)
+}
+
+func (s *Server) batch(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+
+	var request BatchRequest
+
+	if err := decode(w, r, &request); err != nil {
+		writeJSON(w, Status(err), fix(render.Record{Operation: "batch", Error: err.Error()}))
+		return
+	}
+
+	if len(request.Requests) > maxBatch {
+		err := fmt.Errorf("%w: %d requests, at most %d in a batch", errInvalidRequest, len(request.Requests), maxBatch)
+
+		writeJSON(w, http.StatusRequestEntityTooLarge, fix(render.Record{Operation: "batch", Error: err.Error()}))
+		return
+	}
+
+	response := BatchResponse{Results: make([]render.Record, 0, len(request.Requests))}
+
+	for _, item := range request.Requests {
+		record, _ := s.Eval(r.Context(), item.Operation, item.Request)
+
+		response.Results = append(response.Results, record)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Eval evaluates a request, the record carries the error as well
+func (s *Server) Eval(ctx context.Context, name string, request Request) (render.Record, error) {
+	record := render.Record{Operation: name, Operands: operands(request.Operands), Precision: render.Wrap}
+
+	switch request.Precision {
+	case "", render.Wrap:
+	case render.Checked:
+		ctx = calc.Checked(ctx)
+		record.Precision = render.Checked
+	default:
+		err := fmt.Errorf("%w: unknown precision %q, use %s or %s", errInvalidRequest, request.Precision, render.Wrap, render.Checked)
+		record.Error = err.Error()
+
+		return record, err
+	}
+
+	if s.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, s.opts.Timeout)
+		defer cancel()
+	}
+
+	result, err := s.registry.Eval(ctx, name, request.Operands...)
+	if err != nil {
+		record.Error = err.Error()
+
+		return record, err
+	}
+
+	record.Result = strconv.Itoa(result)
+
+	return record, nil
+}
+
+// Status maps the errors of pkg/calc to HTTP status codes
+func Status(err error) int {
+	var tooLarge *http.MaxBytesError
+
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, calc.ErrUnknownOperation):
+		return http.StatusNotFound
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errInvalidRequest), errors.Is(err, calc.ErrInvalidOperand), errors.Is(err, calc.ErrArity):
+		return http.StatusBadRequest
+	case errors.Is(err, calc.ErrDivisionByZero), errors.Is(err, calc.ErrOverflow):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, calc.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
+
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+
+	w.Header().Set("Allow", method)
+	writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": fmt.Sprintf("use %s", method)})
+
+	return false
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+
+		return fmt.Errorf("%w: %v", errInvalidRequest, err)
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func operands(values []int) []string {
+	operands := make([]string, 0, len(values))
+
+	for _, value := range values {
+		operands = append(operands, strconv.Itoa(value))
+	}
+
+	return operands
+}
+
+// fix fills the fields the schema requires on records of requests that
+// could not be decoded
+func fix(record render.Record) render.Record {
+	if record.Operands == nil {
+		record.Operands = []string{}
+	}
+
+	return record
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/render"
+)
+
+func do(t *testing.T, s *Server, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+	return w
+}
+
+func TestOperation(t *testing.T) {
+	s := New(calc.Default, Options{})
+
+	type testCase struct {
+		method string
+		path   string
+		body   string
+		status int
+		result string
+		error  string
+	}
+
+	cases := []testCase{
+		{method: http.MethodPost, path: "/v1/sum", body: `{"operands": [2, 3]}`, status: http.StatusOK, result: "5"},
+		{method: http.MethodPost, path: "/v1/pow", body: `{"operands": [2, 10]}`, status: http.StatusOK, result: "1024"},
+		{method: http.MethodPost, path: "/v1/div", body: `{"operands": [1, 0]}`, status: http.StatusUnprocessableEntity, error: "division by zero"},
+		{method: http.MethodPost, path: "/v1/mul", body: `{"operands": [9223372036854775807, 2], "precision": "checked"}`, status: http.StatusUnprocessableEntity, error: "integer overflow"},
+		{method: http.MethodPost, path: "/v1/sum", body: `{"operands": [1]}`, status: http.StatusBadRequest, error: "wrong number of operands: sum expects 2, got 1"},
+		{method: http.MethodPost, path: "/v1/sum", body: `{"operands": ["x", 1]}`, status: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/v1/sum", body: `{"operands": [1, 2], "precision": "exact"}`, status: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/v1/mod", body: `{"operands": [1, 2]}`, status: http.StatusNotFound, error: `unknown operation "mod"`},
+		{method: http.MethodGet, path: "/v1/sum", status: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: "/sum", status: http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		w := do(t, s, tc.method, tc.path, tc.body)
+
+		if w.Code != tc.status {
+			t.Errorf("%s %s %s: expected %d got %d %s", tc.method, tc.path, tc.body, tc.status, w.Code, w.Body)
+			continue
+		}
+
+		if tc.result == "" && tc.error == "" {
+			continue
+		}
+
+		var record render.Record
+		if err := json.Unmarshal(w.Body.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, record.Result, tc.result)
+		assert.Equal(t, record.Error, tc.error)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	s := New(calc.Default, Options{})
+
+	body := `{"requests": [
+		{"operation": "sum", "operands": [1, 2]},
+		{"operation": "div", "operands": [1, 0]},
+		{"operation": "sub", "operands": [-9223372036854775808, 1], "precision": "checked"}
+	]}`
+
+	w := do(t, s, http.MethodPost, BatchPath, body)
+
+	assert.Equal(t, w.Code, http.StatusOK)
+
+	var response BatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(response.Results), 3)
+	assert.Equal(t, response.Results[0].Result, "3")
+	assert.Equal(t, response.Results[1].Error, "division by zero")
+	assert.Equal(t, response.Results[2].Error, "integer overflow")
+	assert.Equal(t, response.Results[2].Precision, render.Checked)
+
+	w = do(t, s, http.MethodPost, BatchPath, `{"requests": `+strings.Repeat("[", 10))
+	assert.Equal(t, w.Code, http.StatusBadRequest)
+}
+
+func TestTimeout(t *testing.T) {
+	s := New(calc.Default, Options{Timeout: time.Millisecond})
+
+	w := do(t, s, http.MethodPost, "/v1/pow", `{"operands": [3, 100000000]}`)
+
+	assert.Equal(t, w.Code, http.StatusGatewayTimeout)
+}
+
+func TestLargeExponent(t *testing.T) {
+	s := New(calc.Default, Options{Timeout: 100 * time.Millisecond})
+
+	type testCase struct {
+		body   string
+		status int
+		result string
+	}
+
+	// the evaluation is stopped or fails, the server keeps running
+	cases := []testCase{
+		{body: `{"operands": [3, 200000000]}`, status: http.StatusGatewayTimeout},
+		{body: `{"operands": [3, 200000000], "precision": "checked"}`, status: http.StatusUnprocessableEntity},
+		{body: `{"operands": [1, 200000000]}`, status: http.StatusOK, result: "1"},
+	}
+
+	for _, tc := range cases {
+		w := do(t, s, http.MethodPost, "/v1/pow", tc.body)
+
+		if w.Code != tc.status {
+			t.Errorf("%s: expected %d got %d %s", tc.body, tc.status, w.Code, w.Body)
+			continue
+		}
+
+		if tc.result != "" {
+			var record render.Record
+			if err := json.Unmarshal(w.Body.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, record.Result, tc.result)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	s := New(calc.Default, Options{})
+
+	w := do(t, s, http.MethodGet, OpenAPIPath, "")
+
+	assert.Equal(t, w.Code, http.StatusOK)
+
+	var document struct {
+		OpenAPI string                 `json:"openapi"`
+		Paths   map[string]interface{} `json:"paths"`
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, document.OpenAPI, OpenAPIVersion)
+
+	for _, op := range calc.Operations() {
+		if _, ok := document.Paths[Prefix+op.Name()]; !ok {
+			t.Errorf("missing path for %s", op.Name())
+		}
+	}
+
+	if _, ok := document.Paths[BatchPath]; !ok {
+		t.Error("missing the batch path")
+	}
+}
+
+func TestServe(t *testing.T) {
+	s := New(calc.Default, Options{ShutdownTimeout: time.Second})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("cannot listen:", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.Serve(ctx, l)
+	}()
+
+	url := "http://" + l.Addr().String()
+
+	response, err := http.Post(url+"/v1/sum", "application/json", strings.NewReader(`{"operands": [40, 2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response.Body.Close()
+
+	assert.Equal(t, response.StatusCode, http.StatusOK)
+	assert.Equal(t, do(t, s, http.MethodGet, ReadyPath, "").Code, http.StatusOK)
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, do(t, s, http.MethodGet, ReadyPath, "").Code, http.StatusServiceUnavailable)
+	assert.Equal(t, do(t, s, http.MethodGet, HealthPath, "").Code, http.StatusOK)
+}
@@ -0,0 +1,398 @@
+package sheet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// limits of the references, the ones of the common spreadsheets
+const (
+	maxCols = 16384
+	maxRows = 1048576
+)
+
+// Cell is a position of the sheet, Row and Col count from 0
+type Cell struct {
+	Row int
+	Col int
+}
+
+// ParseCell parses references like B3, lower case letters and the $ of
+// absolute references are accepted
+func ParseCell(s string) (Cell, error) {
+	name := strings.ToUpper(strings.ReplaceAll(s, "$", ""))
+
+	letters := 0
+	for letters < len(name) && name[letters] >= 'A' && name[letters] <= 'Z' {
+		letters++
+	}
+
+	if letters == 0 || letters > 3 || letters == len(name) || name[letters] == '0' {
+		return Cell{}, fmt.Errorf("%w %q", ErrReference, s)
+	}
+
+	row, err := strconv.Atoi(name[letters:])
+	if err != nil || row > maxRows {
+		return Cell{}, fmt.Errorf("%w %q", ErrReference, s)
+	}
+
+	col := 0
+	for _, letter := range name[:letters] {
+		col = col*26 + int(letter-'A') + 1
+	}
+
+	if col > maxCols {
+		return Cell{}, fmt.Errorf("%w %q", ErrReference, s)
+	}
+
+	return Cell{Row: row - 1, Col: col - 1}, nil
+}
+
+func (c Cell) String() string {
+	return Column(c.Col) + strconv.Itoa(c.Row+1)
+}
+
+// Column is the name of the column col counting from 0: A, B, ..., Z, AA
+func Column(col int) string {
+	var name []byte
+
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = append([]byte{byte('A' + (col-1)%26)}, name...)
+	}
+
+	return string(name)
+}
+
+// Range is the rectangle of cells between two corners, A1:B3
+type Range struct {
+	From Cell
+	To   Cell
+}
+
+func (r Range) String() string {
+	return r.From.String() + ":" + r.To.String()
+}
+
+// the nodes of the formulas
+type (
+	expr interface{}
+
+	number int
+	ref    Cell
+	// rng is only allowed as an argument of a function
+	rng   Range
+	unary struct {
+		operand expr
+	}
+	binary struct {
+		op          rune
+		left, right expr
+	}
+	call struct {
+		name string
+		args []expr
+	}
+)
+
+// parse reads the formula of a cell without the leading =: integers,
+// references, ranges, functions, + - * / ^ and parentheses
+func parse(s string) (expr, error) {
+	p := &parser{input: []rune(s)}
+
+	e, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	if err := checkRanges(e, false); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// checkRanges fails on the ranges that are not arguments of a function
+func checkRanges(e expr, argument bool) error {
+	switch e := e.(type) {
+	case rng:
+		if !argument {
+			return fmt.Errorf("%w: range %s outside a function", ErrInvalidFormula, Range(e))
+		}
+	case unary:
+		return checkRanges(e.operand, false)
+	case binary:
+		if err := checkRanges(e.left, false); err != nil {
+			return err
+		}
+
+		return checkRanges(e.right, false)
+	case call:
+		for _, arg := range e.args {
+			if err := checkRanges(arg, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w at %d: %s", ErrInvalidFormula, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// peek returns the next non blank rune, 0 at the end of the input
+func (p *parser) peek() rune {
+	p.skipSpaces()
+
+	if p.pos >= len(p.input) {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *parser) sum() (expr, error) {
+	e, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+
+		if op != '+' && op != '-' {
+			return e, nil
+		}
+
+		p.pos++
+
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+
+		e = binary{op: op, left: e, right: right}
+	}
+}
+
+func (p *parser) product() (expr, error) {
+	e, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+
+		if op != '*' && op != '/' {
+			return e, nil
+		}
+
+		p.pos++
+
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		e = binary{op: op, left: e, right: right}
+	}
+}
+
+func (p *parser) unary() (expr, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return unary{operand: e}, nil
+	case '+':
+		p.pos++
+
+		return p.unary()
+	}
+
+	return p.power()
+}
+
+func (p *parser) power() (expr, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek() != '^' {
+		return base, nil
+	}
+
+	p.pos++
+
+	// right associative: 2^3^2 is 2^(3^2)
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	return binary{op: '^', left: base, right: exponent}, nil
+}
+
+func (p *parser) primary() (expr, error) {
+	r := p.peek()
+
+	switch {
+	case r == '(':
+		p.pos++
+
+		e, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ')' {
+			return nil, p.errorf("missing )")
+		}
+
+		p.pos++
+
+		return e, nil
+	case unicode.IsDigit(r):
+		return p.number()
+	case isNameRune(r):
+		return p.name()
+	case r == 0:
+		return nil, p.errorf("unexpected end")
+	}
+
+	return nil, p.errorf("unexpected %q", r)
+}
+
+func (p *parser) number() (expr, error) {
+	start := p.pos
+
+	for p.pos < len(p.input) && unicode.IsDigit(p.input[p.pos]) {
+		p.pos++
+	}
+
+	value, err := strconv.Atoi(string(p.input[start:p.pos]))
+	if err != nil {
+		return nil, p.errorf("%s", err)
+	}
+
+	return number(value), nil
+}
+
+// name reads a function call, a reference or a range
+func (p *parser) name() (expr, error) {
+	start := p.pos
+
+	for p.pos < len(p.input) && isNameRune(p.input[p.pos]) {
+		p.pos++
+	}
+
+	name := string(p.input[start:p.pos])
+
+	if p.peek() == '(' {
+		p.pos++
+
+		return p.call(strings.ToUpper(name))
+	}
+
+	from, err := ParseCell(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek() != ':' {
+		return ref(from), nil
+	}
+
+	p.pos++
+	p.skipSpaces()
+
+	start = p.pos
+
+	for p.pos < len(p.input) && isNameRune(p.input[p.pos]) {
+		p.pos++
+	}
+
+	to, err := ParseCell(string(p.input[start:p.pos]))
+	if err != nil {
+		return nil, err
+	}
+
+	return newRange(from, to), nil
+}
+
+// call reads the arguments of a function after its (
+func (p *parser) call(name string) (expr, error) {
+	c := call{name: name}
+
+	if p.peek() == ')' {
+		p.pos++
+
+		return c, nil
+	}
+
+	for {
+		arg, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+
+		c.args = append(c.args, arg)
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+
+			return c, nil
+		default:
+			return nil, p.errorf("missing ) after the arguments of %s", name)
+		}
+	}
+}
+
+// newRange orders the corners, B3:A1 is A1:B3
+func newRange(a, b Cell) rng {
+	r := Range{From: a, To: b}
+
+	if r.From.Row > r.To.Row {
+		r.From.Row, r.To.Row = r.To.Row, r.From.Row
+	}
+
+	if r.From.Col > r.To.Col {
+		r.From.Col, r.To.Col = r.To.Col, r.From.Col
+	}
+
+	return rng(r)
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
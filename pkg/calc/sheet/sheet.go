@@ -0,0 +1,475 @@
+package sheet
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+var (
+	ErrCycle          = errors.New("circular reference")
+	ErrInvalidFormula = errors.New("invalid formula")
+	ErrReference      = errors.New("invalid reference")
+)
+
+// CellError is a formula failing, Err is the *CellError of the
+// referenced cell when the failure comes from there
+type CellError struct {
+	Cell Cell
+	Err  error
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Cell, e.Err)
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// Marker is what the evaluated grid shows in place of a failed formula
+func Marker(err error) string {
+	switch {
+	case errors.Is(err, ErrCycle):
+		return "#CYCLE!"
+	case errors.Is(err, calc.ErrDivisionByZero):
+		return "#DIV/0!"
+	case errors.Is(err, calc.ErrOverflow):
+		return "#NUM!"
+	case errors.Is(err, calc.ErrInvalidOperand):
+		return "#VALUE!"
+	case errors.Is(err, ErrReference):
+		return "#REF!"
+	case errors.Is(err, calc.ErrUnknownOperation):
+		return "#NAME?"
+	}
+
+	return "#ERROR!"
+}
+
+// Sheet is a grid of cells, the cells starting with = are formulas and
+// the others integers, text or blank
+type Sheet struct {
+	cells [][]string
+}
+
+func New(cells [][]string) *Sheet {
+	return &Sheet{cells: cells}
+}
+
+// Read reads a sheet from CSV, the first record is the row 1. Comma
+// separates the fields, ',' when zero
+func Read(r io.Reader, comma rune) (*Sheet, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	if comma != 0 {
+		reader.Comma = comma
+	}
+
+	cells, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cells) > 0 && len(cells[0]) > 0 {
+		cells[0][0] = strings.TrimPrefix(cells[0][0], "\ufeff")
+	}
+
+	return New(cells), nil
+}
+
+type Options struct {
+	// Registry holds the functions other than SUM, AVG, MIN, MAX and
+	// COUNT, called by their upper case name: POW(2, 10). calc.Default
+	// when nil
+	Registry *calc.Registry
+	// Rounding of AVG, the exact average is rounded to an integer like
+	// the averages of pkg/calc/table, half to even when empty
+	Rounding calc.Rounding
+}
+
+type Result struct {
+	// Values is the grid with the formulas replaced by their values, or
+	// by the Marker of their error
+	Values [][]string
+	// Errors of the failed formulas, row by row
+	Errors []*CellError
+	// Formulas are the cells holding a formula, row by row
+	Formulas []Cell
+}
+
+// Evaluate computes the formulas in the order of their dependencies, the
+// cells of a cycle fail with ErrCycle and the cells using a failed cell
+// fail too. The arithmetic is the one of pkg/calc so calc.Checked
+// contexts fail on overflow, the error is only returned once ctx is done
+func (s *Sheet) Evaluate(ctx context.Context, opts Options) (*Result, error) {
+	e := &evaluation{
+		ctx:      ctx,
+		registry: opts.Registry,
+		rounding: opts.Rounding,
+		cells:    s.cells,
+		formulas: map[Cell]expr{},
+		values:   map[Cell]int{},
+		errs:     map[Cell]*CellError{},
+	}
+
+	if e.registry == nil {
+		e.registry = calc.Default
+	}
+
+	for _, c := range s.positions() {
+		text := strings.TrimSpace(s.cells[c.Row][c.Col])
+
+		if !strings.HasPrefix(text, "=") {
+			continue
+		}
+
+		formula, err := parse(text[1:])
+		if err != nil {
+			e.errs[c] = &CellError{Cell: c, Err: err}
+			continue
+		}
+
+		e.formulas[c] = formula
+	}
+
+	for _, c := range e.order(s.positions()) {
+		if _, failed := e.errs[c]; failed {
+			continue
+		}
+
+		value, err := e.eval(e.formulas[c])
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+
+			e.errs[c] = &CellError{Cell: c, Err: err}
+
+			continue
+		}
+
+		e.values[c] = value
+	}
+
+	result := &Result{Values: make([][]string, len(s.cells))}
+
+	for i, row := range s.cells {
+		result.Values[i] = append([]string{}, row...)
+	}
+
+	for _, c := range s.positions() {
+		if _, ok := e.formulas[c]; ok || e.errs[c] != nil {
+			result.Formulas = append(result.Formulas, c)
+		}
+
+		if err, failed := e.errs[c]; failed {
+			result.Values[c.Row][c.Col] = Marker(err)
+			result.Errors = append(result.Errors, err)
+		} else if value, ok := e.values[c]; ok {
+			result.Values[c.Row][c.Col] = strconv.Itoa(value)
+		}
+	}
+
+	return result, nil
+}
+
+// Text is the content of c as read, blank outside the grid
+func (s *Sheet) Text(c Cell) string {
+	if c.Row >= len(s.cells) || c.Col >= len(s.cells[c.Row]) {
+		return ""
+	}
+
+	return strings.TrimSpace(s.cells[c.Row][c.Col])
+}
+
+// positions lists the cells row by row
+func (s *Sheet) positions() []Cell {
+	var cells []Cell
+
+	for row := range s.cells {
+		for col := range s.cells[row] {
+			cells = append(cells, Cell{Row: row, Col: col})
+		}
+	}
+
+	return cells
+}
+
+type evaluation struct {
+	ctx      context.Context
+	registry *calc.Registry
+	rounding calc.Rounding
+	cells    [][]string
+	formulas map[Cell]expr
+	values   map[Cell]int
+	errs     map[Cell]*CellError
+}
+
+// order sorts the formulas so every one comes after the formulas it
+// references, a depth first search marking the cycles it meets
+func (e *evaluation) order(cells []Cell) []Cell {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := map[Cell]int{}
+	stack := []Cell{}
+	order := make([]Cell, 0, len(e.formulas))
+
+	var visit func(c Cell)
+
+	visit = func(c Cell) {
+		state[c] = visiting
+		stack = append(stack, c)
+
+		for _, dep := range e.dependencies(e.formulas[c]) {
+			switch state[dep] {
+			case visiting:
+				e.cycle(stack, dep)
+			case 0:
+				visit(dep)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[c] = visited
+		order = append(order, c)
+	}
+
+	for _, c := range cells {
+		if _, ok := e.formulas[c]; ok && state[c] == 0 {
+			visit(c)
+		}
+	}
+
+	return order
+}
+
+// cycle fails the cells of stack from start on, they reference each other
+func (e *evaluation) cycle(stack []Cell, start Cell) {
+	from := len(stack) - 1
+	for stack[from] != start {
+		from--
+	}
+
+	path := make([]string, 0, len(stack)-from+1)
+
+	for _, c := range stack[from:] {
+		path = append(path, c.String())
+	}
+
+	path = append(path, start.String())
+
+	for _, c := range stack[from:] {
+		if _, failed := e.errs[c]; !failed {
+			e.errs[c] = &CellError{Cell: c, Err: fmt.Errorf("%w %s", ErrCycle, strings.Join(path, " -> "))}
+		}
+	}
+}
+
+// dependencies are the formulas x references
+func (e *evaluation) dependencies(x expr) []Cell {
+	var deps []Cell
+
+	switch x := x.(type) {
+	case ref:
+		if _, ok := e.formulas[Cell(x)]; ok {
+			deps = append(deps, Cell(x))
+		}
+	case rng:
+		for _, c := range e.within(Range(x)) {
+			if _, ok := e.formulas[c]; ok {
+				deps = append(deps, c)
+			}
+		}
+	case unary:
+		deps = e.dependencies(x.operand)
+	case binary:
+		deps = append(e.dependencies(x.left), e.dependencies(x.right)...)
+	case call:
+		for _, arg := range x.args {
+			deps = append(deps, e.dependencies(arg)...)
+		}
+	}
+
+	return deps
+}
+
+// within lists the cells of r inside the grid, the others are blank
+func (e *evaluation) within(r Range) []Cell {
+	var cells []Cell
+
+	for row := r.From.Row; row <= r.To.Row && row < len(e.cells); row++ {
+		for col := r.From.Col; col <= r.To.Col && col < len(e.cells[row]); col++ {
+			cells = append(cells, Cell{Row: row, Col: col})
+		}
+	}
+
+	return cells
+}
+
+// value is the integer of a cell, blank cells are 0
+func (e *evaluation) value(c Cell) (int, error) {
+	if err, failed := e.errs[c]; failed {
+		return 0, err
+	}
+
+	if value, ok := e.values[c]; ok {
+		return value, nil
+	}
+
+	if c.Row >= len(e.cells) || c.Col >= len(e.cells[c.Row]) {
+		return 0, nil
+	}
+
+	text := strings.TrimSpace(e.cells[c.Row][c.Col])
+	if text == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, &calc.OperandError{Operand: text, Err: fmt.Errorf("cell %s is not an integer", c)}
+	}
+
+	return value, nil
+}
+
+func (e *evaluation) eval(x expr) (int, error) {
+	switch x := x.(type) {
+	case number:
+		return int(x), nil
+	case ref:
+		return e.value(Cell(x))
+	case unary:
+		operand, err := e.eval(x.operand)
+		if err != nil {
+			return 0, err
+		}
+
+		return calc.SubContext(e.ctx, 0, operand)
+	case binary:
+		left, err := e.eval(x.left)
+		if err != nil {
+			return 0, err
+		}
+
+		right, err := e.eval(x.right)
+		if err != nil {
+			return 0, err
+		}
+
+		switch x.op {
+		case '+':
+			return calc.SumContext(e.ctx, left, right)
+		case '-':
+			return calc.SubContext(e.ctx, left, right)
+		case '*':
+			return calc.MulContext(e.ctx, left, right)
+		case '/':
+			return calc.DivContext(e.ctx, left, right)
+		case '^':
+			return calc.PowContext(e.ctx, left, right)
+		}
+	case call:
+		return e.call(x)
+	}
+
+	return 0, fmt.Errorf("cannot evaluate %v", x)
+}
+
+// call evaluates a function, the blank and text cells of the ranges are
+// left out of its operands
+func (e *evaluation) call(c call) (int, error) {
+	var operands []int
+
+	for _, arg := range c.args {
+		r, ok := arg.(rng)
+		if !ok {
+			value, err := e.eval(arg)
+			if err != nil {
+				return 0, err
+			}
+
+			operands = append(operands, value)
+
+			continue
+		}
+
+		for _, cell := range e.within(Range(r)) {
+			if _, formula := e.formulas[cell]; !formula && e.errs[cell] == nil {
+				if value, err := strconv.Atoi(strings.TrimSpace(e.cells[cell.Row][cell.Col])); err == nil {
+					operands = append(operands, value)
+				}
+
+				continue
+			}
+
+			value, err := e.value(cell)
+			if err != nil {
+				return 0, err
+			}
+
+			operands = append(operands, value)
+		}
+	}
+
+	switch c.name {
+	case "SUM", "AVG":
+		var sum int
+
+		for _, operand := range operands {
+			var err error
+
+			if sum, err = calc.SumContext(e.ctx, sum, operand); err != nil {
+				return 0, err
+			}
+		}
+
+		if c.name == "AVG" {
+			return e.average(sum, len(operands))
+		}
+
+		return sum, nil
+	case "MIN", "MAX":
+		if len(operands) == 0 {
+			return 0, nil
+		}
+
+		extreme := operands[0]
+
+		for _, operand := range operands[1:] {
+			if (c.name == "MIN") == (operand < extreme) {
+				extreme = operand
+			}
+		}
+
+		return extreme, nil
+	case "COUNT":
+		return len(operands), nil
+	}
+
+	return e.registry.Eval(e.ctx, strings.ToLower(c.name), operands...)
+}
+
+// average is sum / count rounded with the rounding of the evaluation, no
+// operands divide by zero
+func (e *evaluation) average(sum, count int) (int, error) {
+	if count == 0 {
+		return 0, calc.ErrDivisionByZero
+	}
+
+	avg := e.rounding.Round(big.NewRat(int64(sum), int64(count)), 0)
+
+	return int(avg.Num().Int64()), nil
+}
@@ -0,0 +1,238 @@
+package sheet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+func TestParseCell(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected Cell
+	}
+
+	cases := []testCase{
+		{input: "A1", expected: Cell{Row: 0, Col: 0}},
+		{input: "b3", expected: Cell{Row: 2, Col: 1}},
+		{input: "$AA$10", expected: Cell{Row: 9, Col: 26}},
+		{input: "XFD1048576", expected: Cell{Row: 1048575, Col: 16383}},
+	}
+
+	for _, tc := range cases {
+		c, err := ParseCell(tc.input)
+		if err != nil {
+			t.Errorf("%s: %v", tc.input, err)
+			continue
+		}
+
+		assert.Equal(t, c, tc.expected)
+		assert.Equal(t, strings.ToUpper(strings.ReplaceAll(tc.input, "$", "")), c.String())
+	}
+
+	for _, invalid := range []string{"", "A", "1", "A0", "XFE1", "A1048577", "ABCD1", "A1B"} {
+		if _, err := ParseCell(invalid); !errors.Is(err, ErrReference) {
+			t.Errorf("%q: expected an invalid reference, got %v", invalid, err)
+		}
+	}
+}
+
+func TestColumn(t *testing.T) {
+	for col, expected := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, Column(col), expected)
+	}
+}
+
+func evaluate(t *testing.T, ctx context.Context, csv string) *Result {
+	t.Helper()
+
+	s, err := Read(strings.NewReader(csv), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Evaluate(ctx, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return result
+}
+
+func grid(result *Result) string {
+	var b bytes.Buffer
+
+	_ = result.WriteCSV(&b, 0)
+
+	return b.String()
+}
+
+func TestEvaluate(t *testing.T) {
+	type testCase struct {
+		name     string
+		input    string
+		expected string
+	}
+
+	cases := []testCase{
+		{
+			name:  "orders",
+			input: "\ufeffitem,price,qty,total\napple,3,4,=B2*C2\npear,5,2,= b3 * c3\ntotal,=SUM(B2:B3),=SUM(C2:C3),=SUM(D2:D3)\n",
+			expected: `item,price,qty,total
+apple,3,4,12
+pear,5,2,10
+total,8,6,22
+`,
+		},
+		{
+			// the formulas reference cells below and on the right
+			name:     "order",
+			input:    "=B1+1,=C1*2,=A2^2\n3\n",
+			expected: "19,18,9\n3\n",
+		},
+		{
+			name:     "precedence",
+			input:    "=-2^2,=2^3^2,=(1+2)*3-4/2,=7/-2,=--1\n",
+			expected: "-4,512,7,-3,1\n",
+		},
+		{
+			// blank and text cells are left out of the ranges
+			name:     "aggregates",
+			input:    "1,x,,4\n=AVG(A1:D1),\"=MIN(A1:D1,-1)\",=MAX(D1:A1),=COUNT(A1:D1)\n=SUM(A1:D1)/E1,=MIN(),=AVG(B1:C1),\"=POW(2,A1+9)\"\n",
+			expected: "1,x,,4\n2,-1,4,2\n#DIV/0!,0,#DIV/0!,1024\n",
+		},
+		{
+			name:     "errors",
+			input:    "x,=A1+1,=B1*2,=1/0,=SUM(D1:D1)\n=NOPE(1),=POW(1),=A1:A2,=,=ZZZZ1\n",
+			expected: "x,#VALUE!,#VALUE!,#DIV/0!,#DIV/0!\n#NAME?,#ERROR!,#ERROR!,#ERROR!,#REF!\n",
+		},
+		{
+			name:     "cycle",
+			input:    "=B1,=A1,=A1+1,=D1,=SUM(A2:E2)\n1,=E1\n",
+			expected: "#CYCLE!,#CYCLE!,#CYCLE!,#CYCLE!,#CYCLE!\n1,#CYCLE!\n",
+		},
+	}
+
+	for _, tc := range cases {
+		result := evaluate(t, context.Background(), tc.input)
+
+		if got := grid(result); got != tc.expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	result := evaluate(t, context.Background(), "=B1,=A1,=A1+1,=D1\n,=0/0\n")
+
+	messages := make([]string, 0, len(result.Errors))
+
+	for _, err := range result.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	assert.Equal(t, strings.Join(messages, "\n"), strings.Join([]string{
+		"A1: circular reference A1 -> B1 -> A1",
+		"B1: circular reference A1 -> B1 -> A1",
+		"C1: A1: circular reference A1 -> B1 -> A1",
+		"D1: circular reference D1 -> D1",
+		"B2: division by zero",
+	}, "\n"))
+
+	assert.Equal(t, errors.Is(result.Errors[2], ErrCycle), true)
+	assert.Equal(t, errors.Is(result.Errors[4], calc.ErrDivisionByZero), true)
+
+	max := strconv.Itoa(math.MaxInt)
+
+	wrapped := evaluate(t, context.Background(), max+",=A1+1\n")
+	assert.Equal(t, grid(wrapped), max+","+strconv.Itoa(math.MinInt)+"\n")
+
+	checked := evaluate(t, calc.Checked(context.Background()), max+",=A1+1,=SUM(A1:A1)*2\n")
+	assert.Equal(t, grid(checked), max+",#NUM!,#NUM!\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s, err := Read(strings.NewReader("1,=A1+1\n"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Evaluate(ctx, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the evaluation to be canceled, got %v", err)
+	}
+}
+
+func TestAverage(t *testing.T) {
+	s := New([][]string{{"1", "2", "4", "=AVG(A1:B1)", "=AVG(A1:C1)", "=AVG(B1:C1,-1)"}})
+
+	averages := map[calc.Rounding]string{
+		"":            "1,2,4,2,2,2\n",
+		calc.HalfEven: "1,2,4,2,2,2\n",
+		calc.HalfUp:   "1,2,4,2,2,2\n",
+		calc.Down:     "1,2,4,1,2,1\n",
+	}
+
+	for rounding, expected := range averages {
+		result, err := s.Evaluate(context.Background(), Options{Rounding: rounding})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, grid(result), expected)
+	}
+
+	result, err := New([][]string{{"1", "4", "=AVG(A1:B1)"}}).Evaluate(context.Background(), Options{Rounding: calc.HalfUp})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, grid(result), "1,4,3\n")
+	assert.Equal(t, len(result.Formulas), 1)
+	assert.Equal(t, result.Formulas[0].String(), "C1")
+}
+
+func TestRegistry(t *testing.T) {
+	registry := calc.NewRegistry()
+
+	err := registry.Register(calc.NewOperation("twice", 1, "doubles", func(ctx context.Context, operands ...int) (int, error) {
+		return calc.MulContext(ctx, operands[0], 2)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New([][]string{{"21", "=TWICE(A1)", "=POW(2,2)"}})
+
+	result, err := s.Evaluate(context.Background(), Options{Registry: registry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, grid(result), "21,42,#NAME?\n")
+}
+
+func TestWriteTable(t *testing.T) {
+	result := evaluate(t, context.Background(), "item,qty\napple,=3*4\n,=1/0\nbanana,1000\n,,,,,,,,,,x\n")
+
+	var b bytes.Buffer
+
+	if err := result.WriteTable(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, b.String(), `   A       B        C  D  E  F  G  H  I  J  K
+1  item    qty
+2  apple        12
+3          #DIV/0!
+4  banana     1000
+5                                           x
+`)
+}
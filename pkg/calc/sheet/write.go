@@ -0,0 +1,102 @@
+package sheet
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WriteCSV writes the evaluated grid, comma is ',' when zero
+func (r *Result) WriteCSV(w io.Writer, comma rune) error {
+	writer := csv.NewWriter(w)
+
+	if comma != 0 {
+		writer.Comma = comma
+	}
+
+	if err := writer.WriteAll(r.Values); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+// WriteTable writes the evaluated grid as a table headed by the column
+// names and the row numbers, the integers are aligned to the right
+func (r *Result) WriteTable(w io.Writer) error {
+	cols := 0
+
+	for _, row := range r.Values {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+
+	widths := make([]int, cols)
+
+	for col := range widths {
+		widths[col] = len(Column(col))
+	}
+
+	for _, row := range r.Values {
+		for col, value := range row {
+			if n := utf8.RuneCountInString(value); n > widths[col] {
+				widths[col] = n
+			}
+		}
+	}
+
+	numbers := len(strconv.Itoa(len(r.Values)))
+
+	b := bufio.NewWriter(w)
+
+	line := func(label string, cells []string, right func(string) bool) {
+		var sb strings.Builder
+
+		sb.WriteString(strings.Repeat(" ", numbers-len(label)))
+		sb.WriteString(label)
+
+		for col, width := range widths {
+			value := ""
+			if col < len(cells) {
+				value = cells[col]
+			}
+
+			padding := strings.Repeat(" ", width-utf8.RuneCountInString(value))
+
+			sb.WriteString("  ")
+
+			if right(value) {
+				sb.WriteString(padding + value)
+			} else {
+				sb.WriteString(value + padding)
+			}
+		}
+
+		b.WriteString(strings.TrimRight(sb.String(), " "))
+		b.WriteString("\n")
+	}
+
+	names := make([]string, cols)
+
+	for col := range names {
+		names[col] = Column(col)
+	}
+
+	line("", names, func(string) bool { return false })
+
+	for i, row := range r.Values {
+		line(strconv.Itoa(i+1), row, isInteger)
+	}
+
+	return b.Flush()
+}
+
+func isInteger(s string) bool {
+	_, err := strconv.Atoi(strings.TrimSpace(s))
+
+	return err == nil
+}
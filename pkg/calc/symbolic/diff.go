@@ -0,0 +1,115 @@
+package symbolic
+
+import (
+	"context"
+	"fmt"
+)
+
+// Diff differentiates e with respect to variable and simplifies the result
+func Diff(e Expr, variable string) (Expr, error) {
+	return DiffContext(context.Background(), e, variable)
+}
+
+// DiffContext is Diff simplifying the result with SimplifyContext
+func DiffContext(ctx context.Context, e Expr, variable string) (Expr, error) {
+	d, err := derive(e, variable)
+	if err != nil {
+		return nil, err
+	}
+
+	return SimplifyContext(ctx, d)
+}
+
+func derive(e Expr, variable string) (Expr, error) {
+	switch e := e.(type) {
+	case Const:
+		return Const{Value: 0}, nil
+	case Var:
+		if e.Name == variable {
+			return Const{Value: 1}, nil
+		}
+
+		return Const{Value: 0}, nil
+	case Sum:
+		terms := make([]Expr, 0, len(e.Terms))
+
+		for _, term := range e.Terms {
+			d, err := derive(term, variable)
+			if err != nil {
+				return nil, err
+			}
+
+			terms = append(terms, d)
+		}
+
+		return Sum{Terms: terms}, nil
+	case Product:
+		return deriveProduct(e, variable)
+	case Quotient:
+		return deriveQuotient(e, variable)
+	case Power:
+		return derivePower(e, variable)
+	}
+
+	return nil, fmt.Errorf("cannot differentiate %s", e)
+}
+
+// (f*g*h)' = f'*g*h + f*g'*h + f*g*h'
+func deriveProduct(p Product, variable string) (Expr, error) {
+	terms := make([]Expr, 0, len(p.Factors))
+
+	for i := range p.Factors {
+		d, err := derive(p.Factors[i], variable)
+		if err != nil {
+			return nil, err
+		}
+
+		factors := make([]Expr, 0, len(p.Factors))
+		factors = append(factors, p.Factors[:i]...)
+		factors = append(factors, d)
+		factors = append(factors, p.Factors[i+1:]...)
+
+		terms = append(terms, Product{Factors: factors})
+	}
+
+	return Sum{Terms: terms}, nil
+}
+
+// (u/v)' = (u'*v - u*v')/v^2
+func deriveQuotient(q Quotient, variable string) (Expr, error) {
+	du, err := derive(q.Num, variable)
+	if err != nil {
+		return nil, err
+	}
+
+	dv, err := derive(q.Den, variable)
+	if err != nil {
+		return nil, err
+	}
+
+	return Quotient{
+		Num: Sum{Terms: []Expr{
+			Product{Factors: []Expr{du, q.Den}},
+			Product{Factors: []Expr{Const{Value: -1}, q.Num, dv}},
+		}},
+		Den: Power{Base: q.Den, Exponent: Const{Value: 2}},
+	}, nil
+}
+
+// (u^n)' = n*u^(n-1)*u', the chain rule applies to any inner u
+func derivePower(p Power, variable string) (Expr, error) {
+	if dependsOn(p.Exponent, variable) {
+		return nil, fmt.Errorf("cannot differentiate %s: the exponent depends on %s", p, variable)
+	}
+
+	du, err := derive(p.Base, variable)
+	if err != nil {
+		return nil, err
+	}
+
+	return Product{Factors: []Expr{
+		p.Exponent,
+		Power{Base: p.Base, Exponent: Sum{Terms: []Expr{p.Exponent, Const{Value: -1}}}},
+		du,
+	}}, nil
+}
@@ -0,0 +1,225 @@
+package symbolic
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// Parse reads an expression made of integers, variables, + - * / ^ and
+// parentheses, a number followed by a variable is a product (3x^2)
+func Parse(s string) (Expr, error) {
+	p := &parser{input: []rune(s)}
+
+	e, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	return e, nil
+}
+
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid expression at %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// peek returns the next non blank rune, 0 at the end of the input
+func (p *parser) peek() rune {
+	p.skipSpaces()
+
+	if p.pos >= len(p.input) {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *parser) sum() (Expr, error) {
+	first, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+
+	terms := []Expr{first}
+
+	for {
+		op := p.peek()
+
+		if op != '+' && op != '-' {
+			break
+		}
+
+		p.pos++
+
+		term, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+
+		if op == '-' {
+			term = Product{Factors: []Expr{Const{Value: -1}, term}}
+		}
+
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 1 {
+		return first, nil
+	}
+
+	return Sum{Terms: terms}, nil
+}
+
+func (p *parser) product() (Expr, error) {
+	e, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+
+		if op != '*' && op != '/' {
+			break
+		}
+
+		p.pos++
+
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		if op == '/' {
+			e = Quotient{Num: e, Den: right}
+		} else {
+			e = Product{Factors: []Expr{e, right}}
+		}
+	}
+
+	return e, nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.peek() == '-' {
+		p.pos++
+
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return Product{Factors: []Expr{Const{Value: -1}, e}}, nil
+	}
+
+	return p.power()
+}
+
+func (p *parser) power() (Expr, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek() != '^' {
+		return base, nil
+	}
+
+	p.pos++
+
+	// right associative: 2^3^2 is 2^(3^2)
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	return Power{Base: base, Exponent: exponent}, nil
+}
+
+func (p *parser) primary() (Expr, error) {
+	r := p.peek()
+
+	switch {
+	case r == '(':
+		p.pos++
+
+		e, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ')' {
+			return nil, p.errorf("missing )")
+		}
+
+		p.pos++
+
+		return e, nil
+	case unicode.IsDigit(r):
+		return p.number()
+	case isIdentStart(r):
+		return p.variable(), nil
+	case r == 0:
+		return nil, p.errorf("unexpected end")
+	}
+
+	return nil, p.errorf("unexpected %q", r)
+}
+
+func (p *parser) number() (Expr, error) {
+	start := p.pos
+
+	for p.pos < len(p.input) && unicode.IsDigit(p.input[p.pos]) {
+		p.pos++
+	}
+
+	value, err := strconv.Atoi(string(p.input[start:p.pos]))
+	if err != nil {
+		return nil, p.errorf("%s", err)
+	}
+
+	c := Const{Value: value}
+
+	// implicit product: 3x, 2(x + 1)
+	if p.pos < len(p.input) && (isIdentStart(p.input[p.pos]) || p.input[p.pos] == '(') {
+		right, err := p.power()
+		if err != nil {
+			return nil, err
+		}
+
+		return Product{Factors: []Expr{c, right}}, nil
+	}
+
+	return c, nil
+}
+
+func (p *parser) variable() Expr {
+	start := p.pos
+
+	for p.pos < len(p.input) && (isIdentStart(p.input[p.pos]) || unicode.IsDigit(p.input[p.pos])) {
+		p.pos++
+	}
+
+	return Var{Name: string(p.input[start:p.pos])}
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
@@ -0,0 +1,271 @@
+package symbolic
+
+import (
+	"context"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+// Simplify folds constants, flattens nested sums and products and
+// collects like terms (2*x + 3*x) and like factors (x*x^2)
+func Simplify(e Expr) Expr {
+	sp := simplifier{ctx: context.Background()}
+
+	return sp.simplify(e)
+}
+
+// SimplifyContext is Simplify folding the constants with the calc
+// operations of ctx, failing with calc.ErrOverflow in calc.Checked
+// contexts and with ctx.Err() once ctx is done
+func SimplifyContext(ctx context.Context, e Expr) (Expr, error) {
+	sp := simplifier{ctx: ctx}
+
+	simplified := sp.simplify(e)
+	if sp.err != nil {
+		return nil, sp.err
+	}
+
+	return simplified, nil
+}
+
+// simplifier keeps the first error of the folded operations, the
+// simplification goes on with their results and is dropped at the end
+type simplifier struct {
+	ctx context.Context
+	err error
+}
+
+func (sp *simplifier) fold(result int, err error) int {
+	if err != nil && sp.err == nil {
+		sp.err = err
+	}
+
+	return result
+}
+
+func (sp *simplifier) simplify(e Expr) Expr {
+	switch e := e.(type) {
+	case Sum:
+		return sp.sum(e)
+	case Product:
+		return sp.product(e)
+	case Quotient:
+		return sp.quotient(e)
+	case Power:
+		return sp.power(e)
+	}
+
+	return e
+}
+
+func (sp *simplifier) sum(s Sum) Expr {
+	var constant int
+
+	// like terms keyed by their non constant part, in order of appearance
+	var keys []string
+	coefficients := map[string]int{}
+	parts := map[string]Expr{}
+
+	var collect func(e Expr)
+	collect = func(e Expr) {
+		switch e := e.(type) {
+		case Sum:
+			for _, term := range e.Terms {
+				collect(term)
+			}
+		case Const:
+			constant = sp.fold(calc.SumContext(sp.ctx, constant, e.Value))
+		default:
+			coefficient, part := splitCoefficient(e)
+			key := part.String()
+
+			if _, ok := parts[key]; !ok {
+				keys = append(keys, key)
+				parts[key] = part
+			}
+
+			coefficients[key] = sp.fold(calc.SumContext(sp.ctx, coefficients[key], coefficient))
+		}
+	}
+
+	for _, term := range s.Terms {
+		collect(sp.simplify(term))
+	}
+
+	terms := []Expr{}
+
+	for _, key := range keys {
+		switch coefficients[key] {
+		case 0:
+		case 1:
+			terms = append(terms, parts[key])
+		default:
+			terms = append(terms, sp.simplify(Product{Factors: []Expr{Const{Value: coefficients[key]}, parts[key]}}))
+		}
+	}
+
+	if constant != 0 {
+		terms = append(terms, Const{Value: constant})
+	}
+
+	switch len(terms) {
+	case 0:
+		return Const{Value: 0}
+	case 1:
+		return terms[0]
+	}
+
+	return Sum{Terms: terms}
+}
+
+// splitCoefficient separates the constant factor of a simplified term
+func splitCoefficient(e Expr) (int, Expr) {
+	p, ok := e.(Product)
+	if !ok {
+		return 1, e
+	}
+
+	c, ok := p.Factors[0].(Const)
+	if !ok {
+		return 1, e
+	}
+
+	if len(p.Factors) == 2 {
+		return c.Value, p.Factors[1]
+	}
+
+	return c.Value, Product{Factors: p.Factors[1:]}
+}
+
+func (sp *simplifier) product(p Product) Expr {
+	coefficient := 1
+
+	// exponents of like bases, in order of appearance
+	var keys []string
+	exponents := map[string][]Expr{}
+	bases := map[string]Expr{}
+
+	var collect func(e Expr)
+	collect = func(e Expr) {
+		switch e := e.(type) {
+		case Product:
+			for _, factor := range e.Factors {
+				collect(factor)
+			}
+		case Const:
+			coefficient = sp.fold(calc.MulContext(sp.ctx, coefficient, e.Value))
+		default:
+			base, exponent := e, Expr(Const{Value: 1})
+
+			if power, ok := e.(Power); ok {
+				base, exponent = power.Base, power.Exponent
+			}
+
+			key := base.String()
+
+			if _, ok := bases[key]; !ok {
+				keys = append(keys, key)
+				bases[key] = base
+			}
+
+			exponents[key] = append(exponents[key], exponent)
+		}
+	}
+
+	for _, factor := range p.Factors {
+		collect(sp.simplify(factor))
+	}
+
+	if coefficient == 0 {
+		return Const{Value: 0}
+	}
+
+	factors := []Expr{}
+
+	for _, key := range keys {
+		factor := sp.simplify(Power{Base: bases[key], Exponent: sp.simplify(Sum{Terms: exponents[key]})})
+
+		switch factor := factor.(type) {
+		case Const:
+			coefficient = sp.fold(calc.MulContext(sp.ctx, coefficient, factor.Value))
+		case Product:
+			// a folded power may carry its own coefficient
+			c, rest := splitCoefficient(factor)
+			coefficient = sp.fold(calc.MulContext(sp.ctx, coefficient, c))
+			factors = append(factors, rest)
+		default:
+			factors = append(factors, factor)
+		}
+	}
+
+	if coefficient == 0 {
+		return Const{Value: 0}
+	}
+
+	if len(factors) == 0 {
+		return Const{Value: coefficient}
+	}
+
+	if coefficient != 1 {
+		factors = append([]Expr{Const{Value: coefficient}}, factors...)
+	}
+
+	if len(factors) == 1 {
+		return factors[0]
+	}
+
+	return Product{Factors: factors}
+}
+
+func (sp *simplifier) quotient(q Quotient) Expr {
+	num := sp.simplify(q.Num)
+	den := sp.simplify(q.Den)
+
+	n, numConst := num.(Const)
+	d, denConst := den.(Const)
+
+	switch {
+	case denConst && d.Value == 0:
+		// keep it, evaluation reports the division by zero
+		return Quotient{Num: num, Den: den}
+	case numConst && n.Value == 0:
+		return Const{Value: 0}
+	case denConst && d.Value == 1:
+		return num
+	case num.String() == den.String():
+		return Const{Value: 1}
+	case numConst && denConst:
+		div := sp.fold(calc.DivContext(sp.ctx, n.Value, d.Value))
+		if sp.err == nil && sp.fold(calc.MulContext(sp.ctx, div, d.Value)) == n.Value {
+			return Const{Value: div}
+		}
+	}
+
+	return Quotient{Num: num, Den: den}
+}
+
+func (sp *simplifier) power(p Power) Expr {
+	base := sp.simplify(p.Base)
+	exponent := sp.simplify(p.Exponent)
+
+	e, expConst := exponent.(Const)
+	b, baseConst := base.(Const)
+
+	switch {
+	case expConst && e.Value == 0:
+		return Const{Value: 1}
+	case expConst && e.Value == 1:
+		return base
+	case baseConst && (b.Value == 0 || b.Value == 1) && expConst && e.Value > 0:
+		return base
+	case baseConst && expConst && e.Value > 0:
+		return Const{Value: sp.fold(calc.PowContext(sp.ctx, b.Value, e.Value))}
+	}
+
+	// (u^a)^b is u^(a*b)
+	if inner, ok := base.(Power); ok && expConst {
+		return sp.simplify(Power{Base: inner.Base, Exponent: Product{Factors: []Expr{inner.Exponent, exponent}}})
+	}
+
+	return Power{Base: base, Exponent: exponent}
+}
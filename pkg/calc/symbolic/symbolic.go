@@ -0,0 +1,412 @@
+package symbolic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+// Expr is a node of an expression tree
+type Expr interface {
+	String() string
+	precedence() int
+}
+
+const (
+	precSum = iota + 1
+	precProduct
+	precPower
+	precAtom
+)
+
+type Const struct {
+	Value int
+}
+
+type Var struct {
+	Name string
+}
+
+type Sum struct {
+	Terms []Expr
+}
+
+type Product struct {
+	Factors []Expr
+}
+
+type Quotient struct {
+	Num Expr
+	Den Expr
+}
+
+type Power struct {
+	Base     Expr
+	Exponent Expr
+}
+
+func (c Const) precedence() int {
+	if c.Value < 0 {
+		return precSum
+	}
+
+	return precAtom
+}
+
+func (v Var) precedence() int      { return precAtom }
+func (s Sum) precedence() int      { return precSum }
+func (p Product) precedence() int  { return precProduct }
+func (q Quotient) precedence() int { return precProduct }
+func (p Power) precedence() int    { return precPower }
+
+func (c Const) String() string {
+	return strconv.Itoa(c.Value)
+}
+
+func (v Var) String() string {
+	return v.Name
+}
+
+func (s Sum) String() string {
+	var b strings.Builder
+
+	for i, term := range s.Terms {
+		// print a - b rather than a + -1*b
+		negated, negative := negate(term)
+
+		switch {
+		case i == 0:
+			b.WriteString(term.String())
+			continue
+		case negative:
+			b.WriteString(" - ")
+			term = negated
+		default:
+			b.WriteString(" + ")
+		}
+
+		b.WriteString(parenthesize(term, precProduct))
+	}
+
+	return b.String()
+}
+
+func (p Product) String() string {
+	factors := p.Factors
+
+	prefix := ""
+
+	if c, ok := factors[0].(Const); ok && c.Value == -1 && len(factors) > 1 {
+		prefix = "-"
+		factors = factors[1:]
+	}
+
+	parts := make([]string, 0, len(factors))
+
+	for i, factor := range factors {
+		least := precProduct
+		if i > 0 || prefix != "" {
+			least = precPower
+		}
+
+		parts = append(parts, parenthesize(factor, least))
+	}
+
+	return prefix + strings.Join(parts, "*")
+}
+
+func (q Quotient) String() string {
+	return parenthesize(q.Num, precProduct) + "/" + parenthesize(q.Den, precPower)
+}
+
+func (p Power) String() string {
+	return parenthesize(p.Base, precAtom) + "^" + parenthesize(p.Exponent, precAtom)
+}
+
+// parenthesize wraps e when it binds less tightly than least
+func parenthesize(e Expr, least int) string {
+	if e.precedence() < least {
+		return "(" + e.String() + ")"
+	}
+
+	return e.String()
+}
+
+// negate returns -e when e carries a negative coefficient
+func negate(e Expr) (Expr, bool) {
+	switch e := e.(type) {
+	case Const:
+		if e.Value < 0 {
+			return Const{Value: -e.Value}, true
+		}
+	case Product:
+		if c, ok := e.Factors[0].(Const); ok && c.Value < 0 {
+			factors := append([]Expr{Const{Value: -c.Value}}, e.Factors[1:]...)
+
+			return Simplify(Product{Factors: factors}), true
+		}
+	}
+
+	return e, false
+}
+
+var ErrUnboundVariable = errors.New("unbound variable")
+
+// Eval computes e with pkg/calc integer arithmetic
+func Eval(e Expr, vars map[string]int) (int, error) {
+	return EvalContext(context.Background(), e, vars)
+}
+
+// EvalContext is Eval stopping once ctx is done and failing with
+// calc.ErrOverflow in calc.Checked contexts
+func EvalContext(ctx context.Context, e Expr, vars map[string]int) (int, error) {
+	switch e := e.(type) {
+	case Const:
+		return e.Value, nil
+	case Var:
+		value, ok := vars[e.Name]
+		if !ok {
+			return 0, fmt.Errorf("%w %s", ErrUnboundVariable, e.Name)
+		}
+
+		return value, nil
+	case Sum:
+		var sum int
+
+		for _, term := range e.Terms {
+			value, err := EvalContext(ctx, term, vars)
+			if err != nil {
+				return 0, err
+			}
+
+			if sum, err = calc.SumContext(ctx, sum, value); err != nil {
+				return 0, err
+			}
+		}
+
+		return sum, nil
+	case Product:
+		mul := 1
+
+		for _, factor := range e.Factors {
+			value, err := EvalContext(ctx, factor, vars)
+			if err != nil {
+				return 0, err
+			}
+
+			if mul, err = calc.MulContext(ctx, mul, value); err != nil {
+				return 0, err
+			}
+		}
+
+		return mul, nil
+	case Quotient:
+		num, err := EvalContext(ctx, e.Num, vars)
+		if err != nil {
+			return 0, err
+		}
+
+		den, err := EvalContext(ctx, e.Den, vars)
+		if err != nil {
+			return 0, err
+		}
+
+		return calc.DivContext(ctx, num, den)
+	case Power:
+		base, err := EvalContext(ctx, e.Base, vars)
+		if err != nil {
+			return 0, err
+		}
+
+		exponent, err := EvalContext(ctx, e.Exponent, vars)
+		if err != nil {
+			return 0, err
+		}
+
+		return calc.PowContext(ctx, base, exponent)
+	}
+
+	return 0, fmt.Errorf("cannot evaluate %s", e)
+}
+
+// the largest exponent EvalRat raises a value other than -1, 0 and 1 to
+const maxRatExponent = 1 << 12
+
+// EvalRat computes e exactly on fractions, for the values that are not
+// integers such as prices, it stops once ctx is done
+func EvalRat(ctx context.Context, e Expr, vars map[string]*big.Rat) (*big.Rat, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	switch e := e.(type) {
+	case Const:
+		return new(big.Rat).SetInt64(int64(e.Value)), nil
+	case Var:
+		value, ok := vars[e.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w %s", ErrUnboundVariable, e.Name)
+		}
+
+		return value, nil
+	case Sum:
+		sum := new(big.Rat)
+
+		for _, term := range e.Terms {
+			value, err := EvalRat(ctx, term, vars)
+			if err != nil {
+				return nil, err
+			}
+
+			sum.Add(sum, value)
+		}
+
+		return sum, nil
+	case Product:
+		mul := big.NewRat(1, 1)
+
+		for _, factor := range e.Factors {
+			value, err := EvalRat(ctx, factor, vars)
+			if err != nil {
+				return nil, err
+			}
+
+			mul.Mul(mul, value)
+		}
+
+		return mul, nil
+	case Quotient:
+		num, err := EvalRat(ctx, e.Num, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		den, err := EvalRat(ctx, e.Den, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		if den.Sign() == 0 {
+			return nil, calc.ErrDivisionByZero
+		}
+
+		return new(big.Rat).Quo(num, den), nil
+	case Power:
+		base, err := EvalRat(ctx, e.Base, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		exponent, err := EvalRat(ctx, e.Exponent, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		return powRat(base, exponent)
+	}
+
+	return nil, fmt.Errorf("cannot evaluate %s", e)
+}
+
+func powRat(base, exponent *big.Rat) (*big.Rat, error) {
+	if !exponent.IsInt() {
+		return nil, &calc.OperandError{Operand: exponent.RatString(), Err: errors.New("the exponent is not an integer")}
+	}
+
+	n := exponent.Num()
+
+	if base.Sign() == 0 && n.Sign() < 0 {
+		return nil, calc.ErrDivisionByZero
+	}
+
+	// 0, 1 and -1 stay small whatever the exponent
+	small := base.Sign() == 0 || base.IsInt() && base.Num().CmpAbs(big.NewInt(1)) == 0
+
+	if !small && n.CmpAbs(big.NewInt(maxRatExponent)) > 0 {
+		return nil, calc.ErrOverflow
+	}
+
+	abs := new(big.Int).Abs(n)
+
+	power := new(big.Rat).SetFrac(new(big.Int).Exp(base.Num(), abs, nil), new(big.Int).Exp(base.Denom(), abs, nil))
+
+	if n.Sign() < 0 {
+		power.Inv(power)
+	}
+
+	return power, nil
+}
+
+// Variables returns the sorted names of the variables of e
+func Variables(e Expr) []string {
+	seen := map[string]bool{}
+
+	var walk func(e Expr)
+
+	walk = func(e Expr) {
+		switch e := e.(type) {
+		case Var:
+			seen[e.Name] = true
+		case Sum:
+			for _, term := range e.Terms {
+				walk(term)
+			}
+		case Product:
+			for _, factor := range e.Factors {
+				walk(factor)
+			}
+		case Quotient:
+			walk(e.Num)
+			walk(e.Den)
+		case Power:
+			walk(e.Base)
+			walk(e.Exponent)
+		}
+	}
+
+	walk(e)
+
+	names := make([]string, 0, len(seen))
+
+	for name := range seen {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// dependsOn reports whether e references the variable
+func dependsOn(e Expr, variable string) bool {
+	switch e := e.(type) {
+	case Var:
+		return e.Name == variable
+	case Sum:
+		return anyDependsOn(e.Terms, variable)
+	case Product:
+		return anyDependsOn(e.Factors, variable)
+	case Quotient:
+		return dependsOn(e.Num, variable) || dependsOn(e.Den, variable)
+	case Power:
+		return dependsOn(e.Base, variable) || dependsOn(e.Exponent, variable)
+	}
+
+	return false
+}
+
+func anyDependsOn(exprs []Expr, variable string) bool {
+	for _, e := range exprs {
+		if dependsOn(e, variable) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,360 @@
+package symbolic
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+func TestParse(t *testing.T) {
+	type testCase struct {
+		input  string
+		output string
+		ok     bool
+	}
+
+	cases := []testCase{
+		{
+			input:  "x^3 + 2*x",
+			output: "x^3 + 2*x",
+			ok:     true,
+		},
+		{
+			input:  "3x^2 - (x + 1)",
+			output: "3*x^2 - (x + 1)",
+			ok:     true,
+		},
+		{
+			input:  "-x^2",
+			output: "-x^2",
+			ok:     true,
+		},
+		{
+			input:  "2^3^2",
+			output: "2^(3^2)",
+			ok:     true,
+		},
+		{
+			input: "x +",
+			ok:    false,
+		},
+		{
+			input: "(x + 1",
+			ok:    false,
+		},
+		{
+			input: "x $ 2",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		e, err := Parse(tc.input)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected parse error for %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, e.String(), tc.output)
+		}
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	type testCase struct {
+		input      string
+		simplified string
+	}
+
+	cases := []testCase{
+		{
+			input:      "2*x + 3*x",
+			simplified: "5*x",
+		},
+		{
+			input:      "x*x^2*3",
+			simplified: "3*x^3",
+		},
+		{
+			input:      "2 + 3*4 - 1",
+			simplified: "13",
+		},
+		{
+			input:      "x - x",
+			simplified: "0",
+		},
+		{
+			input:      "0*y + x^1 + y^0",
+			simplified: "x + 1",
+		},
+		{
+			input:      "(x^2)^3",
+			simplified: "x^6",
+		},
+		{
+			input:      "6/3 + x/x",
+			simplified: "3",
+		},
+		{
+			input:      "x - 2*y",
+			simplified: "x - 2*y",
+		},
+	}
+
+	for _, tc := range cases {
+		e, err := Parse(tc.input)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		assert.Equal(t, Simplify(e).String(), tc.simplified)
+	}
+}
+
+func TestSimplifyContext(t *testing.T) {
+	checked := calc.Checked(context.Background())
+
+	for _, input := range []string{"2^100", "x*9223372036854775807*2", "9223372036854775807 + x + 1"} {
+		e, err := Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := SimplifyContext(checked, e); !errors.Is(err, calc.ErrOverflow) {
+			t.Errorf("%s: expected an overflow, got %v", input, err)
+		}
+	}
+
+	e, _ := Parse("2^10 + 3*x + x")
+
+	simplified, err := SimplifyContext(checked, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, simplified.String(), "4*x + 1024")
+}
+
+func TestDiff(t *testing.T) {
+	type testCase struct {
+		input      string
+		variable   string
+		derivative string
+		ok         bool
+	}
+
+	cases := []testCase{
+		{
+			input:      "x^3 + 2*x",
+			variable:   "x",
+			derivative: "3*x^2 + 2",
+			ok:         true,
+		},
+		{
+			input:      "x*y + y^2",
+			variable:   "y",
+			derivative: "x + 2*y",
+			ok:         true,
+		},
+		{
+			// chain rule
+			input:      "(x^2 + 1)^3",
+			variable:   "x",
+			derivative: "6*(x^2 + 1)^2*x",
+			ok:         true,
+		},
+		{
+			// quotient rule
+			input:      "x/(x + 1)",
+			variable:   "x",
+			derivative: "1/(x + 1)^2",
+			ok:         true,
+		},
+		{
+			input:      "5",
+			variable:   "x",
+			derivative: "0",
+			ok:         true,
+		},
+		{
+			input:    "2^x",
+			variable: "x",
+			ok:       false,
+		},
+	}
+
+	for _, tc := range cases {
+		e, _ := Parse(tc.input)
+
+		d, err := Diff(e, tc.variable)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error differentiating %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, d.String(), tc.derivative)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	type testCase struct {
+		input string
+		vars  map[string]int
+		value int
+		ok    bool
+	}
+
+	cases := []testCase{
+		{
+			input: "3*x^2 + 2",
+			vars:  map[string]int{"x": 2},
+			value: 14,
+			ok:    true,
+		},
+		{
+			input: "x - 2*y",
+			vars:  map[string]int{"x": 1, "y": 3},
+			value: -5,
+			ok:    true,
+		},
+		{
+			input: "x/(y - 3)",
+			vars:  map[string]int{"x": 1, "y": 3},
+			ok:    false,
+		},
+		{
+			input: "x + z",
+			vars:  map[string]int{"x": 1},
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		e, _ := Parse(tc.input)
+
+		value, err := Eval(e, tc.vars)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error evaluating %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, value, tc.value)
+		}
+	}
+
+	_, err := Eval(Var{Name: "z"}, nil)
+	if !errors.Is(err, ErrUnboundVariable) {
+		t.Errorf("expected unbound variable, got %v", err)
+	}
+}
+
+func TestEvalContext(t *testing.T) {
+	e, _ := Parse("x * 2 + 1")
+
+	checked := calc.Checked(context.Background())
+
+	value, err := EvalContext(checked, e, map[string]int{"x": 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, value, 41)
+
+	_, err = EvalContext(checked, e, map[string]int{"x": math.MaxInt/2 + 1})
+	if !errors.Is(err, calc.ErrOverflow) {
+		t.Errorf("expected overflow, got %v", err)
+	}
+
+	// Eval wraps around like Go's int
+	value, err = Eval(e, map[string]int{"x": math.MaxInt/2 + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, value, math.MinInt+1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := EvalContext(ctx, e, map[string]int{"x": 1}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled, got %v", err)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	e, _ := Parse("total / (qty - discount) + qty^2 + 3")
+
+	assert.Equal(t, strings.Join(Variables(e), " "), "discount qty total")
+	assert.Equal(t, len(Variables(Const{Value: 1})), 0)
+}
+
+func TestEvalRat(t *testing.T) {
+	type testCase struct {
+		expr     string
+		vars     map[string]string
+		expected string
+		target   error
+	}
+
+	cases := []testCase{
+		{expr: "price * qty", vars: map[string]string{"price": "9.99", "qty": "3"}, expected: "2997/100"},
+		{expr: "x / 3", vars: map[string]string{"x": "1"}, expected: "1/3"},
+		{expr: "x^-2", vars: map[string]string{"x": "0.5"}, expected: "4"},
+		{expr: "(0 - 1)^x", vars: map[string]string{"x": "1000000001"}, expected: "-1"},
+		{expr: "x / (x - x)", vars: map[string]string{"x": "1.5"}, target: calc.ErrDivisionByZero},
+		{expr: "x^x", vars: map[string]string{"x": "0.5"}, target: calc.ErrInvalidOperand},
+		{expr: "2^x", vars: map[string]string{"x": "1000000"}, target: calc.ErrOverflow},
+	}
+
+	for _, tc := range cases {
+		e, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vars := map[string]*big.Rat{}
+		for name, value := range tc.vars {
+			vars[name], _ = new(big.Rat).SetString(value)
+		}
+
+		value, err := EvalRat(context.Background(), e, vars)
+
+		if tc.target != nil {
+			if !errors.Is(err, tc.target) {
+				t.Errorf("%s: expected %v, got %v", tc.expr, tc.target, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+
+		assert.Equal(t, value.RatString(), tc.expected)
+	}
+}
@@ -0,0 +1,379 @@
+package table
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc/symbolic"
+)
+
+var (
+	ErrUnknownColumn  = errors.New("unknown column")
+	ErrInvalidFormula = errors.New("invalid formula")
+)
+
+// Formula computes Column from the columns of a row, it replaces the
+// column when the input has one
+type Formula struct {
+	Column string
+	Expr   symbolic.Expr
+}
+
+// ParseFormula parses column = expression, the expression references the
+// columns by name like the variables of symbolic.Parse
+func ParseFormula(s string) (Formula, error) {
+	column, expression, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(column) == "" {
+		return Formula{}, fmt.Errorf("%w %q, expected column = expression", ErrInvalidFormula, s)
+	}
+
+	e, err := symbolic.Parse(expression)
+	if err != nil {
+		return Formula{}, fmt.Errorf("%w %q: %v", ErrInvalidFormula, s, err)
+	}
+
+	return Formula{Column: strings.TrimSpace(column), Expr: e}, nil
+}
+
+func (f Formula) String() string {
+	return fmt.Sprintf("%s = %s", f.Column, f.Expr)
+}
+
+// OnError is what happens to a row whose formulas fail
+type OnError string
+
+const (
+	// the row is left out
+	Skip OnError = "skip"
+	// the computation stops with the error, the default
+	Fail OnError = "fail"
+	// the failed cells are left empty
+	Blank OnError = "blank"
+)
+
+func ParseOnError(s string) (OnError, error) {
+	for _, onError := range []OnError{Skip, Fail, Blank} {
+		if string(onError) == s {
+			return onError, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown error handling %q, use skip, fail or blank", s)
+}
+
+// Aggregate is a footer row
+type Aggregate string
+
+const (
+	Sum Aggregate = "sum"
+	// Avg is exact, rounded to Options.Places
+	Avg Aggregate = "avg"
+)
+
+func ParseAggregate(s string) (Aggregate, error) {
+	for _, aggregate := range []Aggregate{Sum, Avg} {
+		if string(aggregate) == s {
+			return aggregate, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown footer %q, use sum or avg", s)
+}
+
+type Options struct {
+	// Formulas are evaluated in order, a formula may use the columns of
+	// the previous ones
+	Formulas []Formula
+	OnError  OnError
+	// Footers aggregate the computed columns and the columns they use
+	Footers []Aggregate
+	// Places and Rounding of the averages and of the results computed
+	// from decimal cells
+	Places   int
+	Rounding calc.Rounding
+	// Comma separates the fields, ',' when zero
+	Comma rune
+}
+
+// RowError is a formula failing on a row, Line is the line of the row in
+// the input
+type RowError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+type Stats struct {
+	// Rows written, the footers excluded
+	Rows    int
+	Skipped int
+	// Errors of the skipped and blanked rows
+	Errors []*RowError
+}
+
+// Compute reads CSV with a header from r and writes it to w with the
+// columns of the formulas, the arithmetic is the one of pkg/calc so
+// calc.Checked contexts fail on overflow. Formulas using decimal cells
+// such as 9.99 are computed exactly and rounded to Options.Places
+func Compute(ctx context.Context, r io.Reader, w io.Writer, opts Options) (Stats, error) {
+	var stats Stats
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	writer := csv.NewWriter(w)
+
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+		writer.Comma = opts.Comma
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return stats, errors.New("missing header")
+		}
+
+		return stats, err
+	}
+
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	columns := map[string]int{}
+
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+
+		if _, ok := columns[header[i]]; !ok {
+			columns[header[i]] = i
+		}
+	}
+
+	// targets are the output positions of the formulas, aggregated the
+	// positions of the footers
+	targets := make([]int, 0, len(opts.Formulas))
+	aggregated := map[int]bool{}
+
+	for _, f := range opts.Formulas {
+		for _, name := range symbolic.Variables(f.Expr) {
+			i, ok := columns[name]
+			if !ok {
+				return stats, fmt.Errorf("%w %q in %s", ErrUnknownColumn, name, f)
+			}
+
+			aggregated[i] = true
+		}
+
+		if _, ok := columns[f.Column]; !ok {
+			columns[f.Column] = len(header)
+			header = append(header, f.Column)
+		}
+
+		targets = append(targets, columns[f.Column])
+		aggregated[columns[f.Column]] = true
+	}
+
+	if err := writer.Write(header); err != nil {
+		return stats, err
+	}
+
+	// decimals are the sums of the decimal cells, nil for the columns
+	// holding integers only
+	sums := make([]int, len(header))
+	decimals := make([]*big.Rat, len(header))
+	counts := make([]int, len(header))
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return stats, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		row := make([]string, len(header))
+		copy(row, record)
+
+		failed, err := evaluate(ctx, row, columns, targets, line, opts, &stats)
+		if err != nil {
+			return stats, err
+		}
+
+		if failed && opts.OnError == Skip {
+			stats.Skipped++
+			continue
+		}
+
+		for i := range aggregated {
+			value, decimal, err := parseCell(row[i])
+			if err != nil {
+				continue
+			}
+
+			counts[i]++
+
+			if decimal != nil {
+				if decimals[i] == nil {
+					decimals[i] = new(big.Rat)
+				}
+
+				decimals[i].Add(decimals[i], decimal)
+
+				continue
+			}
+
+			if sums[i], err = calc.SumContext(ctx, sums[i], value); err != nil {
+				return stats, fmt.Errorf("%s of %s: %w", Sum, header[i], err)
+			}
+		}
+
+		if err := writer.Write(row); err != nil {
+			return stats, err
+		}
+
+		stats.Rows++
+	}
+
+	for _, aggregate := range opts.Footers {
+		footer := make([]string, len(header))
+
+		// the label goes in the first column unless it holds a result
+		if !aggregated[0] {
+			footer[0] = string(aggregate)
+		}
+
+		for i := range aggregated {
+			total := new(big.Rat).SetInt64(int64(sums[i]))
+			if decimals[i] != nil {
+				total.Add(total, decimals[i])
+			}
+
+			switch {
+			case aggregate == Sum && decimals[i] == nil:
+				footer[i] = strconv.Itoa(sums[i])
+			case aggregate == Sum:
+				footer[i] = opts.round(total)
+			case counts[i] > 0:
+				footer[i] = opts.round(total.Quo(total, big.NewRat(int64(counts[i]), 1)))
+			}
+		}
+
+		if err := writer.Write(footer); err != nil {
+			return stats, err
+		}
+	}
+
+	writer.Flush()
+
+	return stats, writer.Error()
+}
+
+// evaluate computes the formulas on row, it returns whether one failed
+// and the error ending the computation with Fail
+func evaluate(ctx context.Context, row []string, columns map[string]int, targets []int, line int, opts Options, stats *Stats) (bool, error) {
+	failed := false
+
+	for i, f := range opts.Formulas {
+		value, err := eval(ctx, f, row, columns, opts)
+
+		if err == nil {
+			row[targets[i]] = value
+			continue
+		}
+
+		rowErr := &RowError{Line: line, Column: f.Column, Err: err}
+
+		// an expired deadline ends the computation whatever OnError is
+		if opts.OnError == Fail || opts.OnError == "" || ctx.Err() != nil {
+			return true, rowErr
+		}
+
+		stats.Errors = append(stats.Errors, rowErr)
+		failed = true
+
+		if opts.OnError == Skip {
+			return true, nil
+		}
+
+		row[targets[i]] = ""
+	}
+
+	return failed, nil
+}
+
+// eval computes f with the integer arithmetic of pkg/calc, or exactly
+// when a cell it uses has decimals
+func eval(ctx context.Context, f Formula, row []string, columns map[string]int, opts Options) (string, error) {
+	vars := map[string]int{}
+	fractions := map[string]*big.Rat{}
+	exact := false
+
+	for _, name := range symbolic.Variables(f.Expr) {
+		cell := strings.TrimSpace(row[columns[name]])
+
+		value, decimal, err := parseCell(cell)
+		if err != nil {
+			return "", &calc.OperandError{Operand: cell, Err: fmt.Errorf("column %s is not a number", name)}
+		}
+
+		vars[name] = value
+		fractions[name] = decimal
+
+		if decimal == nil {
+			fractions[name] = new(big.Rat).SetInt64(int64(value))
+		} else {
+			exact = true
+		}
+	}
+
+	if !exact {
+		value, err := symbolic.EvalContext(ctx, f.Expr, vars)
+
+		return strconv.Itoa(value), err
+	}
+
+	value, err := symbolic.EvalRat(ctx, f.Expr, fractions)
+	if err != nil {
+		return "", err
+	}
+
+	return opts.round(value), nil
+}
+
+// parseCell parses an integer cell, or a decimal one such as 9.99 into
+// decimal
+func parseCell(cell string) (value int, decimal *big.Rat, err error) {
+	cell = strings.TrimSpace(cell)
+
+	if value, err = strconv.Atoi(cell); err == nil {
+		return value, nil, nil
+	}
+
+	decimal, err = calc.ParseDecimal(cell)
+
+	return 0, decimal, err
+}
+
+// round writes x with Places decimal places rounded with Rounding
+func (o Options) round(x *big.Rat) string {
+	return o.Rounding.Round(x, o.Places).FloatString(o.Places)
+}
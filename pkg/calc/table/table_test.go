@@ -0,0 +1,197 @@
+package table
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+const orders = `item,price,qty
+apple,3,4
+pear,5,x
+plum,7,2
+`
+
+func formulas(t *testing.T, exprs ...string) []Formula {
+	t.Helper()
+
+	parsed := make([]Formula, 0, len(exprs))
+
+	for _, expr := range exprs {
+		f, err := ParseFormula(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed = append(parsed, f)
+	}
+
+	return parsed
+}
+
+func TestParseFormula(t *testing.T) {
+	f, err := ParseFormula(" total = price * qty ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, f.Column, "total")
+	assert.Equal(t, f.String(), "total = price*qty")
+
+	for _, invalid := range []string{"price * qty", "= price", "total = price *"} {
+		if _, err := ParseFormula(invalid); !errors.Is(err, ErrInvalidFormula) {
+			t.Errorf("%q: expected an invalid formula, got %v", invalid, err)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	type testCase struct {
+		name     string
+		input    string
+		opts     Options
+		expected string
+		skipped  int
+		errors   int
+	}
+
+	cases := []testCase{
+		{
+			name:  "skip",
+			input: orders,
+			opts:  Options{Formulas: formulas(t, "total = price * qty"), OnError: Skip, Footers: []Aggregate{Sum, Avg}, Places: 2},
+			expected: `item,price,qty,total
+apple,3,4,12
+plum,7,2,14
+sum,10,6,26
+avg,5.00,3.00,13.00
+`,
+			skipped: 1,
+			errors:  1,
+		},
+		{
+			name:  "blank",
+			input: orders,
+			opts:  Options{Formulas: formulas(t, "total = price * qty", "tax = total / 5"), OnError: Blank, Footers: []Aggregate{Avg}},
+			expected: `item,price,qty,total,tax
+apple,3,4,12,2
+pear,5,x,,
+plum,7,2,14,2
+avg,5,3,13,2
+`,
+			errors: 2,
+		},
+		{
+			name:  "replace",
+			input: "\ufeffa;b\n1;2\n",
+			opts:  Options{Formulas: formulas(t, "b = a - b"), Comma: ';'},
+			expected: `a;b
+1;-1
+`,
+		},
+		{
+			name:  "decimal",
+			input: "item,price,qty\napple,9.99,3\npear,0.5,2\n",
+			opts:  Options{Formulas: formulas(t, "total = price * qty"), Footers: []Aggregate{Sum, Avg}, Places: 2},
+			expected: `item,price,qty,total
+apple,9.99,3,29.97
+pear,0.5,2,1.00
+sum,10.49,5,30.97
+avg,5.24,2.50,15.48
+`,
+		},
+		{
+			name:  "rounding",
+			input: "n\n1\n2\n",
+			opts:  Options{Formulas: formulas(t, "n = n"), Footers: []Aggregate{Avg}, Rounding: calc.Down},
+			expected: `n
+1
+2
+1
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		var out bytes.Buffer
+
+		stats, err := Compute(context.Background(), strings.NewReader(tc.input), &out, tc.opts)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+
+		assert.Equal(t, out.String(), tc.expected)
+		assert.Equal(t, stats.Skipped, tc.skipped)
+		assert.Equal(t, len(stats.Errors), tc.errors)
+	}
+}
+
+func TestComputeErrors(t *testing.T) {
+	type testCase struct {
+		input    string
+		opts     Options
+		target   error
+		expected string
+	}
+
+	cases := []testCase{
+		{
+			input:    orders,
+			opts:     Options{Formulas: formulas(t, "total = price * qty")},
+			target:   calc.ErrInvalidOperand,
+			expected: `line 3: total: invalid operand "x": column qty is not a number`,
+		},
+		{
+			input:    "a,b\n1,0\n",
+			opts:     Options{Formulas: formulas(t, "c = a / b"), OnError: Fail},
+			target:   calc.ErrDivisionByZero,
+			expected: "line 2: c: division by zero",
+		},
+		{
+			input:  orders,
+			opts:   Options{Formulas: formulas(t, "total = price * quantity")},
+			target: ErrUnknownColumn,
+		},
+		{
+			input:  "",
+			opts:   Options{},
+			target: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		var out bytes.Buffer
+
+		_, err := Compute(context.Background(), strings.NewReader(tc.input), &out, tc.opts)
+		if err == nil {
+			t.Errorf("%q: expected an error", tc.input)
+			continue
+		}
+
+		if tc.target != nil && !errors.Is(err, tc.target) {
+			t.Errorf("%q: expected %v, got %v", tc.input, tc.target, err)
+		}
+
+		if tc.expected != "" {
+			assert.Equal(t, err.Error(), tc.expected)
+		}
+	}
+
+	checked := calc.Checked(context.Background())
+
+	_, err := Compute(checked, strings.NewReader("a\n9223372036854775807\n"), &bytes.Buffer{}, Options{Formulas: formulas(t, "b = a + 1"), OnError: Skip})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Compute(checked, strings.NewReader("a\n9223372036854775807\n1\n"), &bytes.Buffer{}, Options{Formulas: formulas(t, "a = a"), Footers: []Aggregate{Sum}})
+	if !errors.Is(err, calc.ErrOverflow) {
+		t.Errorf("expected an overflow of the sum, got %v", err)
+	}
+}
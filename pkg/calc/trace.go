@@ -0,0 +1,51 @@
+package calc
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Step is an intermediate state of an operation, Depth is the nesting
+// level: the rounds of a Sum or the powers of a Pow
+type Step struct {
+	Op      string
+	Depth   int
+	Message string
+}
+
+// Tracer receives the steps of the operations, operations built on
+// other operations only report their own steps
+type Tracer func(step Step)
+
+// tracer is read by every operation, atomically so SetTracer is safe
+// while serve computes concurrently
+var tracer atomic.Pointer[Tracer]
+
+// SetTracer installs the tracer of every following operation, nil
+// turns tracing off
+func SetTracer(t Tracer) {
+	if t == nil {
+		tracer.Store(nil)
+
+		return
+	}
+
+	tracer.Store(&t)
+}
+
+// currentTracer is the installed tracer, nil when tracing is off
+func currentTracer() Tracer {
+	if t := tracer.Load(); t != nil {
+		return *t
+	}
+
+	return nil
+}
+
+func (t Tracer) trace(op string, depth int, format string, args ...interface{}) {
+	if t == nil {
+		return
+	}
+
+	t(Step{Op: op, Depth: depth, Message: fmt.Sprintf(format, args...)})
+}
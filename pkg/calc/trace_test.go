@@ -0,0 +1,100 @@
+package calc
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestTracer(t *testing.T) {
+	type testCase struct {
+		op    func()
+		steps []string
+	}
+
+	cases := []testCase{
+		{
+			op: func() { Sum(3, 5) },
+			steps: []string{
+				"sum 0 3 + 5",
+				"sum 1 round 1: partial 6 (110), carry 2 (10)",
+				"sum 1 round 2: partial 4 (100), carry 4 (100)",
+				"sum 1 round 3: partial 0 (0), carry 8 (1000)",
+				"sum 1 round 4: partial 8 (1000), carry 0 (0)",
+			},
+		},
+		{
+			op: func() { Mul(4, 2) },
+			steps: []string{
+				"mul 0 4 * 2",
+				"mul 1 partial product 1: 4",
+				"mul 1 partial product 2: 8",
+			},
+		},
+		{
+			op: func() { _, _ = Div(-7, 3) },
+			steps: []string{
+				"div 0 -7 / 3 = -(7 / 3)",
+				"div 0 7 / 3",
+				"div 1 step 1: 7 - 3 = 4",
+				"div 1 step 2: 4 - 3 = 1",
+				"div 1 quotient 2, remainder 1",
+			},
+		},
+		{
+			op: func() { Pow(2, 3) },
+			steps: []string{
+				"pow 0 2^3",
+				"pow 1 power 2: 4",
+				"pow 1 power 3: 8",
+			},
+		},
+	}
+
+	defer SetTracer(nil)
+
+	for _, tc := range cases {
+		steps := []string{}
+
+		SetTracer(func(step Step) {
+			steps = append(steps, fmt.Sprintf("%s %d %s", step.Op, step.Depth, step.Message))
+		})
+
+		tc.op()
+
+		assert.Equal(t, len(steps), len(tc.steps))
+
+		for i := range tc.steps {
+			if i < len(steps) {
+				assert.Equal(t, steps[i], tc.steps[i])
+			}
+		}
+	}
+}
+
+// TestSetTracerConcurrently is meant for go test -race, operations read
+// the tracer while it is replaced
+func TestSetTracerConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				Sum(j, 1)
+			}
+		}()
+	}
+
+	for j := 0; j < 100; j++ {
+		SetTracer(func(step Step) {})
+		SetTracer(nil)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,333 @@
+// Package units parses quantities such as 3.5GiB or 250ms and combines
+// them across units of the same dimension. Unit arithmetic is float64:
+// it does not go through pkg/calc, so --precision checked, --explain and
+// the observers do not apply to it
+package units
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/pkg/calc"
+)
+
+// Dimension is the vector of base dimension exponents of a quantity
+type Dimension struct {
+	Data   int
+	Time   int
+	Length int
+	Mass   int
+}
+
+var (
+	Dimensionless = Dimension{}
+	DataSize      = Dimension{Data: 1}
+	Time          = Dimension{Time: 1}
+	Length        = Dimension{Length: 1}
+	Mass          = Dimension{Mass: 1}
+	DataRate      = Dimension{Data: 1, Time: -1}
+	Frequency     = Dimension{Time: -1}
+	Speed         = Dimension{Length: 1, Time: -1}
+)
+
+var dimensionNames = map[Dimension]string{
+	Dimensionless: "dimensionless",
+	DataSize:      "data size",
+	Time:          "time",
+	Length:        "length",
+	Mass:          "mass",
+	DataRate:      "data rate",
+	Frequency:     "rate",
+	Speed:         "speed",
+}
+
+func (d Dimension) String() string {
+	if name, ok := dimensionNames[d]; ok {
+		return name
+	}
+
+	return d.symbol()
+}
+
+func (d Dimension) add(other Dimension) Dimension {
+	return Dimension{
+		Data:   d.Data + other.Data,
+		Time:   d.Time + other.Time,
+		Length: d.Length + other.Length,
+		Mass:   d.Mass + other.Mass,
+	}
+}
+
+func (d Dimension) neg() Dimension {
+	return Dimension{Data: -d.Data, Time: -d.Time, Length: -d.Length, Mass: -d.Mass}
+}
+
+// symbol spells the dimension with base units, e.g. B/s or m^2
+func (d Dimension) symbol() string {
+	bases := []struct {
+		symbol   string
+		exponent int
+	}{
+		{"B", d.Data},
+		{"m", d.Length},
+		{"g", d.Mass},
+		{"s", d.Time},
+	}
+
+	var num, den []string
+
+	for _, base := range bases {
+		switch {
+		case base.exponent == 1:
+			num = append(num, base.symbol)
+		case base.exponent > 1:
+			num = append(num, fmt.Sprintf("%s^%d", base.symbol, base.exponent))
+		case base.exponent == -1:
+			den = append(den, base.symbol)
+		case base.exponent < -1:
+			den = append(den, fmt.Sprintf("%s^%d", base.symbol, -base.exponent))
+		}
+	}
+
+	symbol := strings.Join(num, "*")
+
+	if len(den) > 0 {
+		if symbol == "" {
+			symbol = "1"
+		}
+
+		symbol += "/" + strings.Join(den, "*")
+	}
+
+	return symbol
+}
+
+// Unit is a named scale of a dimension, Factor converts it to base units
+type Unit struct {
+	Symbol    string
+	Factor    float64
+	Dimension Dimension
+}
+
+var baseUnits = map[string]Unit{
+	"B":   {Symbol: "B", Factor: 1, Dimension: DataSize},
+	"bit": {Symbol: "bit", Factor: 0.125, Dimension: DataSize},
+	"s":   {Symbol: "s", Factor: 1, Dimension: Time},
+	"min": {Symbol: "min", Factor: 60, Dimension: Time},
+	"h":   {Symbol: "h", Factor: 3600, Dimension: Time},
+	"d":   {Symbol: "d", Factor: 86400, Dimension: Time},
+	"m":   {Symbol: "m", Factor: 1, Dimension: Length},
+	"g":   {Symbol: "g", Factor: 1, Dimension: Mass},
+}
+
+// units accepting SI prefixes
+var siUnits = []string{"B", "bit", "s", "m", "g"}
+
+// units accepting IEC (binary) prefixes
+var iecUnits = []string{"B", "bit"}
+
+var siPrefixes = map[string]float64{
+	"E": 1e18,
+	"P": 1e15,
+	"T": 1e12,
+	"G": 1e9,
+	"M": 1e6,
+	"k": 1e3,
+	"m": 1e-3,
+	"u": 1e-6,
+	"µ": 1e-6,
+	"n": 1e-9,
+	"p": 1e-12,
+}
+
+var iecPrefixes = map[string]float64{
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+	"Ei": 1 << 60,
+}
+
+// ParseUnit parses a unit symbol such as GiB, ms or MB/s
+func ParseUnit(symbol string) (Unit, error) {
+	symbol = strings.TrimSpace(symbol)
+
+	if symbol == "" {
+		return Unit{Factor: 1}, nil
+	}
+
+	if num, den, ok := strings.Cut(symbol, "/"); ok {
+		numerator, err := ParseUnit(num)
+		if err != nil {
+			return Unit{}, err
+		}
+
+		denominator, err := parseSimpleUnit(den)
+		if err != nil {
+			return Unit{}, err
+		}
+
+		return Unit{
+			Symbol:    symbol,
+			Factor:    numerator.Factor / denominator.Factor,
+			Dimension: numerator.Dimension.add(denominator.Dimension.neg()),
+		}, nil
+	}
+
+	return parseSimpleUnit(symbol)
+}
+
+func parseSimpleUnit(symbol string) (Unit, error) {
+	if unit, ok := baseUnits[symbol]; ok {
+		return unit, nil
+	}
+
+	if unit, ok := prefixed(symbol, iecUnits, iecPrefixes); ok {
+		return unit, nil
+	}
+
+	if unit, ok := prefixed(symbol, siUnits, siPrefixes); ok {
+		return unit, nil
+	}
+
+	return Unit{}, fmt.Errorf("unknown unit %q", symbol)
+}
+
+func prefixed(symbol string, bases []string, prefixes map[string]float64) (Unit, bool) {
+	for _, base := range bases {
+		prefix := strings.TrimSuffix(symbol, base)
+
+		if prefix == symbol {
+			continue
+		}
+
+		if factor, ok := prefixes[prefix]; ok {
+			unit := baseUnits[base]
+
+			return Unit{Symbol: symbol, Factor: factor * unit.Factor, Dimension: unit.Dimension}, true
+		}
+	}
+
+	return Unit{}, false
+}
+
+// Quantity is a value expressed in a unit
+type Quantity struct {
+	Value float64
+	Unit  Unit
+}
+
+var quantityRegexp = regexp.MustCompile(`^([+-]?(?:\d+\.?\d*|\.\d+)(?:[eE][+-]?\d+)?)\s*(.*)$`)
+
+// Parse parses a quantity such as 3.5GiB, 250ms or 40
+func Parse(s string) (Quantity, error) {
+	match := quantityRegexp.FindStringSubmatch(strings.TrimSpace(s))
+
+	if match == nil {
+		return Quantity{}, fmt.Errorf("invalid quantity %q", s)
+	}
+
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return Quantity{}, err
+	}
+
+	unit, err := ParseUnit(match[2])
+	if err != nil {
+		return Quantity{}, err
+	}
+
+	return Quantity{Value: value, Unit: unit}, nil
+}
+
+func (q Quantity) Dimension() Dimension {
+	return q.Unit.Dimension
+}
+
+// Base returns the value expressed in base units
+func (q Quantity) Base() float64 {
+	return q.Value * q.Unit.Factor
+}
+
+func (q Quantity) String() string {
+	// drop the noise of binary floating point, 12 digits are plenty
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(q.Value, 'g', 12, 64), 64)
+
+	return strconv.FormatFloat(rounded, 'f', -1, 64) + q.Unit.Symbol
+}
+
+// DimensionError reports an operation between incompatible dimensions
+type DimensionError struct {
+	Op    string
+	Left  Dimension
+	Right Dimension
+}
+
+func (e *DimensionError) Error() string {
+	return fmt.Sprintf("cannot %s %s and %s: incompatible dimensions", e.Op, e.Left, e.Right)
+}
+
+var ErrDivisionByZero = calc.ErrDivisionByZero
+
+func Add(first, second Quantity) (Quantity, error) {
+	if first.Dimension() != second.Dimension() {
+		return Quantity{}, &DimensionError{Op: "add", Left: first.Dimension(), Right: second.Dimension()}
+	}
+
+	return Quantity{Value: first.Value + second.Base()/first.Unit.Factor, Unit: first.Unit}, nil
+}
+
+func Sub(first, second Quantity) (Quantity, error) {
+	if first.Dimension() != second.Dimension() {
+		return Quantity{}, &DimensionError{Op: "subtract", Left: first.Dimension(), Right: second.Dimension()}
+	}
+
+	return Quantity{Value: first.Value - second.Base()/first.Unit.Factor, Unit: first.Unit}, nil
+}
+
+func Mul(first, second Quantity) (Quantity, error) {
+	switch {
+	case second.Dimension() == Dimensionless:
+		return Quantity{Value: first.Value * second.Base(), Unit: first.Unit}, nil
+	case first.Dimension() == Dimensionless:
+		return Quantity{Value: first.Base() * second.Value, Unit: second.Unit}, nil
+	}
+
+	return inBaseUnits(first.Base()*second.Base(), first.Dimension().add(second.Dimension())), nil
+}
+
+func Div(first, second Quantity) (Quantity, error) {
+	if second.Value == 0 {
+		return Quantity{}, ErrDivisionByZero
+	}
+
+	switch {
+	case second.Dimension() == Dimensionless:
+		return Quantity{Value: first.Value / second.Base(), Unit: first.Unit}, nil
+	case first.Dimension() == second.Dimension():
+		return Quantity{Value: first.Base() / second.Base(), Unit: Unit{Factor: 1}}, nil
+	}
+
+	return inBaseUnits(first.Base()/second.Base(), first.Dimension().add(second.Dimension().neg())), nil
+}
+
+// Convert expresses the quantity in the given unit
+func Convert(q Quantity, symbol string) (Quantity, error) {
+	unit, err := ParseUnit(symbol)
+	if err != nil {
+		return Quantity{}, err
+	}
+
+	if q.Dimension() != unit.Dimension {
+		return Quantity{}, &DimensionError{Op: "convert", Left: q.Dimension(), Right: unit.Dimension}
+	}
+
+	return Quantity{Value: q.Base() / unit.Factor, Unit: unit}, nil
+}
+
+func inBaseUnits(value float64, dimension Dimension) Quantity {
+	return Quantity{Value: value, Unit: Unit{Symbol: dimension.symbol(), Factor: 1, Dimension: dimension}}
+}
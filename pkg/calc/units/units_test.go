@@ -0,0 +1,246 @@
+package units
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alvise88/zero-turnaround-cicd-with-dagger/internal/assert"
+)
+
+func TestParse(t *testing.T) {
+	type testCase struct {
+		input     string
+		base      float64
+		dimension Dimension
+		ok        bool
+	}
+
+	cases := []testCase{
+		{
+			input:     "40",
+			base:      40,
+			dimension: Dimensionless,
+			ok:        true,
+		},
+		{
+			input:     "1GiB",
+			base:      1 << 30,
+			dimension: DataSize,
+			ok:        true,
+		},
+		{
+			input:     "1.5 kB",
+			base:      1500,
+			dimension: DataSize,
+			ok:        true,
+		},
+		{
+			input:     "250ms",
+			base:      0.25,
+			dimension: Time,
+			ok:        true,
+		},
+		{
+			input:     "2min",
+			base:      120,
+			dimension: Time,
+			ok:        true,
+		},
+		{
+			input:     "3km",
+			base:      3000,
+			dimension: Length,
+			ok:        true,
+		},
+		{
+			input:     "2kg",
+			base:      2000,
+			dimension: Mass,
+			ok:        true,
+		},
+		{
+			input:     "8Mbit/s",
+			base:      1e6,
+			dimension: DataRate,
+			ok:        true,
+		},
+		{
+			input: "3parsec",
+			ok:    false,
+		},
+		{
+			input: "GiB",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		q, err := Parse(tc.input)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected parse error for %q", tc.input)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, q.Base(), tc.base)
+			assert.Equal(t, q.Dimension(), tc.dimension)
+		}
+	}
+}
+
+func TestOperations(t *testing.T) {
+	type testCase struct {
+		op     func(first, second Quantity) (Quantity, error)
+		first  string
+		second string
+		result string
+		ok     bool
+	}
+
+	cases := []testCase{
+		{
+			op:     Add,
+			first:  "1GiB",
+			second: "512MiB",
+			result: "1.5GiB",
+			ok:     true,
+		},
+		{
+			op:     Sub,
+			first:  "1h",
+			second: "30min",
+			result: "0.5h",
+			ok:     true,
+		},
+		{
+			op:     Mul,
+			first:  "250ms",
+			second: "40",
+			result: "10000ms",
+			ok:     true,
+		},
+		{
+			op:     Div,
+			first:  "1GiB",
+			second: "512MiB",
+			result: "2",
+			ok:     true,
+		},
+		{
+			op:     Div,
+			first:  "10MB",
+			second: "2s",
+			result: "5000000B/s",
+			ok:     true,
+		},
+		{
+			op:     Mul,
+			first:  "3m",
+			second: "2m",
+			result: "6m^2",
+			ok:     true,
+		},
+		{
+			op:     Add,
+			first:  "1GiB",
+			second: "1s",
+			ok:     false,
+		},
+		{
+			op:     Div,
+			first:  "1GiB",
+			second: "0s",
+			ok:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		first, _ := Parse(tc.first)
+		second, _ := Parse(tc.second)
+
+		result, err := tc.op(first, second)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected error for %s and %s", tc.first, tc.second)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, result.String(), tc.result)
+		}
+	}
+}
+
+func TestDimensionError(t *testing.T) {
+	first, _ := Parse("1GiB")
+	second, _ := Parse("1s")
+
+	_, err := Add(first, second)
+
+	var dimErr *DimensionError
+	if !errors.As(err, &dimErr) {
+		t.Fatalf("expected a dimension error, got %v", err)
+	}
+
+	assert.Equal(t, err.Error(), "cannot add data size and time: incompatible dimensions")
+}
+
+func TestConvert(t *testing.T) {
+	type testCase struct {
+		input  string
+		unit   string
+		result string
+		ok     bool
+	}
+
+	cases := []testCase{
+		{
+			input:  "3.5GiB",
+			unit:   "MB",
+			result: "3758.096384MB",
+			ok:     true,
+		},
+		{
+			input:  "90min",
+			unit:   "h",
+			result: "1.5h",
+			ok:     true,
+		},
+		{
+			input:  "1Gbit/s",
+			unit:   "MB/s",
+			result: "125MB/s",
+			ok:     true,
+		},
+		{
+			input: "1kg",
+			unit:  "s",
+			ok:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		q, _ := Parse(tc.input)
+
+		converted, err := Convert(q, tc.unit)
+
+		if !tc.ok && err == nil {
+			t.Errorf("expected conversion error for %s to %s", tc.input, tc.unit)
+		}
+
+		if tc.ok && err != nil {
+			t.Error(err)
+		}
+
+		if tc.ok && err == nil {
+			assert.Equal(t, converted.String(), tc.result)
+		}
+	}
+}